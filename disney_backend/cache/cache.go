@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrMiss is returned by Get for keys that are missing or expired
+	ErrMiss = errors.New("cache miss")
+	// ErrUnavailable is returned while a remote backend cannot be reached
+	ErrUnavailable = errors.New("cache unavailable")
+)
+
+// Cache stores byte values under string keys with a time-to-live
+type Cache interface {
+	// Get returns the value of key, or ErrMiss
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value under key for ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes keys; missing keys are ignored
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// GetJSON reads key and decodes it into v
+func GetJSON(ctx context.Context, c Cache, key string, v interface{}) error {
+	data, err := c.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// SetJSON encodes v and stores it under key for ttl
+func SetJSON(ctx context.Context, c Cache, key string, v interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, key, data, ttl)
+}
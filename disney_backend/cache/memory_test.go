@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// get returns the value of key, or "" on a miss
+func get(t *testing.T, c Cache, key string) string {
+	t.Helper()
+	value, err := c.Get(context.Background(), key)
+	if errors.Is(err, ErrMiss) {
+		return ""
+	}
+	if err != nil {
+		t.Fatalf("Get(%s): %v", key, err)
+	}
+	return string(value)
+}
+
+func TestMemoryGetSetDelete(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(10)
+
+	value := []byte("one")
+	m.Set(ctx, "a", value, time.Minute)
+	value[0] = 'x'
+	if got := get(t, m, "a"); got != "one" {
+		t.Errorf("Get(a) = %q, want the value as stored", got)
+	}
+	m.Set(ctx, "a", []byte("two"), time.Minute)
+	if got := get(t, m, "a"); got != "two" || m.Len() != 1 {
+		t.Errorf("Get(a) = %q with %d entries after overwrite", got, m.Len())
+	}
+
+	m.Delete(ctx, "a", "missing")
+	if _, err := m.Get(ctx, "a"); !errors.Is(err, ErrMiss) {
+		t.Errorf("Get after Delete: %v, want ErrMiss", err)
+	}
+}
+
+func TestMemoryExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(10)
+	m.Set(ctx, "short", []byte("1"), 10*time.Millisecond)
+	m.Set(ctx, "long", []byte("2"), time.Minute)
+
+	time.Sleep(20 * time.Millisecond)
+	if got := get(t, m, "short"); got != "" {
+		t.Errorf("expired entry returned %q", got)
+	}
+	if got := get(t, m, "long"); got != "2" || m.Len() != 1 {
+		t.Errorf("Get(long) = %q with %d entries, want the expired entry evicted", got, m.Len())
+	}
+}
+
+func TestMemoryEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(2)
+	m.Set(ctx, "a", []byte("1"), time.Minute)
+	m.Set(ctx, "b", []byte("2"), time.Minute)
+	// Reading a makes b the least recently used
+	get(t, m, "a")
+	m.Set(ctx, "c", []byte("3"), time.Minute)
+
+	if get(t, m, "b") != "" || get(t, m, "a") != "1" || get(t, m, "c") != "3" {
+		t.Error("eviction did not remove the least recently used entry")
+	}
+	if m.Len() != 2 {
+		t.Errorf("%d entries, want 2", m.Len())
+	}
+}
+
+func TestMemoryDeleteFunc(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(10)
+	for _, key := range []string{"user:1", "user:2", "cartoon:1"} {
+		m.Set(ctx, key, []byte(key), time.Minute)
+	}
+
+	removed := m.DeleteFunc(func(key string, _ []byte) bool { return strings.HasPrefix(key, "user:") })
+	if removed != 2 || m.Len() != 1 || get(t, m, "cartoon:1") == "" {
+		t.Errorf("removed %d, %d left", removed, m.Len())
+	}
+}
+
+func TestJSONHelpers(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(10)
+	if err := SetJSON(ctx, m, "ids", []int{3, 1, 2}, time.Minute); err != nil {
+		t.Fatalf("SetJSON: %v", err)
+	}
+	var ids []int
+	if err := GetJSON(ctx, m, "ids", &ids); err != nil || len(ids) != 3 || ids[0] != 3 {
+		t.Errorf("GetJSON = %v, %v", ids, err)
+	}
+	if err := GetJSON(ctx, m, "missing", &ids); !errors.Is(err, ErrMiss) {
+		t.Errorf("GetJSON(missing): %v, want ErrMiss", err)
+	}
+}
@@ -0,0 +1,226 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis caches values in Redis and notices when Redis goes away
+// After a connection error every call fails fast with ErrUnavailable, and a
+// background loop pings Redis every retryInterval until it answers again
+type Redis struct {
+	options       *redis.Options
+	retryInterval time.Duration
+
+	mu           sync.RWMutex
+	client       *redis.Client
+	ownsClient   bool
+	available    bool
+	reconnecting bool
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewRedis creates a Redis cache
+// client is the shared connection (nil if Redis was down at startup); options,
+// if set, let the cache open its own connection once Redis comes up
+func NewRedis(client *redis.Client, options *redis.Options, retryInterval time.Duration) *Redis {
+	if retryInterval <= 0 {
+		retryInterval = 5 * time.Second
+	}
+	r := &Redis{
+		options:       options,
+		retryInterval: retryInterval,
+		client:        client,
+		available:     client != nil,
+		done:          make(chan struct{}),
+	}
+	if client == nil {
+		r.markUnavailable(nil)
+	}
+	return r
+}
+
+// Available reports whether Redis answered the last call
+func (r *Redis) Available() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.available
+}
+
+// conn returns the client if Redis is available
+func (r *Redis) conn() (*redis.Client, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !r.available {
+		return nil, ErrUnavailable
+	}
+	return r.client, nil
+}
+
+// check converts a Redis error, marking Redis unavailable on connection errors
+// Errors Redis itself returned (e.g. WRONGTYPE) and the caller's own
+// cancellation leave it available
+func (r *Redis) check(ctx context.Context, err error) error {
+	var redisErr redis.Error
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, redis.Nil):
+		return ErrMiss
+	case errors.As(err, &redisErr), ctx.Err() != nil:
+		return err
+	}
+	r.markUnavailable(err)
+	return fmt.Errorf("%w: %v", ErrUnavailable, err)
+}
+
+// markUnavailable starts the reconnect loop unless it is already running
+func (r *Redis) markUnavailable(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.available = false
+	if r.reconnecting {
+		return
+	}
+	if r.client == nil && r.options == nil {
+		// Redis is not configured, there is nothing to reconnect to
+		return
+	}
+	r.reconnecting = true
+	if err != nil {
+		log.Printf("WARNING: Redis cache unavailable, retrying every %s: %v", r.retryInterval, err)
+	}
+	go r.reconnect()
+}
+
+// reconnect pings Redis until it answers, opening a connection if there is none
+func (r *Redis) reconnect() {
+	ticker := time.NewTicker(r.retryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		}
+
+		r.mu.Lock()
+		if r.client == nil {
+			r.client = redis.NewClient(r.options)
+			r.ownsClient = true
+		}
+		client := r.client
+		r.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		err := client.Ping(ctx).Err()
+		cancel()
+		if err != nil {
+			continue
+		}
+
+		r.mu.Lock()
+		r.available = true
+		r.reconnecting = false
+		r.mu.Unlock()
+		log.Println("Redis cache reconnected")
+		return
+	}
+}
+
+// Get returns the value of key
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	client, err := r.conn()
+	if err != nil {
+		return nil, err
+	}
+	value, err := client.Get(ctx, key).Bytes()
+	if err := r.check(ctx, err); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Set stores value under key for ttl
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	client, err := r.conn()
+	if err != nil {
+		return err
+	}
+	return r.check(ctx, client.Set(ctx, key, value, ttl).Err())
+}
+
+// Delete removes keys
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	client, err := r.conn()
+	if err != nil {
+		return err
+	}
+	return r.check(ctx, client.Del(ctx, keys...).Err())
+}
+
+// Do runs fn with the connection for commands the Cache interface lacks
+// Like Get and Set it fails fast with ErrUnavailable while Redis is down, and
+// connection errors returned by fn mark Redis unavailable
+func (r *Redis) Do(ctx context.Context, fn func(client *redis.Client) error) error {
+	client, err := r.conn()
+	if err != nil {
+		return err
+	}
+	return r.check(ctx, fn(client))
+}
+
+// Subscribe passes the payload of every message published on channel to
+// handle until Close, subscribing as soon as Redis is available
+// go-redis resubscribes by itself after a reconnect
+func (r *Redis) Subscribe(channel string, handle func(payload string)) {
+	go func() {
+		ticker := time.NewTicker(r.retryInterval)
+		defer ticker.Stop()
+
+		for {
+			if client, err := r.conn(); err == nil {
+				pubsub := client.Subscribe(context.Background(), channel)
+				go func() {
+					<-r.done
+					pubsub.Close()
+				}()
+				for msg := range pubsub.Channel() {
+					handle(msg.Payload)
+				}
+				return
+			}
+
+			select {
+			case <-r.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close stops reconnecting and subscriptions, and closes the connection the cache opened itself
+// The shared client passed to NewRedis is left to its owner
+func (r *Redis) Close() error {
+	r.closeOnce.Do(func() { close(r.done) })
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ownsClient && r.client != nil {
+		return r.client.Close()
+	}
+	return nil
+}
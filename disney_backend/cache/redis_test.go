@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedis returns a Redis cache on a miniredis server, retried every 10ms
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *Redis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	// No retries or long dial timeouts while the server is stopped
+	options := &redis.Options{Addr: server.Addr(), MaxRetries: -1, DialTimeout: 50 * time.Millisecond}
+	client := redis.NewClient(options)
+	r := NewRedis(client, options, 10*time.Millisecond)
+	t.Cleanup(func() {
+		r.Close()
+		client.Close()
+	})
+	return server, r
+}
+
+// waitAvailable waits until the cache reconnects
+func waitAvailable(t *testing.T, r *Redis) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !r.Available() {
+		if time.Now().After(deadline) {
+			t.Fatal("Redis cache did not reconnect")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRedisGetSetDelete(t *testing.T) {
+	server, r := newTestRedis(t)
+	ctx := context.Background()
+
+	if _, err := r.Get(ctx, "a"); !errors.Is(err, ErrMiss) {
+		t.Errorf("Get(missing): %v, want ErrMiss", err)
+	}
+	if err := r.Set(ctx, "a", []byte("1"), time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got := get(t, r, "a"); got != "1" || server.TTL("a") != time.Minute {
+		t.Errorf("Get(a) = %q with TTL %s", got, server.TTL("a"))
+	}
+	r.Delete(ctx, "a")
+	if server.Exists("a") {
+		t.Error("Delete left the key")
+	}
+
+	// Errors from Redis itself don't mean it is down
+	server.Lpush("list", "x")
+	if _, err := r.Get(ctx, "list"); err == nil || errors.Is(err, ErrUnavailable) || !r.Available() {
+		t.Errorf("Get of a list: %v, available %v", err, r.Available())
+	}
+}
+
+func TestRedisFailsFastAndReconnects(t *testing.T) {
+	server, r := newTestRedis(t)
+	ctx := context.Background()
+
+	server.Close()
+	if err := r.Set(ctx, "a", []byte("1"), time.Minute); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Set while down: %v, want ErrUnavailable", err)
+	}
+	if r.Available() {
+		t.Error("cache still available after a connection error")
+	}
+	start := time.Now()
+	if _, err := r.Get(ctx, "a"); !errors.Is(err, ErrUnavailable) || time.Since(start) > 10*time.Millisecond {
+		t.Errorf("Get while down: %v after %s, want ErrUnavailable right away", err, time.Since(start))
+	}
+
+	if err := server.Restart(); err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	waitAvailable(t, r)
+	if err := r.Set(ctx, "a", []byte("1"), time.Minute); err != nil {
+		t.Errorf("Set after reconnect: %v", err)
+	}
+}
+
+func TestRedisConnectsWhenStartedWithoutRedis(t *testing.T) {
+	server := miniredis.RunT(t)
+	r := NewRedis(nil, &redis.Options{Addr: server.Addr()}, 10*time.Millisecond)
+	t.Cleanup(func() { r.Close() })
+
+	waitAvailable(t, r)
+	if err := r.Set(context.Background(), "a", []byte("1"), time.Minute); err != nil || !server.Exists("a") {
+		t.Errorf("Set on the cache's own connection: %v", err)
+	}
+
+	unconfigured := NewRedis(nil, nil, 10*time.Millisecond)
+	if _, err := unconfigured.Get(context.Background(), "a"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Get without Redis: %v, want ErrUnavailable", err)
+	}
+}
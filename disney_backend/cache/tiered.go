@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// Tiered puts a local memory cache (L1) in front of a shared cache such as Redis (L2)
+// Reads are served from L1 when possible and L2 hits are copied into L1 for up
+// to l1TTL, so other replicas' writes show up within l1TTL
+// While L2 is unavailable, L1 alone keeps values for their full TTL, so
+// callers see misses only for what this replica never stored
+type Tiered struct {
+	l1    *Memory
+	l2    Cache
+	l1TTL time.Duration
+}
+
+// NewTiered creates a tiered cache keeping L1 copies for at most l1TTL
+func NewTiered(l1 *Memory, l2 Cache, l1TTL time.Duration) *Tiered {
+	return &Tiered{l1: l1, l2: l2, l1TTL: l1TTL}
+}
+
+// Get returns the L1 value, else the L2 value; an unavailable L2 counts as a miss
+func (t *Tiered) Get(ctx context.Context, key string) ([]byte, error) {
+	if value, err := t.l1.Get(ctx, key); err == nil {
+		return value, nil
+	}
+
+	value, err := t.l2.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrMiss) || errors.Is(err, ErrUnavailable) {
+			return nil, ErrMiss
+		}
+		return nil, err
+	}
+	t.l1.Set(ctx, key, value, t.l1TTL)
+	return value, nil
+}
+
+// Set writes to both tiers; if L2 cannot be reached the value stays in L1 for the full ttl
+func (t *Tiered) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	l1TTL := min(ttl, t.l1TTL)
+	err := t.l2.Set(ctx, key, value, ttl)
+	if err != nil {
+		if !errors.Is(err, ErrUnavailable) {
+			return err
+		}
+		l1TTL = ttl
+	}
+	return t.l1.Set(ctx, key, value, l1TTL)
+}
+
+// Delete removes keys from both tiers
+// Other replicas' L1 copies live on until they expire
+func (t *Tiered) Delete(ctx context.Context, keys ...string) error {
+	t.l1.Delete(ctx, keys...)
+	if err := t.l2.Delete(ctx, keys...); err != nil && !errors.Is(err, ErrUnavailable) {
+		return err
+	}
+	return nil
+}
+
+// Close closes L2 if it needs closing
+func (t *Tiered) Close() error {
+	if closer, ok := t.l2.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
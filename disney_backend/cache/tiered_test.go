@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// flakyCache is a memory cache that fails every call with err while err is set
+type flakyCache struct {
+	*Memory
+	err error
+}
+
+func (c *flakyCache) Get(ctx context.Context, key string) ([]byte, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.Memory.Get(ctx, key)
+}
+
+func (c *flakyCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.Memory.Set(ctx, key, value, ttl)
+}
+
+func (c *flakyCache) Delete(ctx context.Context, keys ...string) error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.Memory.Delete(ctx, keys...)
+}
+
+func TestTieredCopiesL2HitsIntoL1(t *testing.T) {
+	ctx := context.Background()
+	l1, l2 := NewMemory(10), &flakyCache{Memory: NewMemory(10)}
+	tiered := NewTiered(l1, l2, 20*time.Millisecond)
+
+	// Written by another replica
+	l2.Set(ctx, "a", []byte("1"), time.Minute)
+	if got := get(t, tiered, "a"); got != "1" {
+		t.Fatalf("Get(a) = %q", got)
+	}
+	if got := get(t, l1, "a"); got != "1" {
+		t.Error("L2 hit not copied into L1")
+	}
+
+	// L1 copies expire after l1TTL, so other replicas' updates show up
+	l2.Set(ctx, "a", []byte("2"), time.Minute)
+	time.Sleep(30 * time.Millisecond)
+	if got := get(t, tiered, "a"); got != "2" {
+		t.Errorf("Get(a) = %q after the L1 copy expired, want the updated value", got)
+	}
+}
+
+func TestTieredSurvivesUnavailableL2(t *testing.T) {
+	ctx := context.Background()
+	l1, l2 := NewMemory(10), &flakyCache{Memory: NewMemory(10)}
+	tiered := NewTiered(l1, l2, 10*time.Millisecond)
+
+	tiered.Set(ctx, "written-before", []byte("1"), time.Minute)
+	l2.err = ErrUnavailable
+	if err := tiered.Set(ctx, "written-during", []byte("2"), time.Minute); err != nil {
+		t.Fatalf("Set while L2 is down: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	// The value written while L2 was down keeps its full TTL in L1
+	if got := get(t, tiered, "written-during"); got != "2" {
+		t.Errorf("Get(written-during) = %q, want it kept in L1", got)
+	}
+	// The expired L1 copy of the other value is a miss, not an error
+	if _, err := tiered.Get(ctx, "written-before"); !errors.Is(err, ErrMiss) {
+		t.Errorf("Get(written-before): %v, want ErrMiss", err)
+	}
+	if err := tiered.Delete(ctx, "written-during"); err != nil || get(t, l1, "written-during") != "" {
+		t.Errorf("Delete while L2 is down: %v", err)
+	}
+}
+
+func TestTieredReturnsOtherL2Errors(t *testing.T) {
+	ctx := context.Background()
+	failure := errors.New("WRONGTYPE")
+	tiered := NewTiered(NewMemory(10), &flakyCache{Memory: NewMemory(10), err: failure}, time.Minute)
+
+	if _, err := tiered.Get(ctx, "a"); !errors.Is(err, failure) {
+		t.Errorf("Get: %v, want the L2 error", err)
+	}
+	if err := tiered.Set(ctx, "a", []byte("1"), time.Minute); !errors.Is(err, failure) {
+		t.Errorf("Set: %v, want the L2 error", err)
+	}
+	if err := tiered.Delete(ctx, "a"); !errors.Is(err, failure) {
+		t.Errorf("Delete: %v, want the L2 error", err)
+	}
+}
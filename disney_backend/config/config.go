@@ -0,0 +1,328 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Config is the server's configuration
+// Values are read, in increasing order of precedence, from defaults, an optional
+// JSON file (-config or CONFIG_FILE), the environment (and .env in local
+// development) and command-line flags; see Load
+// main.go passes each section to the packages that use it
+type Config struct {
+	Server          ServerConfig          `json:"server"`
+	Database        DatabaseConfig        `json:"database"`
+	Redis           RedisConfig           `json:"redis"`
+	Auth            AuthConfig            `json:"auth"`
+	Queue           QueueConfig           `json:"queue"`
+	Workers         WorkersConfig         `json:"workers"`
+	Cache           CacheConfig           `json:"cache"`
+	Metadata        MetadataConfig        `json:"metadata"`
+	Ratings         RatingsConfig         `json:"ratings"`
+	RecentlyViewed  RecentlyViewedConfig  `json:"recently_viewed"`
+	Trending        TrendingConfig        `json:"trending"`
+	Recommendations RecommendationsConfig `json:"recommendations"`
+	Retention       RetentionConfig       `json:"retention"`
+}
+
+// ServerConfig configures the HTTP server
+type ServerConfig struct {
+	Port string `json:"port"`
+	// ShutdownTimeout bounds each shutdown phase (draining HTTP requests, then worker queues)
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+}
+
+// DatabaseConfig configures the Postgres connection and its pool
+type DatabaseConfig struct {
+	Host     string `json:"host"`
+	Port     string `json:"port"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+	Name     string `json:"name"`
+	SSLMode  string `json:"sslmode"`
+	// Keep MaxOpenConns low on hosted Postgres (Supabase limits connections)
+	MaxOpenConns    int      `json:"max_open_conns"`
+	MaxIdleConns    int      `json:"max_idle_conns"`
+	ConnMaxLifetime Duration `json:"conn_max_lifetime"`
+}
+
+// RedisConfig configures Redis, either as a URL or as host and port
+// Redis is optional: with neither set, caches and queues stay in memory
+type RedisConfig struct {
+	URL      string `json:"url"`
+	Host     string `json:"host"`
+	Port     string `json:"port"`
+	Password string `json:"password"`
+	DB       int    `json:"db"`
+}
+
+// AuthConfig holds the authentication secrets
+type AuthConfig struct {
+	JWTSecret string `json:"jwt_secret"`
+	// AdminSecret lets CreateAdmin create admins; admin signup is disabled without it
+	AdminSecret string `json:"admin_secret"`
+}
+
+// QueueConfig configures the worker pool queues and job tracking
+type QueueConfig struct {
+	// Backend is "redis" (jobs survive restarts) or "memory"
+	Backend string `json:"backend"`
+	// VisibilityTimeout is how long a job may stay unacknowledged before it is redelivered
+	VisibilityTimeout Duration `json:"visibility_timeout"`
+	// Consumer names this instance in the Redis consumer groups (default: the hostname)
+	Consumer string `json:"consumer"`
+	// RetryAfter is sent to clients whose job was refused by a saturated pool
+	RetryAfter Duration `json:"retry_after"`
+	// JobStatusTTL is how long async job statuses are kept
+	JobStatusTTL Duration `json:"job_status_ttl"`
+}
+
+// WorkersConfig sizes the worker pools
+type WorkersConfig struct {
+	View      PoolConfig `json:"view"`
+	Favourite PoolConfig `json:"favourite"`
+	Watchlist PoolConfig `json:"watchlist"`
+	// ViewBatchSize is the most views inserted at once
+	ViewBatchSize int `json:"view_batch_size"`
+	// ViewBatchWindow is how long a view may wait for its batch to fill
+	ViewBatchWindow Duration `json:"view_batch_window"`
+}
+
+// PoolConfig sizes a worker pool and its queue
+type PoolConfig struct {
+	Workers       int `json:"workers"`
+	QueueCapacity int `json:"queue_capacity"`
+	// SaturationThreshold is the queue depth at which the pool stops accepting
+	// jobs; 0 means 80% of QueueCapacity
+	SaturationThreshold int `json:"saturation_threshold"`
+}
+
+// SaturatedAt returns the queue depth at which the pool stops accepting jobs
+func (p PoolConfig) SaturatedAt() int {
+	if p.SaturationThreshold > 0 {
+		return p.SaturationThreshold
+	}
+	return p.QueueCapacity * 4 / 5
+}
+
+// CacheConfig configures the shared cache and the response cache
+type CacheConfig struct {
+	// Backend is "tiered" (memory in front of Redis), "redis" or "memory"
+	Backend          string `json:"backend"`
+	MemoryMaxEntries int    `json:"memory_max_entries"`
+	// L1TTL is how long the tiered cache keeps memory copies of Redis values
+	L1TTL Duration `json:"l1_ttl"`
+	// ReconnectInterval is how often Redis is retried while it is down
+	ReconnectInterval Duration `json:"reconnect_interval"`
+	// ResponseTTL is how long a cached API response lives without being invalidated
+	ResponseTTL Duration `json:"response_ttl"`
+	// ResponseLocalEntries is how many responses each replica keeps in memory
+	ResponseLocalEntries int `json:"response_local_entries"`
+}
+
+// MetadataConfig configures the cartoon metadata provider and the IMDb rating refresh
+type MetadataConfig struct {
+	// Provider is "omdb" or "fixture" (reads FixtureFile)
+	Provider    string `json:"provider"`
+	OMDbAPIKey  string `json:"omdb_api_key"`
+	FixtureFile string `json:"fixture_file"`
+	// MaxConcurrent caps simultaneous provider calls
+	MaxConcurrent int `json:"max_concurrent"`
+	// BreakerThreshold consecutive failures open the circuit breaker for BreakerCooldown
+	BreakerThreshold int      `json:"breaker_threshold"`
+	BreakerCooldown  Duration `json:"breaker_cooldown"`
+	// NotFoundTTL is how long "not found" answers are cached
+	NotFoundTTL Duration `json:"not_found_ttl"`
+	// DailyQuota caps provider calls per UTC day (0 means unlimited)
+	DailyQuota int `json:"daily_quota"`
+	// RatingMaxAge is how old a stored IMDb rating may get before it is refreshed
+	RatingMaxAge Duration `json:"rating_max_age"`
+	// RefreshBatchSize is the most cartoons one refresh run looks up
+	RefreshBatchSize int `json:"refresh_batch_size"`
+	// RefreshConcurrency is how many lookups a refresh runs at once
+	RefreshConcurrency int `json:"refresh_concurrency"`
+}
+
+// RatingsConfig configures the range of scores users can give
+type RatingsConfig struct {
+	ScaleMin int `json:"scale_min"`
+	ScaleMax int `json:"scale_max"`
+}
+
+// RecentlyViewedConfig configures the recently viewed history
+type RecentlyViewedConfig struct {
+	// Limit is how many cartoons a history lists
+	Limit int `json:"limit"`
+	// Retention is how far back a history goes; older views still count towards view counts
+	Retention Duration `json:"retention"`
+	// CacheTTL is how long a history stays cached before it is rebuilt from the views table
+	CacheTTL Duration `json:"cache_ttl"`
+}
+
+// TrendingConfig configures trending scores
+type TrendingConfig struct {
+	// Windows are the windows scores are computed over, e.g. "24h" or "7d"
+	Windows         []string `json:"windows"`
+	RefreshInterval Duration `json:"refresh_interval"`
+}
+
+// RecommendationsConfig configures the recommendation model
+type RecommendationsConfig struct {
+	RetrainInterval Duration `json:"retrain_interval"`
+}
+
+// RetentionConfig configures how long logs are kept
+type RetentionConfig struct {
+	RequestLogs Duration `json:"request_logs"`
+	AdminLogs   Duration `json:"admin_logs"`
+	JobRuns     Duration `json:"job_runs"`
+}
+
+// Default returns the configuration used when nothing is set
+func Default() Config {
+	consumer := "default"
+	if host, err := os.Hostname(); err == nil && host != "" {
+		consumer = host
+	}
+
+	return Config{
+		Server: ServerConfig{Port: "8080", ShutdownTimeout: Duration(30 * time.Second)},
+		Database: DatabaseConfig{
+			Port:            "5432",
+			SSLMode:         "disable",
+			MaxOpenConns:    5,
+			MaxIdleConns:    2,
+			ConnMaxLifetime: Duration(time.Hour),
+		},
+		Redis: RedisConfig{Port: "6379"},
+		Queue: QueueConfig{
+			Backend:           "redis",
+			VisibilityTimeout: Duration(30 * time.Second),
+			Consumer:          consumer,
+			RetryAfter:        Duration(5 * time.Second),
+			JobStatusTTL:      Duration(time.Hour),
+		},
+		Workers: WorkersConfig{
+			// The view queue also holds the unacknowledged views of open batches
+			View:            PoolConfig{Workers: 5, QueueCapacity: 1000},
+			Favourite:       PoolConfig{Workers: 5, QueueCapacity: 100},
+			Watchlist:       PoolConfig{Workers: 5, QueueCapacity: 100},
+			ViewBatchSize:   100,
+			ViewBatchWindow: Duration(500 * time.Millisecond),
+		},
+		Cache: CacheConfig{
+			Backend:              "tiered",
+			MemoryMaxEntries:     10000,
+			L1TTL:                Duration(30 * time.Second),
+			ReconnectInterval:    Duration(5 * time.Second),
+			ResponseTTL:          Duration(10 * time.Minute),
+			ResponseLocalEntries: 1000,
+		},
+		Metadata: MetadataConfig{
+			Provider:         "omdb",
+			FixtureFile:      "fixtures/metadata.json",
+			MaxConcurrent:    4,
+			BreakerThreshold: 5,
+			BreakerCooldown:  Duration(time.Minute),
+			NotFoundTTL:      Duration(6 * time.Hour),
+			// The OMDb free tier limit
+			DailyQuota:         1000,
+			RatingMaxAge:       Duration(7 * 24 * time.Hour),
+			RefreshBatchSize:   100,
+			RefreshConcurrency: 4,
+		},
+		// Ratings were originally stored on a 1-10 scale
+		Ratings: RatingsConfig{ScaleMin: 1, ScaleMax: 10},
+		RecentlyViewed: RecentlyViewedConfig{
+			Limit:     10,
+			Retention: Duration(90 * 24 * time.Hour),
+			CacheTTL:  Duration(24 * time.Hour),
+		},
+		Trending: TrendingConfig{
+			Windows:         []string{"24h", "7d", "30d"},
+			RefreshInterval: Duration(10 * time.Minute),
+		},
+		Recommendations: RecommendationsConfig{RetrainInterval: Duration(time.Hour)},
+		Retention: RetentionConfig{
+			RequestLogs: Duration(30 * 24 * time.Hour),
+			AdminLogs:   Duration(365 * 24 * time.Hour),
+			JobRuns:     Duration(30 * 24 * time.Hour),
+		},
+	}
+}
+
+// DSN returns the Postgres connection string
+func (d DatabaseConfig) DSN() string {
+	return fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
+		d.Host, d.User, d.Password, d.Name, d.Port, d.SSLMode,
+	)
+}
+
+// Options returns the Redis client options; it returns nil if Redis is not configured
+func (r RedisConfig) Options() (*redis.Options, error) {
+	if r.URL != "" {
+		return redis.ParseURL(r.URL)
+	}
+	if r.Host == "" {
+		return nil, nil
+	}
+	return &redis.Options{
+		Addr:     net.JoinHostPort(r.Host, r.Port),
+		Password: r.Password,
+		DB:       r.DB,
+	}, nil
+}
+
+// Duration is a time.Duration written as a string ("1h30m", "7d") in the
+// config file, the environment and flags
+type Duration time.Duration
+
+// ParseDuration parses a time.ParseDuration value or a whole number of days ("7d")
+func ParseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// UnmarshalJSON parses a duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"1h\": %w", err)
+	}
+	return d.Set(s)
+}
+
+// MarshalJSON writes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// String returns the duration as a string
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// Set parses a duration flag
+func (d *Duration) Set(s string) error {
+	parsed, err := ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// Load reads the configuration from the config file, the environment and the
+// command-line args (os.Args[1:]), then validates it
+func Load(args []string) (Config, error) {
+	loadDotEnv()
+
+	cfg := Default()
+	fs := flag.NewFlagSet("disney", flag.ContinueOnError)
+	path := fs.String("config", os.Getenv("CONFIG_FILE"), "JSON config file")
+	cfg.bindFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	// Parsing wrote the flags into cfg; remember them to apply them again last
+	flags := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		flags[f.Name] = f.Value.String()
+	})
+
+	cfg = Default()
+	if *path != "" {
+		if err := cfg.loadFile(*path); err != nil {
+			return cfg, err
+		}
+	}
+	if err := cfg.loadEnv(); err != nil {
+		return cfg, err
+	}
+	for name, value := range flags {
+		if err := fs.Set(name, value); err != nil {
+			return cfg, err
+		}
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// loadDotEnv loads .env file only in local development
+func loadDotEnv() {
+	if os.Getenv("RENDER") == "" {
+		// local development only
+		if err := godotenv.Load(); err != nil {
+			log.Println("No .env file found, using system env")
+		}
+	}
+}
+
+// loadFile overlays a JSON config file; fields it leaves out keep their values
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv overlays the environment variables that are set
+func (c *Config) loadEnv() error {
+	var env envReader
+
+	// Render sets PORT; SERVER_PORT is for local development
+	env.string("SERVER_PORT", &c.Server.Port)
+	env.string("PORT", &c.Server.Port)
+	env.duration("SHUTDOWN_TIMEOUT", &c.Server.ShutdownTimeout)
+
+	env.string("DB_HOST", &c.Database.Host)
+	env.string("DB_PORT", &c.Database.Port)
+	env.string("DB_USER", &c.Database.User)
+	env.string("DB_PASSWORD", &c.Database.Password)
+	env.string("DB_NAME", &c.Database.Name)
+	env.string("DB_SSLMODE", &c.Database.SSLMode)
+	env.int("DB_MAX_OPEN_CONNS", &c.Database.MaxOpenConns)
+	env.int("DB_MAX_IDLE_CONNS", &c.Database.MaxIdleConns)
+	env.duration("DB_CONN_MAX_LIFETIME", &c.Database.ConnMaxLifetime)
+
+	env.string("REDIS_URL", &c.Redis.URL)
+	env.string("REDIS_HOST", &c.Redis.Host)
+	env.string("REDIS_PORT", &c.Redis.Port)
+	env.string("REDIS_PASSWORD", &c.Redis.Password)
+	env.int("REDIS_DB", &c.Redis.DB)
+
+	env.string("JWT_SECRET_KEY", &c.Auth.JWTSecret)
+	env.string("ADMIN_SECRET_KEY", &c.Auth.AdminSecret)
+
+	env.string("QUEUE_BACKEND", &c.Queue.Backend)
+	env.duration("QUEUE_VISIBILITY_TIMEOUT", &c.Queue.VisibilityTimeout)
+	env.string("QUEUE_CONSUMER", &c.Queue.Consumer)
+	env.duration("QUEUE_RETRY_AFTER", &c.Queue.RetryAfter)
+	env.duration("JOB_STATUS_TTL", &c.Queue.JobStatusTTL)
+
+	for prefix, pool := range map[string]*PoolConfig{
+		"VIEW":      &c.Workers.View,
+		"FAVOURITE": &c.Workers.Favourite,
+		"WATCHLIST": &c.Workers.Watchlist,
+	} {
+		env.int(prefix+"_WORKERS", &pool.Workers)
+		env.int(prefix+"_QUEUE_CAPACITY", &pool.QueueCapacity)
+		env.int(prefix+"_QUEUE_THRESHOLD", &pool.SaturationThreshold)
+	}
+	env.int("VIEW_BATCH_SIZE", &c.Workers.ViewBatchSize)
+	env.duration("VIEW_BATCH_WINDOW", &c.Workers.ViewBatchWindow)
+
+	env.string("CACHE_BACKEND", &c.Cache.Backend)
+	env.int("CACHE_MEMORY_MAX_ENTRIES", &c.Cache.MemoryMaxEntries)
+	env.duration("CACHE_L1_TTL", &c.Cache.L1TTL)
+	env.duration("CACHE_RECONNECT_INTERVAL", &c.Cache.ReconnectInterval)
+	env.duration("RESPONSE_CACHE_TTL", &c.Cache.ResponseTTL)
+	env.int("RESPONSE_CACHE_LOCAL_ENTRIES", &c.Cache.ResponseLocalEntries)
+
+	env.string("METADATA_PROVIDER", &c.Metadata.Provider)
+	env.string("OMDB_API_KEY", &c.Metadata.OMDbAPIKey)
+	env.string("METADATA_FIXTURE_FILE", &c.Metadata.FixtureFile)
+	env.int("METADATA_MAX_CONCURRENT", &c.Metadata.MaxConcurrent)
+	env.int("METADATA_BREAKER_THRESHOLD", &c.Metadata.BreakerThreshold)
+	env.duration("METADATA_BREAKER_COOLDOWN", &c.Metadata.BreakerCooldown)
+	env.duration("METADATA_NOT_FOUND_TTL", &c.Metadata.NotFoundTTL)
+	env.int("OMDB_DAILY_QUOTA", &c.Metadata.DailyQuota)
+	env.duration("IMDB_RATING_MAX_AGE", &c.Metadata.RatingMaxAge)
+	env.int("IMDB_REFRESH_BATCH_SIZE", &c.Metadata.RefreshBatchSize)
+	env.int("IMDB_REFRESH_CONCURRENCY", &c.Metadata.RefreshConcurrency)
+
+	env.int("RATING_SCALE_MIN", &c.Ratings.ScaleMin)
+	env.int("RATING_SCALE_MAX", &c.Ratings.ScaleMax)
+
+	env.int("RECENTLY_VIEWED_LIMIT", &c.RecentlyViewed.Limit)
+	env.duration("RECENTLY_VIEWED_RETENTION", &c.RecentlyViewed.Retention)
+	env.duration("RECENTLY_VIEWED_CACHE_TTL", &c.RecentlyViewed.CacheTTL)
+
+	env.list("TRENDING_WINDOWS", &c.Trending.Windows)
+	env.duration("TRENDING_REFRESH_INTERVAL", &c.Trending.RefreshInterval)
+
+	env.duration("RECOMMENDATION_RETRAIN_INTERVAL", &c.Recommendations.RetrainInterval)
+
+	env.duration("REQUEST_LOG_RETENTION", &c.Retention.RequestLogs)
+	env.duration("ADMIN_LOG_RETENTION", &c.Retention.AdminLogs)
+	env.duration("JOB_RUN_RETENTION", &c.Retention.JobRuns)
+
+	if len(env.errs) > 0 {
+		return fmt.Errorf("invalid environment: %w", errors.Join(env.errs...))
+	}
+	return nil
+}
+
+// bindFlags defines the command-line flags, writing into c
+func (c *Config) bindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Server.Port, "port", c.Server.Port, "HTTP port")
+	fs.StringVar(&c.Database.Host, "db-host", c.Database.Host, "Postgres host")
+	fs.StringVar(&c.Database.Port, "db-port", c.Database.Port, "Postgres port")
+	fs.StringVar(&c.Database.Name, "db-name", c.Database.Name, "Postgres database")
+	fs.IntVar(&c.Database.MaxOpenConns, "db-max-open-conns", c.Database.MaxOpenConns, "maximum open database connections")
+	fs.IntVar(&c.Database.MaxIdleConns, "db-max-idle-conns", c.Database.MaxIdleConns, "maximum idle database connections")
+	fs.Var(&c.Database.ConnMaxLifetime, "db-conn-max-lifetime", "maximum lifetime of a database connection")
+	fs.StringVar(&c.Redis.URL, "redis-url", c.Redis.URL, "Redis URL (overrides -redis-host)")
+	fs.StringVar(&c.Redis.Host, "redis-host", c.Redis.Host, "Redis host")
+	fs.StringVar(&c.Redis.Port, "redis-port", c.Redis.Port, "Redis port")
+	fs.StringVar(&c.Queue.Backend, "queue-backend", c.Queue.Backend, "queue backend: redis or memory")
+	fs.IntVar(&c.Workers.View.Workers, "view-workers", c.Workers.View.Workers, "view worker pool size")
+	fs.IntVar(&c.Workers.View.QueueCapacity, "view-queue-capacity", c.Workers.View.QueueCapacity, "view queue capacity")
+	fs.IntVar(&c.Workers.Favourite.Workers, "favourite-workers", c.Workers.Favourite.Workers, "favourite worker pool size")
+	fs.IntVar(&c.Workers.Favourite.QueueCapacity, "favourite-queue-capacity", c.Workers.Favourite.QueueCapacity, "favourite queue capacity")
+	fs.IntVar(&c.Workers.Watchlist.Workers, "watchlist-workers", c.Workers.Watchlist.Workers, "watchlist worker pool size")
+	fs.IntVar(&c.Workers.Watchlist.QueueCapacity, "watchlist-queue-capacity", c.Workers.Watchlist.QueueCapacity, "watchlist queue capacity")
+	fs.StringVar(&c.Cache.Backend, "cache-backend", c.Cache.Backend, "cache backend: tiered, redis or memory")
+	fs.StringVar(&c.Metadata.Provider, "metadata-provider", c.Metadata.Provider, "metadata provider: omdb or fixture")
+	fs.StringVar(&c.Metadata.FixtureFile, "metadata-fixture-file", c.Metadata.FixtureFile, "metadata fixture file")
+}
+
+// envReader overlays environment variables, collecting parse errors
+type envReader struct {
+	errs []error
+}
+
+// string sets *dst to the variable's value if it is set
+func (e *envReader) string(key string, dst *string) {
+	if v := os.Getenv(key); v != "" {
+		*dst = v
+	}
+}
+
+// int sets *dst to the variable's value if it is set
+func (e *envReader) int(key string, dst *int) {
+	v := os.Getenv(key)
+	if v == "" {
+		return
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s must be a number, got %q", key, v))
+		return
+	}
+	*dst = n
+}
+
+// duration sets *dst to the variable's value, such as "30m" or "7d", if it is set
+func (e *envReader) duration(key string, dst *Duration) {
+	v := os.Getenv(key)
+	if v == "" {
+		return
+	}
+	if err := dst.Set(v); err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s must be a duration such as 30m or 7d, got %q", key, v))
+	}
+}
+
+// list sets *dst to the variable's comma separated values if it is set
+func (e *envReader) list(key string, dst *[]string) {
+	v := os.Getenv(key)
+	if v == "" {
+		return
+	}
+	var values []string
+	for _, value := range strings.Split(v, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	*dst = values
+}
@@ -3,7 +3,6 @@ package config
 import (
 	"context"
 	"log"
-	"os"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -12,17 +11,14 @@ import (
 var RedisClient *redis.Client
 
 // InitRedis initializes the Redis client connection
-func InitRedis() {
-	redisURL := os.Getenv("REDIS_URL")
-
-	if redisURL == "" {
-		log.Println("⚠️ REDIS_URL not set – Redis disabled")
+func InitRedis(cfg RedisConfig) {
+	opt, err := cfg.Options()
+	if err != nil {
+		log.Println("❌ Invalid Redis configuration:", err)
 		return
 	}
-
-	opt, err := redis.ParseURL(redisURL)
-	if err != nil {
-		log.Println("❌ Invalid REDIS_URL:", err)
+	if opt == nil {
+		log.Println("⚠️ Neither REDIS_URL nor REDIS_HOST set – Redis disabled")
 		return
 	}
 
@@ -33,7 +29,7 @@ func InitRedis() {
 
 	if err := RedisClient.Ping(ctx).Err(); err != nil {
 		log.Println("❌ Redis connection failed:", err)
-		log.Println("⚠️ Recently viewed feature will be disabled")
+		log.Println("⚠️ Caches fall back to memory until Redis is reachable")
 		RedisClient = nil
 		return
 	}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Validate checks the configuration, reporting every problem at once
+func (c *Config) Validate() error {
+	var errs []error
+	required := func(name, value string) {
+		if strings.TrimSpace(value) == "" {
+			errs = append(errs, fmt.Errorf("%s is required", name))
+		}
+	}
+	port := func(name, value string) {
+		if n, err := strconv.Atoi(value); err != nil || n < 1 || n > 65535 {
+			errs = append(errs, fmt.Errorf("%s must be a port number, got %q", name, value))
+		}
+	}
+	positive := func(name string, value int) {
+		if value < 1 {
+			errs = append(errs, fmt.Errorf("%s must be at least 1", name))
+		}
+	}
+	positiveDuration := func(name string, value Duration) {
+		if value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be a positive duration", name))
+		}
+	}
+	oneOf := func(name, value string, allowed ...string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+		errs = append(errs, fmt.Errorf("%s must be one of %s, got %q", name, strings.Join(allowed, ", "), value))
+	}
+
+	port("server port (PORT)", c.Server.Port)
+	positiveDuration("SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout)
+
+	required("database host (DB_HOST)", c.Database.Host)
+	required("database user (DB_USER)", c.Database.User)
+	required("database name (DB_NAME)", c.Database.Name)
+	port("database port (DB_PORT)", c.Database.Port)
+	switch c.Database.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		errs = append(errs, fmt.Errorf("database sslmode (DB_SSLMODE) %q is not a Postgres sslmode", c.Database.SSLMode))
+	}
+	positive("DB_MAX_OPEN_CONNS", c.Database.MaxOpenConns)
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		errs = append(errs, errors.New("DB_MAX_IDLE_CONNS must be between 0 and DB_MAX_OPEN_CONNS"))
+	}
+	positiveDuration("DB_CONN_MAX_LIFETIME", c.Database.ConnMaxLifetime)
+
+	if c.Redis.URL != "" {
+		if _, err := redis.ParseURL(c.Redis.URL); err != nil {
+			errs = append(errs, fmt.Errorf("invalid REDIS_URL: %w", err))
+		}
+	} else if c.Redis.Host != "" {
+		port("Redis port (REDIS_PORT)", c.Redis.Port)
+	}
+	if c.Redis.DB < 0 {
+		errs = append(errs, errors.New("REDIS_DB must be a non-negative number"))
+	}
+
+	required("JWT secret (JWT_SECRET_KEY)", c.Auth.JWTSecret)
+
+	oneOf("QUEUE_BACKEND", c.Queue.Backend, "redis", "memory")
+	positiveDuration("QUEUE_VISIBILITY_TIMEOUT", c.Queue.VisibilityTimeout)
+	required("queue consumer name (QUEUE_CONSUMER)", c.Queue.Consumer)
+	positiveDuration("QUEUE_RETRY_AFTER", c.Queue.RetryAfter)
+	positiveDuration("JOB_STATUS_TTL", c.Queue.JobStatusTTL)
+
+	for name, pool := range map[string]PoolConfig{
+		"VIEW":      c.Workers.View,
+		"FAVOURITE": c.Workers.Favourite,
+		"WATCHLIST": c.Workers.Watchlist,
+	} {
+		positive(name+"_WORKERS", pool.Workers)
+		positive(name+"_QUEUE_CAPACITY", pool.QueueCapacity)
+		if pool.SaturationThreshold < 0 || pool.SaturationThreshold > pool.QueueCapacity {
+			errs = append(errs, fmt.Errorf("%s_QUEUE_THRESHOLD must be between 0 and %s_QUEUE_CAPACITY", name, name))
+		}
+	}
+	positive("VIEW_BATCH_SIZE", c.Workers.ViewBatchSize)
+	positiveDuration("VIEW_BATCH_WINDOW", c.Workers.ViewBatchWindow)
+
+	oneOf("CACHE_BACKEND", c.Cache.Backend, "tiered", "redis", "memory")
+	positive("CACHE_MEMORY_MAX_ENTRIES", c.Cache.MemoryMaxEntries)
+	positiveDuration("CACHE_L1_TTL", c.Cache.L1TTL)
+	positiveDuration("CACHE_RECONNECT_INTERVAL", c.Cache.ReconnectInterval)
+	positiveDuration("RESPONSE_CACHE_TTL", c.Cache.ResponseTTL)
+	positive("RESPONSE_CACHE_LOCAL_ENTRIES", c.Cache.ResponseLocalEntries)
+
+	oneOf("METADATA_PROVIDER", c.Metadata.Provider, "omdb", "fixture")
+	if c.Metadata.Provider == "fixture" {
+		required("metadata fixture file (METADATA_FIXTURE_FILE)", c.Metadata.FixtureFile)
+	}
+	positive("METADATA_MAX_CONCURRENT", c.Metadata.MaxConcurrent)
+	positive("METADATA_BREAKER_THRESHOLD", c.Metadata.BreakerThreshold)
+	positiveDuration("METADATA_BREAKER_COOLDOWN", c.Metadata.BreakerCooldown)
+	positiveDuration("METADATA_NOT_FOUND_TTL", c.Metadata.NotFoundTTL)
+	if c.Metadata.DailyQuota < 0 {
+		errs = append(errs, errors.New("OMDB_DAILY_QUOTA must be a non-negative number"))
+	}
+	positiveDuration("IMDB_RATING_MAX_AGE", c.Metadata.RatingMaxAge)
+	positive("IMDB_REFRESH_BATCH_SIZE", c.Metadata.RefreshBatchSize)
+	positive("IMDB_REFRESH_CONCURRENCY", c.Metadata.RefreshConcurrency)
+
+	if c.Ratings.ScaleMin >= c.Ratings.ScaleMax {
+		errs = append(errs, fmt.Errorf("RATING_SCALE_MIN (%d) must be below RATING_SCALE_MAX (%d)", c.Ratings.ScaleMin, c.Ratings.ScaleMax))
+	}
+
+	positive("RECENTLY_VIEWED_LIMIT", c.RecentlyViewed.Limit)
+	positiveDuration("RECENTLY_VIEWED_RETENTION", c.RecentlyViewed.Retention)
+	positiveDuration("RECENTLY_VIEWED_CACHE_TTL", c.RecentlyViewed.CacheTTL)
+
+	if len(c.Trending.Windows) == 0 {
+		errs = append(errs, errors.New("TRENDING_WINDOWS must list at least one window"))
+	}
+	for _, window := range c.Trending.Windows {
+		if d, err := ParseDuration(window); err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("TRENDING_WINDOWS has an invalid window %q", window))
+		}
+	}
+	positiveDuration("TRENDING_REFRESH_INTERVAL", c.Trending.RefreshInterval)
+
+	positiveDuration("RECOMMENDATION_RETRAIN_INTERVAL", c.Recommendations.RetrainInterval)
+
+	positiveDuration("REQUEST_LOG_RETENTION", c.Retention.RequestLogs)
+	positiveDuration("ADMIN_LOG_RETENTION", c.Retention.AdminLogs)
+	positiveDuration("JOB_RUN_RETENTION", c.Retention.JobRuns)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Summary describes the configuration for the startup log, with secrets redacted
+func (c *Config) Summary() string {
+	redis := "disabled (in-memory caches and queues)"
+	switch {
+	case c.Redis.URL != "":
+		redis = redactURL(c.Redis.URL)
+	case c.Redis.Host != "":
+		redis = fmt.Sprintf("%s db=%d password=%s", net.JoinHostPort(c.Redis.Host, c.Redis.Port), c.Redis.DB, redact(c.Redis.Password))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Configuration:\n")
+	fmt.Fprintf(&b, "  server:    port=%s shutdown_timeout=%s\n", c.Server.Port, c.Server.ShutdownTimeout)
+	fmt.Fprintf(&b, "  database:  %s@%s/%s sslmode=%s password=%s\n",
+		c.Database.User, net.JoinHostPort(c.Database.Host, c.Database.Port), c.Database.Name, c.Database.SSLMode, redact(c.Database.Password))
+	fmt.Fprintf(&b, "  db pool:   max_open=%d max_idle=%d max_lifetime=%s\n",
+		c.Database.MaxOpenConns, c.Database.MaxIdleConns, c.Database.ConnMaxLifetime)
+	fmt.Fprintf(&b, "  redis:     %s\n", redis)
+	fmt.Fprintf(&b, "  auth:      jwt_secret=%s admin_secret=%s\n", redact(c.Auth.JWTSecret), redact(c.Auth.AdminSecret))
+	fmt.Fprintf(&b, "  queue:     backend=%s consumer=%s visibility_timeout=%s retry_after=%s job_status_ttl=%s\n",
+		c.Queue.Backend, c.Queue.Consumer, c.Queue.VisibilityTimeout, c.Queue.RetryAfter, c.Queue.JobStatusTTL)
+	fmt.Fprintf(&b, "  workers:   view=%d/%d/%d favourite=%d/%d/%d watchlist=%d/%d/%d (workers/queue capacity/saturated at)\n",
+		c.Workers.View.Workers, c.Workers.View.QueueCapacity, c.Workers.View.SaturatedAt(),
+		c.Workers.Favourite.Workers, c.Workers.Favourite.QueueCapacity, c.Workers.Favourite.SaturatedAt(),
+		c.Workers.Watchlist.Workers, c.Workers.Watchlist.QueueCapacity, c.Workers.Watchlist.SaturatedAt())
+	fmt.Fprintf(&b, "  views:     batch_size=%d batch_window=%s\n", c.Workers.ViewBatchSize, c.Workers.ViewBatchWindow)
+	fmt.Fprintf(&b, "  cache:     backend=%s memory_max_entries=%d l1_ttl=%s reconnect_interval=%s\n",
+		c.Cache.Backend, c.Cache.MemoryMaxEntries, c.Cache.L1TTL, c.Cache.ReconnectInterval)
+	fmt.Fprintf(&b, "  responses: ttl=%s local_entries=%d\n", c.Cache.ResponseTTL, c.Cache.ResponseLocalEntries)
+	fmt.Fprintf(&b, "  metadata:  provider=%s omdb_api_key=%s fixture_file=%s max_concurrent=%d breaker=%d/%s not_found_ttl=%s daily_quota=%d\n",
+		c.Metadata.Provider, redact(c.Metadata.OMDbAPIKey), c.Metadata.FixtureFile, c.Metadata.MaxConcurrent,
+		c.Metadata.BreakerThreshold, c.Metadata.BreakerCooldown, c.Metadata.NotFoundTTL, c.Metadata.DailyQuota)
+	fmt.Fprintf(&b, "  imdb:      rating_max_age=%s refresh_batch_size=%d refresh_concurrency=%d\n",
+		c.Metadata.RatingMaxAge, c.Metadata.RefreshBatchSize, c.Metadata.RefreshConcurrency)
+	fmt.Fprintf(&b, "  ratings:   scale=%d-%d\n", c.Ratings.ScaleMin, c.Ratings.ScaleMax)
+	fmt.Fprintf(&b, "  history:   limit=%d retention=%s cache_ttl=%s\n",
+		c.RecentlyViewed.Limit, c.RecentlyViewed.Retention, c.RecentlyViewed.CacheTTL)
+	fmt.Fprintf(&b, "  trending:  windows=%s refresh_interval=%s\n", strings.Join(c.Trending.Windows, ","), c.Trending.RefreshInterval)
+	fmt.Fprintf(&b, "  recs:      retrain_interval=%s\n", c.Recommendations.RetrainInterval)
+	fmt.Fprintf(&b, "  retention: request_logs=%s admin_logs=%s job_runs=%s",
+		c.Retention.RequestLogs, c.Retention.AdminLogs, c.Retention.JobRuns)
+	return b.String()
+}
+
+// redact hides a secret, only telling whether it is set
+func redact(secret string) string {
+	if secret == "" {
+		return "(not set)"
+	}
+	return "****"
+}
+
+// redactURL hides the password in a URL
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "(invalid URL)"
+	}
+	return u.Redacted()
+}
@@ -1,53 +1,23 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
+	"disney/config"
 	"disney/models"
 
-	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-// loadEnv loads .env file only in local development
-func loadEnv() {
-	if os.Getenv("RENDER") == "" {
-		// local development only
-		if err := godotenv.Load(); err != nil {
-			log.Println("No .env file found, using system env")
-		}
-	}
-}
-
 var DB *gorm.DB
 
-func InitDB() {
-	// Load .env file only in local development
-	loadEnv()
-
-	// Get SSL mode (default to disable for local, require for production)
-	sslMode := os.Getenv("DB_SSLMODE")
-	if sslMode == "" {
-		sslMode = "disable"
-	}
-
-	// Build DSN from env variables
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-		os.Getenv("DB_PORT"),
-		sslMode,
-	)
-
+func InitDB(cfg config.DatabaseConfig) {
 	// db connection
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{})
 	if err != nil {
 		log.Fatal("Failed to connect to database")
 	}
@@ -58,11 +28,14 @@ func InitDB() {
 		log.Fatal("Failed to get sql DB")
 	}
 
-	sqlDB.SetMaxOpenConns(5)        // 🔥 VERY IMPORTANT (Supabase safe)
-	sqlDB.SetMaxIdleConns(2)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns) // 🔥 VERY IMPORTANT (Supabase safe, 5 by default)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime))
 	log.Println("Database connected")
 
+	// Data fixes that must happen before new constraints are created
+	runPreMigrations()
+
 	// Auto migrate tables
 	DB.AutoMigrate(
 		&models.User{},
@@ -71,8 +44,18 @@ func InitDB() {
 		&models.Cartoon{},
 		&models.Character{},
 		&models.Rating{},
+		&models.RatingHistory{},
+		&models.ReviewVote{},
+		&models.ReviewReport{},
 		&models.Favourite{},
+		&models.Watchlist{},
+		&models.WatchlistItem{},
 		&models.View{},
+		&models.HistoryRemoval{},
+		&models.TrendingScore{},
+		&models.DeadLetterJob{},
+		&models.ScheduledJobRun{},
+		&models.SchedulerLock{},
 		&models.AdminLog{},
 		&models.RequestLog{},
 		&models.TimeTable{},
@@ -87,26 +70,71 @@ func InitDB() {
 	seedDefaultData()
 }
 
+// CloseDB closes the database connection pool
+func CloseDB() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// sequenceTables maps tables with auto-increment IDs to their sequence names
+var sequenceTables = map[string]string{
+	"users":              "users_id_seq",
+	"genres":             "genres_id_seq",
+	"age_groups":         "age_groups_id_seq",
+	"cartoons":           "cartoons_id_seq",
+	"characters":         "characters_id_seq",
+	"ratings":            "ratings_id_seq",
+	"rating_histories":   "rating_histories_id_seq",
+	"review_votes":       "review_votes_id_seq",
+	"review_reports":     "review_reports_id_seq",
+	"favourites":         "favourites_id_seq",
+	"watchlists":         "watchlists_id_seq",
+	"watchlist_items":    "watchlist_items_id_seq",
+	"views":              "views_id_seq",
+	"trending_scores":    "trending_scores_id_seq",
+	"dead_letter_jobs":   "dead_letter_jobs_id_seq",
+	"scheduled_job_runs": "scheduled_job_runs_id_seq",
+	"admin_logs":         "admin_logs_id_seq",
+	"request_logs":       "request_logs_id_seq",
+	"time_tables":        "time_tables_id_seq",
+}
+
+// CheckSequences repairs sequences that fell behind their table's highest ID
+// (e.g. after rows were copied in with explicit IDs) and returns the fixed tables
+func CheckSequences(ctx context.Context) ([]string, error) {
+	var fixed []string
+	for table, sequence := range sequenceTables {
+		var maxID, lastValue int64
+		if err := DB.WithContext(ctx).Raw(fmt.Sprintf("SELECT COALESCE(MAX(id), 0) FROM %s", table)).Scan(&maxID).Error; err != nil {
+			return fixed, err
+		}
+		if err := DB.WithContext(ctx).Raw(fmt.Sprintf("SELECT last_value FROM %s", sequence)).Scan(&lastValue).Error; err != nil {
+			return fixed, err
+		}
+		if lastValue >= maxID {
+			continue
+		}
+
+		if err := DB.WithContext(ctx).Exec("SELECT setval(?, ?, true)", sequence, maxID).Error; err != nil {
+			return fixed, err
+		}
+		log.Printf("Sequence %s was behind %s (%d < %d), fixed", sequence, table, lastValue, maxID)
+		fixed = append(fixed, table)
+	}
+	return fixed, nil
+}
+
 // fixSequences resets auto-increment sequences to avoid primary key conflicts
 func fixSequences() {
 	log.Println("Fixing auto-increment sequences...")
 
-	// List of tables with their sequence names
-	tables := map[string]string{
-		"users":        "users_id_seq",
-		"genres":       "genres_id_seq",
-		"age_groups":   "age_groups_id_seq",
-		"cartoons":     "cartoons_id_seq",
-		"characters":   "characters_id_seq",
-		"ratings":      "ratings_id_seq",
-		"favourites":   "favourites_id_seq",
-		"views":        "views_id_seq",
-		"admin_logs":   "admin_logs_id_seq",
-		"request_logs": "request_logs_id_seq",
-		"time_tables":  "time_tables_id_seq",
-	}
-
-	for table, sequence := range tables {
+	for table, sequence := range sequenceTables {
 		// Reset sequence to current max ID + 1
 		query := fmt.Sprintf(`
 			SELECT setval('%s', COALESCE((SELECT MAX(id) FROM %s), 0) + 1, false);
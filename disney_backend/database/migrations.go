@@ -0,0 +1,69 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"disney/models"
+)
+
+// runPreMigrations applies data fixes that AutoMigrate depends on
+// Each step must be safe to run on every startup and on an empty database
+func runPreMigrations() {
+	dedupeRatings()
+}
+
+// dedupeRatings removes duplicate (user_id, cartoon_id) ratings so the
+// unique idx_ratings_user_cartoon index can be created
+// The most recent row (highest ID) is kept for each pair
+func dedupeRatings() {
+	if !DB.Migrator().HasTable(&models.Rating{}) {
+		return
+	}
+
+	result := DB.Exec(`
+		DELETE FROM ratings older
+		USING ratings newer
+		WHERE older.user_id = newer.user_id
+		  AND older.cartoon_id = newer.cartoon_id
+		  AND older.id < newer.id
+	`)
+	if result.Error != nil {
+		log.Printf("Warning: Could not remove duplicate ratings: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Removed %d duplicate ratings", result.RowsAffected)
+	}
+
+	// The old non-unique index is replaced by idx_ratings_user_cartoon
+	if err := DB.Exec(`DROP INDEX IF EXISTS idx_user_cartoon`).Error; err != nil {
+		log.Printf("Warning: Could not drop old rating index: %v", err)
+	}
+}
+
+// EnsureRatingScale replaces the ratings check constraint with the configured scale
+// The constraint is added NOT VALID so rows rated under an older scale are kept
+func EnsureRatingScale(min, max int) {
+	if err := DB.Exec(`ALTER TABLE ratings DROP CONSTRAINT IF EXISTS chk_ratings_rating`).Error; err != nil {
+		log.Printf("Warning: Could not drop rating check constraint: %v", err)
+		return
+	}
+
+	query := fmt.Sprintf(
+		`ALTER TABLE ratings ADD CONSTRAINT chk_ratings_rating CHECK (rating >= %d AND rating <= %d) NOT VALID`,
+		min, max,
+	)
+	if err := DB.Exec(query).Error; err != nil {
+		log.Printf("Warning: Could not add rating check constraint: %v", err)
+		return
+	}
+
+	var outOfScale int64
+	DB.Model(&models.Rating{}).Where("rating < ? OR rating > ?", min, max).Count(&outOfScale)
+	if outOfScale > 0 {
+		log.Printf("Warning: %d existing ratings are outside the %d-%d scale", outOfScale, min, max)
+	}
+
+	log.Printf("Rating scale set to %d-%d", min, max)
+}
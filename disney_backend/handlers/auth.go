@@ -5,11 +5,23 @@ import (
 	"disney/models"
 	"disney/utils"
 	"net/http"
-	"os"
 
 	"github.com/gin-gonic/gin"
 )
 
+// AuthHandler serves signup, login and admin creation
+// Created in main.go with the token issuer and admin secret from the configuration
+type AuthHandler struct {
+	tokens *utils.TokenIssuer
+	// adminSecret lets CreateAdmin create admins; admin signup is disabled without it
+	adminSecret string
+}
+
+// NewAuthHandler creates an AuthHandler
+func NewAuthHandler(tokens *utils.TokenIssuer, adminSecret string) *AuthHandler {
+	return &AuthHandler{tokens: tokens, adminSecret: adminSecret}
+}
+
 // SignupRequest represents signup request payload
 type SignupRequest struct {
 	Name     string `json:"name" binding:"required"`
@@ -53,7 +65,7 @@ type AuthResponse struct {
 }
 
 // Signup handles user registration
-func Signup(c *gin.Context) {
+func (h *AuthHandler) Signup(c *gin.Context) {
 	var req SignupRequest
 
 	// Validate request payload
@@ -129,7 +141,7 @@ func Signup(c *gin.Context) {
 }
 
 // Login handles user authentication
-func Login(c *gin.Context) {
+func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 
 	// Validate request payload
@@ -161,7 +173,7 @@ func Login(c *gin.Context) {
 	}
 
 	// Generate JWT token (only on successful login)
-	token, err := utils.GenerateToken(user.ID, user.Email, user.Role)
+	token, err := h.tokens.Generate(user.ID, user.Email, user.Role)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, AuthResponse{
 			Message: "Error generating token",
@@ -188,7 +200,7 @@ func Login(c *gin.Context) {
 }
 
 // CreateAdmin handles admin user creation with secret key validation
-func CreateAdmin(c *gin.Context) {
+func (h *AuthHandler) CreateAdmin(c *gin.Context) {
 	var req AdminCreateRequest
 
 	// Validate request payload
@@ -200,8 +212,8 @@ func CreateAdmin(c *gin.Context) {
 		return
 	}
 
-	// Validate secret key from environment
-	adminSecretKey := os.Getenv("ADMIN_SECRET_KEY")
+	// Validate secret key from the configuration
+	adminSecretKey := h.adminSecret
 	if adminSecretKey == "" {
 		c.JSON(http.StatusInternalServerError, AuthResponse{
 			Message: "Server configuration error",
@@ -267,7 +279,7 @@ func CreateAdmin(c *gin.Context) {
 	}
 
 	// Generate JWT token for the new admin
-	token, err := utils.GenerateToken(newAdmin.ID, newAdmin.Email, newAdmin.Role)
+	token, err := h.tokens.Generate(newAdmin.ID, newAdmin.Email, newAdmin.Role)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, AuthResponse{
 			Message: "Error generating token",
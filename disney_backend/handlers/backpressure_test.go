@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"disney/jobs"
+	"disney/workers"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+// setPools replaces the worker pool instances for the test
+// The pools are not started, so queued jobs stay in their queues
+func setPools(t *testing.T, view *workers.ViewWorkerPool, favourite *workers.FavouriteWorkerPool, watchlist *workers.WatchlistWorkerPool) {
+	t.Helper()
+	previousView, previousFavourite, previousWatchlist := ViewWorkerPoolInstance, FavouriteWorkerPoolInstance, WatchlistWorkerPoolInstance
+	ViewWorkerPoolInstance, FavouriteWorkerPoolInstance, WatchlistWorkerPoolInstance = view, favourite, watchlist
+	t.Cleanup(func() {
+		ViewWorkerPoolInstance, FavouriteWorkerPoolInstance, WatchlistWorkerPoolInstance = previousView, previousFavourite, previousWatchlist
+	})
+}
+
+// idlePools returns unsaturated pools with room for capacity jobs each
+func idlePools(capacity int, opts workers.PoolOptions) (*workers.ViewWorkerPool, *workers.FavouriteWorkerPool, *workers.WatchlistWorkerPool) {
+	tracker := workers.NewJobTracker(nil, time.Minute)
+	return workers.NewViewWorkerPool(workers.NewMemoryQueue(capacity), tracker, opts),
+		workers.NewFavouriteWorkerPool(workers.NewMemoryQueue(capacity), tracker, opts),
+		workers.NewWatchlistWorkerPool(workers.NewMemoryQueue(capacity), tracker, opts)
+}
+
+func TestAddFavouriteProcessesSynchronouslyWhenSaturated(t *testing.T) {
+	view, favourite, watchlist := idlePools(10, workers.PoolOptions{SaturationThreshold: 1})
+	setPools(t, view, favourite, watchlist)
+	favourite.Enqueue(context.Background(), jobs.NewFavouriteJob(2, 9, "add"))
+
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(`FROM "cartoons" WHERE "cartoons"."id" = \$1`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery(`INSERT INTO "favourites"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	w := serve(t, AddFavourite, http.MethodPost, "/", `{"cartoon_id": 3}`, nil, 5)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", w.Code, w.Body)
+	}
+	var body struct {
+		Status string `json:"status"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body.Status != jobs.StatusSucceeded || favourite.GetQueueLength() != 1 {
+		t.Errorf("status %q with %d queued, want the job processed without queueing", body.Status, favourite.GetQueueLength())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRecordViewRejectsWhenQueueFull(t *testing.T) {
+	view, favourite, watchlist := idlePools(1, workers.PoolOptions{RetryAfter: 2500 * time.Millisecond})
+	setPools(t, view, favourite, watchlist)
+	view.Enqueue(context.Background(), jobs.NewViewJob(2, 9))
+
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(`FROM "cartoons" WHERE "cartoons"."id" = \$1`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+
+	w := serve(t, RecordView, http.MethodPost, "/", `{"cartoon_id": 3}`, nil, 5)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status %d, want 503: %s", w.Code, w.Body)
+	}
+	if retryAfter := w.Header().Get("Retry-After"); retryAfter != "3" {
+		t.Errorf("Retry-After %q, want 3", retryAfter)
+	}
+}
+
+func TestRespondEnqueueFailedRetryAfter(t *testing.T) {
+	for _, tt := range []struct {
+		retryAfter time.Duration
+		want       string
+	}{
+		{5 * time.Second, "5"},
+		{1400 * time.Millisecond, "1"},
+		{100 * time.Millisecond, "1"},
+		{0, "1"},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		respondEnqueueFailed(c, tt.retryAfter, "job-1", errors.New("queue is full"))
+		if got := w.Header().Get("Retry-After"); got != tt.want || w.Code != http.StatusServiceUnavailable {
+			t.Errorf("retry after %s: status %d, Retry-After %q, want 503 and %q", tt.retryAfter, w.Code, got, tt.want)
+		}
+	}
+}
+
+func TestHealthDegradesWhenPoolSaturated(t *testing.T) {
+	view, favourite, watchlist := idlePools(10, workers.PoolOptions{SaturationThreshold: 1})
+	setPools(t, view, favourite, watchlist)
+	mockDB(t)
+
+	health := func() (int, string) {
+		w := serve(t, Health, http.MethodGet, "/", "", nil, 0)
+		var body struct {
+			Status string `json:"status"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return w.Code, body.Status
+	}
+
+	if code, status := health(); code != http.StatusOK || status != healthOK {
+		t.Errorf("idle pools: %d %q, want 200 ok", code, status)
+	}
+	watchlist.Enqueue(context.Background(), jobs.NewWatchlistJob(1, 2, 3, "add", ""))
+	if code, status := health(); code != http.StatusOK || status != healthDegraded {
+		t.Errorf("saturated pool: %d %q, want 200 degraded", code, status)
+	}
+}
@@ -1,23 +1,30 @@
 package handlers
 
 import (
+	"context"
 	"disney/database"
 	"disney/models"
 	"disney/services"
-	"log"
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 // GetAllCartoonNames returns all cartoon names with poster URLs, year, and IMDb rating for display
 func GetAllCartoonNames(c *gin.Context) {
+	const cacheKey = "cartoons:names"
+	if serveCachedResponse(c, "names", cacheKey) {
+		return
+	}
+
 	var cartoons []models.Cartoon
 
-	// Query ID, Title, PosterURL, and ReleaseYear fields for display
-	if err := database.DB.Select("id", "title", "poster_url", "release_year").Find(&cartoons).Error; err != nil {
+	// Query ID, Title, PosterURL, ReleaseYear and the stored IMDb rating for display
+	if err := database.DB.Select("id", "title", "poster_url", "release_year", "imdb_rating").Find(&cartoons).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "Failed to fetch cartoons",
 			"error":   err.Error(),
@@ -28,19 +35,17 @@ func GetAllCartoonNames(c *gin.Context) {
 	// Extract cartoon data with IMDb ratings
 	var cartoonNames []map[string]interface{}
 	for _, cartoon := range cartoons {
-		// Fetch IMDb rating for each cartoon
-		imdbRating := services.FetchIMDbRating(cartoon.Title)
-
 		cartoonNames = append(cartoonNames, map[string]interface{}{
 			"id":           cartoon.ID,
 			"title":        cartoon.Title,
 			"poster_url":   cartoon.PosterURL,
 			"release_year": cartoon.ReleaseYear,
-			"imdb_rating":  imdbRating,
+			"imdb_rating":  services.FormatIMDbRating(cartoon.IMDbRating),
 		})
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	// Every cartoon change invalidates the names list
+	respondAndCache(c, cacheKey, []string{services.TagCartoonNames}, time.Time{}, gin.H{
 		"message": "Cartoons fetched successfully",
 		"data":    cartoonNames,
 		"count":   len(cartoonNames),
@@ -58,6 +63,11 @@ func GetCartoonsByCharacter(c *gin.Context) {
 		return
 	}
 
+	cacheKey := "cartoons:by-character:" + strings.ToLower(characterName)
+	if serveCachedResponse(c, "by-character", cacheKey) {
+		return
+	}
+
 	var cartoons []models.Cartoon
 	if err := database.DB.Joins("JOIN characters ON characters.cartoon_id = cartoons.id").
 		Where("characters.name ILIKE ?", "%"+characterName+"%").
@@ -70,7 +80,8 @@ func GetCartoonsByCharacter(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	// Character changes invalidate every character list
+	respondAndCache(c, cacheKey, cartoonListTags(cartoons, services.TagCharacterLists), time.Time{}, gin.H{
 		"message": "Cartoons fetched successfully",
 		"data":    cartoons,
 		"count":   len(cartoons),
@@ -88,6 +99,26 @@ func GetCartoonsByGenre(c *gin.Context) {
 		return
 	}
 
+	cacheKey := "cartoons:by-genre:" + strings.ToLower(genreName)
+	if serveCachedResponse(c, "by-genre", cacheKey) {
+		return
+	}
+
+	// The list changes with the cartoons of every matching genre, even if it has none yet
+	var genreIDs []uint
+	if err := database.DB.Model(&models.Genre{}).Where("name ILIKE ?", "%"+genreName+"%").
+		Pluck("id", &genreIDs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to fetch cartoons",
+			"error":   err.Error(),
+		})
+		return
+	}
+	tags := []string{services.TagGenreLists}
+	for _, id := range genreIDs {
+		tags = append(tags, services.GenreTag(id))
+	}
+
 	var cartoons []models.Cartoon
 	if err := database.DB.Joins("JOIN genres ON genres.id = cartoons.genre_id").
 		Where("genres.name ILIKE ?", "%"+genreName+"%").
@@ -100,7 +131,7 @@ func GetCartoonsByGenre(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	respondAndCache(c, cacheKey, cartoonListTags(cartoons, tags...), time.Time{}, gin.H{
 		"message": "Cartoons fetched successfully",
 		"data":    cartoons,
 		"count":   len(cartoons),
@@ -118,8 +149,21 @@ func GetCartoonsByYear(c *gin.Context) {
 		return
 	}
 
+	releaseYear, err := strconv.Atoi(year)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid year",
+			"error":   "Year must be a number",
+		})
+		return
+	}
+	cacheKey := "cartoons:by-year:" + strconv.Itoa(releaseYear)
+	if serveCachedResponse(c, "by-year", cacheKey) {
+		return
+	}
+
 	var cartoons []models.Cartoon
-	if err := database.DB.Where("release_year = ?", year).
+	if err := database.DB.Where("release_year = ?", releaseYear).
 		Preload("Genre").Preload("AgeGroup").
 		Find(&cartoons).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -129,7 +173,7 @@ func GetCartoonsByYear(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	respondAndCache(c, cacheKey, cartoonListTags(cartoons, services.YearTag(releaseYear)), time.Time{}, gin.H{
 		"message": "Cartoons fetched successfully",
 		"data":    cartoons,
 		"count":   len(cartoons),
@@ -147,6 +191,26 @@ func GetCartoonsByAgeGroup(c *gin.Context) {
 		return
 	}
 
+	cacheKey := "cartoons:by-age-group:" + strings.ToLower(ageGroupLabel)
+	if serveCachedResponse(c, "by-age-group", cacheKey) {
+		return
+	}
+
+	// The list changes with the cartoons of every matching age group, even if it has none yet
+	var ageGroupIDs []uint
+	if err := database.DB.Model(&models.AgeGroup{}).Where("label ILIKE ?", "%"+ageGroupLabel+"%").
+		Pluck("id", &ageGroupIDs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to fetch cartoons",
+			"error":   err.Error(),
+		})
+		return
+	}
+	var tags []string
+	for _, id := range ageGroupIDs {
+		tags = append(tags, services.AgeGroupTag(id))
+	}
+
 	var cartoons []models.Cartoon
 	if err := database.DB.Joins("JOIN age_groups ON age_groups.id = cartoons.age_group_id").
 		Where("age_groups.label ILIKE ?", "%"+ageGroupLabel+"%").
@@ -159,13 +223,21 @@ func GetCartoonsByAgeGroup(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	respondAndCache(c, cacheKey, cartoonListTags(cartoons, tags...), time.Time{}, gin.H{
 		"message": "Cartoons fetched successfully",
 		"data":    cartoons,
 		"count":   len(cartoons),
 	})
 }
 
+// cartoonListTags tags a cached cartoon list with its cartoons, besides the given list tags
+func cartoonListTags(cartoons []models.Cartoon, tags ...string) []string {
+	for _, cartoon := range cartoons {
+		tags = append(tags, services.CartoonTag(cartoon.ID))
+	}
+	return tags
+}
+
 // CartoonDetailResponse represents the cartoon detail response with IMDb rating
 type CartoonDetailResponse struct {
 	ID          uint               `json:"id"`
@@ -179,12 +251,15 @@ type CartoonDetailResponse struct {
 	CreatedAt   string             `json:"created_at"`
 	UpdatedAt   string             `json:"updated_at"`
 	IMDbRating  string             `json:"imdb_rating"`
+	IMDbID      string             `json:"imdb_id,omitempty"`
 	Genre       *models.Genre      `json:"genre,omitempty"`
 	AgeGroup    *models.AgeGroup   `json:"age_group,omitempty"`
 	Characters  []models.Character `json:"characters,omitempty"`
 }
 
-// GetCartoonByID retrieves a specific cartoon by its ID and tracks it as recently viewed
+// GetCartoonByID retrieves a specific cartoon by its ID
+// It records nothing: clients record views with RecordView, which keeps the
+// durable history in the views table
 func GetCartoonByID(c *gin.Context) {
 	cartoonID := c.Param("id")
 	if cartoonID == "" {
@@ -194,10 +269,23 @@ func GetCartoonByID(c *gin.Context) {
 		})
 		return
 	}
+	id, err := strconv.ParseUint(cartoonID, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "Cartoon not found",
+			"error":   "Invalid cartoon ID",
+		})
+		return
+	}
+
+	cacheKey := "cartoons:detail:" + strconv.FormatUint(id, 10)
+	if serveCachedResponse(c, "detail", cacheKey) {
+		return
+	}
 
 	var cartoon models.Cartoon
 	if err := database.DB.Preload("Genre").Preload("AgeGroup").Preload("Characters").
-		First(&cartoon, cartoonID).Error; err != nil {
+		First(&cartoon, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"message": "Cartoon not found",
 			"error":   err.Error(),
@@ -205,29 +293,7 @@ func GetCartoonByID(c *gin.Context) {
 		return
 	}
 
-	// Track the viewed cartoon in recently viewed list
-	// This will add the cartoon to recently viewed when details are fetched
-	userID, exists := c.Get("userID")
-	if exists && userID != nil {
-		// userID from context is uint, convert to int
-		uid := int(userID.(uint))
-		cid := int(cartoon.ID)
-
-		// Add to recently viewed cache (async - don't block response if Redis fails)
-		go func() {
-			if err := services.AddRecentlyViewed(uid, cid); err != nil {
-				// Log error but don't fail the request
-				log.Printf("WARNING: Failed to add to recently viewed: %v", err)
-			} else {
-				log.Printf("SUCCESS: Added cartoon %d to recently viewed for user %d", cid, uid)
-			}
-		}()
-	}
-
-	// Fetch IMDb rating
-	imdbRating := services.FetchIMDbRating(cartoon.Title)
-
-	// Build response with IMDb rating
+	// Build response with the stored IMDb rating (refreshed in the background)
 	response := CartoonDetailResponse{
 		ID:          cartoon.ID,
 		Title:       cartoon.Title,
@@ -239,13 +305,16 @@ func GetCartoonByID(c *gin.Context) {
 		IsFeatured:  cartoon.IsFeatured,
 		CreatedAt:   cartoon.CreatedAt.String(),
 		UpdatedAt:   cartoon.UpdatedAt.String(),
-		IMDbRating:  imdbRating,
+		IMDbRating:  services.FormatIMDbRating(cartoon.IMDbRating),
+		IMDbID:      cartoon.IMDbID,
 		Genre:       &cartoon.Genre,
 		AgeGroup:    &cartoon.AgeGroup,
 		Characters:  cartoon.Characters,
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	// No Last-Modified: the IMDb rating and characters change without moving
+	// updated_at, so revalidation relies on the ETag alone
+	respondAndCache(c, cacheKey, []string{services.CartoonTag(cartoon.ID)}, time.Time{}, gin.H{
 		"message": "Cartoon fetched successfully",
 		"data":    response,
 	})
@@ -253,22 +322,42 @@ func GetCartoonByID(c *gin.Context) {
 
 // TrendingCartoonResponse represents a cartoon in the trending list with IMDb rating
 type TrendingCartoonResponse struct {
-	ID          uint             `json:"id"`
-	Title       string           `json:"title"`
-	Description string           `json:"description"`
-	PosterURL   string           `json:"poster_url"`
-	ReleaseYear int              `json:"release_year"`
-	IMDbRating  string           `json:"imdb_rating"`
-	Genre       *models.Genre    `json:"genre,omitempty"`
-	AgeGroup    *models.AgeGroup `json:"age_group,omitempty"`
+	ID            uint             `json:"id"`
+	Title         string           `json:"title"`
+	Description   string           `json:"description"`
+	PosterURL     string           `json:"poster_url"`
+	ReleaseYear   int              `json:"release_year"`
+	IMDbRating    string           `json:"imdb_rating"`
+	TrendingScore float64          `json:"trending_score"`
+	Genre         *models.Genre    `json:"genre,omitempty"`
+	AgeGroup      *models.AgeGroup `json:"age_group,omitempty"`
 }
 
-// GetTrendingCartoons returns top 5 cartoons sorted by IMDb rating
+// GetTrendingCartoons returns the top cartoons by engagement over a time window
+// Query parameters: window (e.g. 24h, 7d, 30d; default 7d) and limit (default 5, max 50)
 func GetTrendingCartoons(c *gin.Context) {
-	var cartoons []models.Cartoon
+	window := c.DefaultQuery("window", services.DefaultTrendingWindow)
+	if _, ok := services.FindTrendingWindow(window); !ok {
+		names := []string{}
+		for _, w := range services.GetTrendingWindows() {
+			names = append(names, w.Name)
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid trending window",
+			"error":   "window must be one of: " + strings.Join(names, ", "),
+		})
+		return
+	}
+
+	limit := 5
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 50 {
+			limit = parsed
+		}
+	}
 
-	// Fetch only top 20 cartoons to reduce load
-	if err := database.DB.Preload("Genre").Preload("AgeGroup").Limit(20).Find(&cartoons).Error; err != nil {
+	trendingList, err := loadTrendingCartoons(c.Request.Context(), window, limit)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": "Failed to fetch cartoons",
 			"error":   err.Error(),
@@ -276,42 +365,78 @@ func GetTrendingCartoons(c *gin.Context) {
 		return
 	}
 
-	// Build response with cached IMDb ratings
-	var trendingList []TrendingCartoonResponse
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Trending cartoons fetched successfully",
+		"data":    trendingList,
+		"count":   len(trendingList),
+		"window":  window,
+	})
+}
+
+// loadTrendingCartoons returns the top cartoons of a window by materialised trending score
+// The IMDb rating is only used to order cartoons with equal scores
+func loadTrendingCartoons(ctx context.Context, window string, limit int) ([]TrendingCartoonResponse, error) {
+	entries, err := services.RankTrending(ctx, window, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return []TrendingCartoonResponse{}, nil
+	}
+
+	ids := make([]uint, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.CartoonID
+	}
+
+	cartoons, err := services.LoadCartoons(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[uint]float64, len(entries))
+	for _, entry := range entries {
+		scores[entry.CartoonID] = entry.Score
+	}
+
+	// Build response with stored IMDb ratings
+	trendingList := make([]TrendingCartoonResponse, 0, len(cartoons))
 	for _, cartoon := range cartoons {
-		imdbRating := services.FetchIMDbRating(cartoon.Title)
 		trendingList = append(trendingList, TrendingCartoonResponse{
-			ID:          cartoon.ID,
-			Title:       cartoon.Title,
-			Description: cartoon.Description,
-			PosterURL:   cartoon.PosterURL,
-			ReleaseYear: cartoon.ReleaseYear,
-			IMDbRating:  imdbRating,
-			Genre:       &cartoon.Genre,
-			AgeGroup:    &cartoon.AgeGroup,
+			ID:            cartoon.ID,
+			Title:         cartoon.Title,
+			Description:   cartoon.Description,
+			PosterURL:     cartoon.PosterURL,
+			ReleaseYear:   cartoon.ReleaseYear,
+			IMDbRating:    services.FormatIMDbRating(cartoon.IMDbRating),
+			TrendingScore: scores[cartoon.ID],
+			Genre:         &cartoon.Genre,
+			AgeGroup:      &cartoon.AgeGroup,
 		})
 	}
 
-	// Sort by IMDb rating (descending)
-	sort.Slice(trendingList, func(i, j int) bool {
+	// Sort by trending score, then IMDb rating (descending); unrated titles go last
+	sort.SliceStable(trendingList, func(i, j int) bool {
+		if trendingList[i].TrendingScore != trendingList[j].TrendingScore {
+			return trendingList[i].TrendingScore > trendingList[j].TrendingScore
+		}
 		ratingI, errI := strconv.ParseFloat(trendingList[i].IMDbRating, 64)
 		ratingJ, errJ := strconv.ParseFloat(trendingList[j].IMDbRating, 64)
-		if errI != nil || errJ != nil {
+		if errI != nil {
 			return false
 		}
+		if errJ != nil {
+			return true
+		}
 		return ratingI > ratingJ
 	})
 
-	// Return top 5
-	if len(trendingList) > 5 {
-		trendingList = trendingList[:5]
+	// Return top N
+	if len(trendingList) > limit {
+		trendingList = trendingList[:limit]
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Trending cartoons fetched successfully",
-		"data":    trendingList,
-		"count":   len(trendingList),
-	})
+	return trendingList, nil
 }
 
 // CreateCartoonRequest represents the request to create a new cartoon with characters
@@ -414,6 +539,14 @@ func CreateCartoon(c *gin.Context) {
 	// Load relationships for response
 	database.DB.Preload("Genre").Preload("AgeGroup").First(&cartoon, cartoon.ID)
 
+	// Catalogue changed, cached related lists and catalogue responses are stale
+	services.InvalidateRelatedCache()
+	tags := services.CartoonResponseTags(cartoon)
+	if len(req.Characters) > 0 {
+		tags = append(tags, services.TagCharacterLists)
+	}
+	services.InvalidateResponses(tags...)
+
 	// Log admin action
 	if adminID, exists := c.Get("userID"); exists {
 		adminLog := models.AdminLog{
@@ -471,6 +604,10 @@ func DeleteCartoon(c *gin.Context) {
 		return
 	}
 
+	// Catalogue changed, cached related lists and catalogue responses are stale
+	services.InvalidateRelatedCache()
+	services.InvalidateResponses(append(services.CartoonResponseTags(cartoon), services.TagCharacterLists)...)
+
 	// Log admin action
 	if adminID, exists := c.Get("userID"); exists {
 		adminLog := models.AdminLog{
@@ -516,7 +653,7 @@ func GetRecentlyViewed(c *gin.Context) {
 	// Convert uint to int
 	userID := int(userIDInterface.(uint))
 
-	// Get cartoon IDs from Redis
+	// Get cartoon IDs from the cache
 	cartoonIDs, err := services.GetRecentlyViewed(userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -526,27 +663,43 @@ func GetRecentlyViewed(c *gin.Context) {
 		return
 	}
 
+	paused, err := services.HistoryPaused(c.Request.Context(), uint(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to fetch recently viewed settings",
+			"error":   err.Error(),
+		})
+		return
+	}
+
 	// If no recently viewed cartoons, return empty list
 	if len(cartoonIDs) == 0 {
 		c.JSON(http.StatusOK, gin.H{
-			"message": "No recently viewed cartoons",
-			"data":    []GetRecentlyViewedResponse{},
-			"count":   0,
+			"message":        "No recently viewed cartoons",
+			"data":           []GetRecentlyViewedResponse{},
+			"count":          0,
+			"history_paused": paused,
 		})
 		return
 	}
 
-	// Fetch full cartoon details from database in the order they appear in Redis
-	var response []GetRecentlyViewedResponse
-
-	for _, cartoonID := range cartoonIDs {
-		var cartoon models.Cartoon
-		if err := database.DB.Preload("Genre").Preload("AgeGroup").
-			First(&cartoon, cartoonID).Error; err != nil {
-			// Skip if cartoon not found, continue with others
-			continue
-		}
+	// Fetch full cartoon details in one query, in the order they appear in the list
+	// (deleted cartoons are skipped)
+	ids := make([]uint, len(cartoonIDs))
+	for i, id := range cartoonIDs {
+		ids[i] = uint(id)
+	}
+	cartoons, err := services.LoadCartoons(c.Request.Context(), ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to fetch recently viewed cartoons",
+			"error":   err.Error(),
+		})
+		return
+	}
 
+	response := make([]GetRecentlyViewedResponse, 0, len(cartoons))
+	for _, cartoon := range cartoons {
 		response = append(response, GetRecentlyViewedResponse{
 			CartoonID:   cartoon.ID,
 			Title:       cartoon.Title,
@@ -559,8 +712,92 @@ func GetRecentlyViewed(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Recently viewed cartoons fetched successfully",
-		"data":    response,
-		"count":   len(response),
+		"message":        "Recently viewed cartoons fetched successfully",
+		"data":           response,
+		"count":          len(response),
+		"history_paused": paused,
+	})
+}
+
+// RemoveRecentlyViewed removes a cartoon from the authenticated user's recently viewed history
+func RemoveRecentlyViewed(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	cartoonID, err := strconv.ParseUint(c.Param("cartoon_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid cartoon ID",
+			"error":   "Cartoon ID must be a number",
+		})
+		return
+	}
+
+	if err := services.RemoveRecentlyViewed(c.Request.Context(), userID, uint(cartoonID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to remove cartoon from recently viewed",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cartoon removed from recently viewed",
+	})
+}
+
+// ClearRecentlyViewed clears the authenticated user's recently viewed history
+// View counts are unaffected
+func ClearRecentlyViewed(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	if err := services.ClearRecentlyViewed(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to clear recently viewed",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Recently viewed cleared",
+	})
+}
+
+// RecentlyViewedSettingsRequest represents the request to update recently viewed settings
+type RecentlyViewedSettingsRequest struct {
+	// Paused stops new views from being added to the history
+	Paused *bool `json:"paused" binding:"required"`
+}
+
+// UpdateRecentlyViewedSettings pauses or resumes the authenticated user's recently viewed history
+func UpdateRecentlyViewedSettings(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req RecentlyViewedSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid request",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if err := services.SetHistoryPaused(c.Request.Context(), userID, *req.Paused); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to update recently viewed settings",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	message := "Recently viewed history resumed"
+	if *req.Paused {
+		message = "Recently viewed history paused"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data": gin.H{
+			"history_paused": *req.Paused,
+		},
 	})
 }
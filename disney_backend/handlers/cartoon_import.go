@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"disney/database"
+	"disney/models"
+	"disney/services"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportCartoonRequest represents the request to import a cartoon from the metadata provider
+type ImportCartoonRequest struct {
+	// Title (and optional Year) or IMDbID identifies the cartoon to import
+	Title  string `json:"title"`
+	Year   int    `json:"year"`
+	IMDbID string `json:"imdb_id"`
+	// Create creates the cartoon right away instead of returning a draft for review
+	Create bool `json:"create"`
+	// AllowNewGenres creates the provider's first genre when none of its genres exist
+	AllowNewGenres bool `json:"allow_new_genres"`
+	// GenreID and AgeGroupID override the mapped genre and inferred age group
+	GenreID    uint `json:"genre_id"`
+	AgeGroupID uint `json:"age_group_id"`
+	IsFeatured bool `json:"is_featured"`
+}
+
+// ImportCartoon looks a cartoon up with the metadata provider by title or IMDb ID (Admin only)
+// By default it returns a pre-filled draft to review and send to CreateCartoon;
+// with "create" it creates the cartoon directly
+func ImportCartoon(c *gin.Context) {
+	var req ImportCartoonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid request",
+			"error":   err.Error(),
+		})
+		return
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	req.IMDbID = strings.TrimSpace(req.IMDbID)
+	if req.Title == "" && req.IMDbID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Title or IMDb ID is required",
+			"error":   "Please provide 'title' or 'imdb_id'",
+		})
+		return
+	}
+	if req.IMDbID != "" && !services.ValidIMDbID(req.IMDbID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid IMDb ID",
+			"error":   "IMDb IDs look like tt0092345",
+		})
+		return
+	}
+
+	query := services.MetadataQuery{Title: req.Title, Year: req.Year}
+	if req.IMDbID != "" {
+		query = services.MetadataQuery{IMDbID: req.IMDbID}
+	}
+	draft, err := services.DraftCartoonImport(c.Request.Context(), MetadataProviderInstance, query, req.AllowNewGenres)
+	switch {
+	case errors.Is(err, services.ErrMetadataNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "No metadata found for this cartoon",
+			"error":   err.Error(),
+		})
+		return
+	case errors.Is(err, services.ErrMetadataUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"message": "Metadata provider is unavailable",
+			"error":   err.Error(),
+		})
+		return
+	case errors.Is(err, services.ErrImportLookup):
+		c.JSON(http.StatusBadGateway, gin.H{
+			"message": "Failed to fetch cartoon metadata",
+			"error":   err.Error(),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to prepare cartoon import",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// Verify overrides exist
+	if req.GenreID != 0 {
+		var genre models.Genre
+		if err := database.DB.First(&genre, req.GenreID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid genre ID",
+				"error":   "Genre not found",
+			})
+			return
+		}
+		draft.GenreID = genre.ID
+		draft.GenreName = genre.Name
+		draft.NewGenre = false
+	}
+	if req.AgeGroupID != 0 {
+		var ageGroup models.AgeGroup
+		if err := database.DB.First(&ageGroup, req.AgeGroupID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": "Invalid age group ID",
+				"error":   "Age group not found",
+			})
+			return
+		}
+		draft.AgeGroupID = ageGroup.ID
+		draft.AgeGroupLabel = ageGroup.Label
+	}
+
+	if !req.Create {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Cartoon import draft created, review it before creating the cartoon",
+			"data":    draft,
+		})
+		return
+	}
+
+	var missing []string
+	if draft.GenreID == 0 && !draft.NewGenre {
+		missing = append(missing, "genre_id (no provider genre matched; set allow_new_genres to create one)")
+	}
+	if draft.AgeGroupID == 0 {
+		missing = append(missing, "age_group_id (content rating "+strings.TrimSpace(draft.Rated+" ")+"could not be mapped)")
+	}
+	if draft.ReleaseYear == 0 {
+		missing = append(missing, "release_year (unknown to the provider; create the cartoon from the draft instead)")
+	}
+	if len(missing) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Cartoon cannot be created from the provider's data alone",
+			"error":   "Missing " + strings.Join(missing, ", "),
+			"data":    draft,
+		})
+		return
+	}
+
+	cartoon, err := services.CreateImportedCartoon(c.Request.Context(), draft, req.IsFeatured)
+	if errors.Is(err, services.ErrCartoonExists) {
+		c.JSON(http.StatusConflict, gin.H{
+			"message": "Cartoon already exists",
+			"error":   err.Error(),
+			"data":    draft,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to create cartoon",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// Load relationships for response
+	database.DB.Preload("Genre").Preload("AgeGroup").First(cartoon, cartoon.ID)
+
+	// Catalogue changed, cached related lists and catalogue responses are stale
+	services.InvalidateRelatedCache()
+	tags := services.CartoonResponseTags(*cartoon)
+	if draft.NewGenre {
+		// Genre-filtered lists may now match the new genre
+		tags = append(tags, services.TagGenreLists)
+	}
+	services.InvalidateResponses(tags...)
+
+	// Log admin action
+	if adminID, exists := c.Get("userID"); exists {
+		entity := "Cartoon: " + cartoon.Title + " (imported from " + MetadataProviderInstance.Name()
+		if draft.NewGenre {
+			entity += ", new genre " + draft.GenreName
+		}
+		database.DB.Create(&models.AdminLog{
+			AdminID: adminID.(uint),
+			Action:  "IMPORT",
+			Entity:  entity + ")",
+		})
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Cartoon imported successfully",
+		"data":    cartoon,
+	})
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"disney/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestImportCartoonRejectsInvalidRequests(t *testing.T) {
+	mock, _ := mockDB(t)
+	for _, body := range []string{`{}`, `{"title": "  "}`, `{"imdb_id": "0092345"}`, `not json`} {
+		if w := serve(t, ImportCartoon, http.MethodPost, "/", body, nil, 99); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", body, w.Code)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestImportCartoonReturnsDraftOrRefusesIncompleteCreate(t *testing.T) {
+	provider := &stubMetadataProvider{metadata: &services.Metadata{Title: "Gargoyles", Year: "1994", Rated: "Unrated", Genres: []string{"Comedy"},
+		ExternalIDs: map[string]string{"imdb": "tt0108783"}}}
+	setMetadataProvider(t, provider)
+
+	for _, create := range []bool{false, true} {
+		mock, _ := mockDB(t)
+		mock.ExpectQuery(`SELECT \* FROM "genres"`).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Comedy"))
+		mock.ExpectQuery(`SELECT "id" FROM "cartoons"`).WithArgs("tt0108783", "Gargoyles", 1994, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		body := `{"imdb_id": "tt0108783", "create": false}`
+		want := http.StatusOK
+		if create {
+			body = strings.Replace(body, "false", "true", 1)
+			// The unrated cartoon has no age group, so it cannot be created as is
+			want = http.StatusBadRequest
+		}
+		w := serve(t, ImportCartoon, http.MethodPost, "/", body, nil, 99)
+		if w.Code != want {
+			t.Fatalf("create %v: status %d, want %d: %s", create, w.Code, want, w.Body)
+		}
+		if provider.query != (services.MetadataQuery{IMDbID: "tt0108783"}) {
+			t.Errorf("looked up %+v, want the IMDb ID", provider.query)
+		}
+
+		var response struct {
+			Error string                 `json:"error"`
+			Data  services.CartoonImport `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if response.Data.GenreID != 1 || response.Data.Title != "Gargoyles" {
+			t.Errorf("create %v: draft %+v", create, response.Data)
+		}
+		if create && !strings.Contains(response.Error, "age_group_id") {
+			t.Errorf("error %q does not name the missing age group", response.Error)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestImportCartoonSeparatesProviderAndDatabaseErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		providerErr error
+		genresErr   error
+		want        int
+	}{
+		{"not found", services.ErrMetadataNotFound, nil, http.StatusNotFound},
+		{"unavailable", services.ErrCircuitOpen, nil, http.StatusServiceUnavailable},
+		{"provider failure", errors.New("omdb returned status 500"), nil, http.StatusBadGateway},
+		{"database failure", nil, errors.New("connection refused"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		setMetadataProvider(t, &stubMetadataProvider{metadata: &services.Metadata{Title: "Gargoyles"}, err: tt.providerErr})
+		mock, _ := mockDB(t)
+		if tt.genresErr != nil {
+			mock.ExpectQuery(`SELECT \* FROM "genres"`).WillReturnError(tt.genresErr)
+		}
+
+		if w := serve(t, ImportCartoon, http.MethodPost, "/", `{"title": "Gargoyles"}`, nil, 99); w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, w.Code, tt.want, w.Body)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"disney/database"
 	"disney/models"
+	"disney/services"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -66,6 +67,9 @@ func UpdateCartoon(c *gin.Context) {
 		return
 	}
 
+	// Cached lists of the cartoon's old genre, age group and year go stale too
+	before := cartoon
+
 	// Start transaction
 	tx := database.DB.Begin()
 	defer func() {
@@ -96,6 +100,14 @@ func UpdateCartoon(c *gin.Context) {
 	// Load relationships for response
 	database.DB.Preload("Genre").Preload("AgeGroup").First(&cartoon, cartoon.ID)
 
+	// Catalogue changed, cached related lists and catalogue responses are stale
+	services.InvalidateRelatedCache()
+	tags := append(services.CartoonResponseTags(before), services.CartoonResponseTags(cartoon)...)
+	if req.Characters != nil {
+		tags = append(tags, services.TagCharacterLists)
+	}
+	services.InvalidateResponses(tags...)
+
 	// Log admin action
 	if adminID, exists := c.Get("userID"); exists {
 		adminLog := models.AdminLog{
@@ -3,6 +3,7 @@ package handlers
 import (
 	"disney/database"
 	"disney/models"
+	"disney/services"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -39,6 +40,11 @@ func CreateCharacter(c *gin.Context) {
 		return
 	}
 
+	// Character names feed related scoring, so cached related lists are stale,
+	// as are the cartoon's details and character-filtered lists
+	services.InvalidateRelatedCache()
+	services.InvalidateResponses(services.CartoonTag(character.CartoonID), services.TagCharacterLists)
+
 	// Log admin action
 	if adminID, exists := c.Get("userID"); exists {
 		adminLog := models.AdminLog{
@@ -101,6 +107,11 @@ func UpdateCharacter(c *gin.Context) {
 		return
 	}
 
+	// Character names feed related scoring, so cached related lists are stale,
+	// as are the cartoon's details and character-filtered lists
+	services.InvalidateRelatedCache()
+	services.InvalidateResponses(services.CartoonTag(character.CartoonID), services.TagCharacterLists)
+
 	// Log admin action
 	if adminID, exists := c.Get("userID"); exists {
 		adminLog := models.AdminLog{
@@ -133,6 +144,11 @@ func DeleteCharacter(c *gin.Context) {
 		return
 	}
 
+	// Character names feed related scoring, so cached related lists are stale,
+	// as are the cartoon's details and character-filtered lists
+	services.InvalidateRelatedCache()
+	services.InvalidateResponses(services.CartoonTag(character.CartoonID), services.TagCharacterLists)
+
 	// Log admin action
 	if adminID, exists := c.Get("userID"); exists {
 		adminLog := models.AdminLog{
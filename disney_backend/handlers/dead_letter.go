@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"disney/database"
+	"disney/jobs"
+	"disney/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jobReplayer re-enqueues a dead-lettered job payload
+type jobReplayer interface {
+	Replay(payload []byte) error
+}
+
+// jobReplayers maps each job type to the worker pool that processes it
+func jobReplayers() map[string]jobReplayer {
+	return map[string]jobReplayer{
+		jobs.TypeView:      ViewWorkerPoolInstance,
+		jobs.TypeFavourite: FavouriteWorkerPoolInstance,
+		jobs.TypeWatchlist: WatchlistWorkerPoolInstance,
+	}
+}
+
+// logDeadLetterAction records admin actions on the dead-letter store
+func logDeadLetterAction(c *gin.Context, action, entity string) {
+	if adminID, exists := c.Get("userID"); exists {
+		adminLog := models.AdminLog{
+			AdminID: adminID.(uint),
+			Action:  action,
+			Entity:  entity,
+		}
+		database.DB.Create(&adminLog)
+	}
+}
+
+// GetDeadLetterJobs lists jobs that failed after all retries (Admin only)
+// Supports ?job_type=view|favourite|watchlist and ?replayed=true|false
+func GetDeadLetterJobs(c *gin.Context) {
+	page, pageSize := parsePagination(c, 50, 200)
+
+	query := database.DB.Model(&models.DeadLetterJob{})
+	if jobType := c.Query("job_type"); jobType != "" {
+		query = query.Where("job_type = ?", jobType)
+	}
+	switch c.Query("replayed") {
+	case "true":
+		query = query.Where("replayed_at IS NOT NULL")
+	case "false":
+		query = query.Where("replayed_at IS NULL")
+	}
+
+	var totalCount int64
+	if err := query.Count(&totalCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to count dead-letter jobs"})
+		return
+	}
+
+	var deadLetters []models.DeadLetterJob
+	offset := (page - 1) * pageSize
+	if err := query.Order("failed_at DESC").Offset(offset).Limit(pageSize).Find(&deadLetters).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch dead-letter jobs"})
+		return
+	}
+
+	totalPages := (int(totalCount) + pageSize - 1) / pageSize
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Dead-letter jobs fetched successfully",
+		"data":    deadLetters,
+		"pagination": gin.H{
+			"current_page": page,
+			"page_size":    pageSize,
+			"total_count":  totalCount,
+			"total_pages":  totalPages,
+		},
+	})
+}
+
+// GetDeadLetterJob returns a single dead-lettered job with its payload (Admin only)
+func GetDeadLetterJob(c *gin.Context) {
+	var deadLetter models.DeadLetterJob
+	if err := database.DB.First(&deadLetter, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "Dead-letter job not found",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Dead-letter job fetched successfully",
+		"data":    deadLetter,
+	})
+}
+
+// ReplayDeadLetterJob puts a dead-lettered job back on its queue (Admin only)
+// The entry is kept with replayed_at set; if the job fails again a new entry is added
+func ReplayDeadLetterJob(c *gin.Context) {
+	var deadLetter models.DeadLetterJob
+	if err := database.DB.First(&deadLetter, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "Dead-letter job not found",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	replayer, ok := jobReplayers()[deadLetter.JobType]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Cannot replay job",
+			"error":   "Unknown job type " + deadLetter.JobType,
+		})
+		return
+	}
+
+	if err := replayer.Replay([]byte(deadLetter.Payload)); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"message": "Failed to replay job",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	if err := database.DB.Model(&deadLetter).Update("replayed_at", now).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Job replayed but failed to mark it as replayed",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	logDeadLetterAction(c, "REPLAY", "Dead-letter job: "+deadLetter.JobType+" "+deadLetter.JobID)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Job queued for replay",
+		"job_id":  deadLetter.JobID,
+		"status":  jobs.StatusQueued,
+	})
+}
+
+// PurgeDeadLetterJob deletes a single dead-lettered job (Admin only)
+func PurgeDeadLetterJob(c *gin.Context) {
+	var deadLetter models.DeadLetterJob
+	if err := database.DB.First(&deadLetter, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "Dead-letter job not found",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if err := database.DB.Delete(&deadLetter).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to purge dead-letter job",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	logDeadLetterAction(c, "PURGE", "Dead-letter job: "+deadLetter.JobType+" "+deadLetter.JobID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Dead-letter job purged successfully",
+	})
+}
+
+// PurgeDeadLetterJobs deletes dead-lettered jobs in bulk (Admin only)
+// Supports ?job_type= to limit the purge and ?replayed=true to only remove replayed entries
+func PurgeDeadLetterJobs(c *gin.Context) {
+	query := database.DB.Where("1 = 1")
+	entity := "Dead-letter jobs"
+	if jobType := c.Query("job_type"); jobType != "" {
+		query = query.Where("job_type = ?", jobType)
+		entity += " (" + jobType + ")"
+	}
+	if c.Query("replayed") == "true" {
+		query = query.Where("replayed_at IS NOT NULL")
+		entity += " (replayed)"
+	}
+
+	result := query.Delete(&models.DeadLetterJob{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to purge dead-letter jobs",
+			"error":   result.Error.Error(),
+		})
+		return
+	}
+
+	logDeadLetterAction(c, "PURGE", entity)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Dead-letter jobs purged successfully",
+		"purged":  result.RowsAffected,
+	})
+}
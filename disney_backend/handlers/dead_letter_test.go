@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"disney/workers"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+// deadLetterRows returns a dead-lettered job of the given type
+func deadLetterRows(jobType, payload string) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "job_type", "job_id", "payload", "error", "attempts"}).
+		AddRow(3, jobType, "job-1", payload, "deadlock detected", 5)
+}
+
+func TestReplayDeadLetterJob(t *testing.T) {
+	queue := workers.NewMemoryQueue(5)
+	previous := FavouriteWorkerPoolInstance
+	FavouriteWorkerPoolInstance = workers.NewFavouriteWorkerPool(queue, workers.NewJobTracker(nil, time.Minute), workers.PoolOptions{})
+	t.Cleanup(func() { FavouriteWorkerPoolInstance = previous })
+
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(`FROM "dead_letter_jobs" WHERE "dead_letter_jobs"."id" = \$1`).
+		WillReturnRows(deadLetterRows("favourite", `{"ID":"job-1","UserID":5,"CartoonID":3,"Action":"add"}`))
+	mock.ExpectExec(`UPDATE "dead_letter_jobs" SET "replayed_at"=\$1 WHERE "id" = \$2`).
+		WithArgs(sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "admin_logs"`).
+		WithArgs(99, "REPLAY", "Dead-letter job: favourite job-1", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	w := serve(t, ReplayDeadLetterJob, http.MethodPost, "/", "", gin.Params{{Key: "id", Value: "3"}}, 99)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	if queue.Len() != 1 {
+		t.Errorf("queue length %d, want the replayed job", queue.Len())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReplayDeadLetterJobUnknownType(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(`FROM "dead_letter_jobs"`).WillReturnRows(deadLetterRows("email", `{}`))
+
+	w := serve(t, ReplayDeadLetterJob, http.MethodPost, "/", "", gin.Params{{Key: "id", Value: "3"}}, 99)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want 400", w.Code)
+	}
+}
+
+func TestPurgeDeadLetterJobsFilters(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectExec(`DELETE FROM "dead_letter_jobs" WHERE 1 = 1 AND job_type = \$1 AND replayed_at IS NOT NULL`).
+		WithArgs("view").
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectQuery(`INSERT INTO "admin_logs"`).
+		WithArgs(99, "PURGE", "Dead-letter jobs (view) (replayed)", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	w := serve(t, PurgeDeadLetterJobs, http.MethodDelete, "/?job_type=view&replayed=true", "", nil, 99)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
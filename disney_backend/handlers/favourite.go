@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"disney/database"
+	"disney/jobs"
 	"disney/models"
+	"disney/services"
 	"disney/workers"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -44,11 +47,25 @@ func AddFavourite(c *gin.Context) {
 	// Enqueue favourite add job to worker pool for async processing
 	// This returns immediately without blocking the HTTP request
 	// If the favourite already exists, the worker will handle it gracefully
-	FavouriteWorkerPoolInstance.EnqueueFavouriteJob(userID, req.CartoonID, "add")
+	job := jobs.NewFavouriteJob(userID, req.CartoonID, "add")
+	jobID, err := FavouriteWorkerPoolInstance.Enqueue(c.Request.Context(), job)
+	if errors.Is(err, workers.ErrPoolSaturated) {
+		// Favourites are user-visible, so write them now rather than ask the client to retry
+		result, err := FavouriteWorkerPoolInstance.Process(c.Request.Context(), job)
+		respondProcessedSync(c, jobID, result, err)
+		return
+	}
+	if err != nil {
+		respondEnqueueFailed(c, FavouriteWorkerPoolInstance.RetryAfter(), jobID, err)
+		return
+	}
 
 	// Return immediate response to client
+	// The job ID can be polled at /api/user/jobs/:id
 	c.JSON(http.StatusAccepted, gin.H{
 		"message":         "Favourite add request queued successfully",
+		"job_id":          jobID,
+		"status":          jobs.StatusQueued,
 		"user_id":         userID,
 		"cartoon_id":      req.CartoonID,
 		"queue_length":    FavouriteWorkerPoolInstance.GetQueueLength(),
@@ -61,13 +78,33 @@ func GetUserFavourites(c *gin.Context) {
 	userID := c.GetUint("userID")
 
 	var favourites []models.Favourite
-	if err := database.DB.Where("user_id = ?", userID).Preload("Cartoon").Find(&favourites).Error; err != nil {
+	if err := database.DB.Where("user_id = ?", userID).Find(&favourites).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to fetch favourites",
 		})
 		return
 	}
 
+	// Attach the cartoons, with genre and age group, loaded in one query
+	ids := make([]uint, len(favourites))
+	for i, favourite := range favourites {
+		ids[i] = favourite.CartoonID
+	}
+	cartoons, err := services.LoadCartoons(c.Request.Context(), ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch favourites",
+		})
+		return
+	}
+	byID := make(map[uint]models.Cartoon, len(cartoons))
+	for _, cartoon := range cartoons {
+		byID[cartoon.ID] = cartoon
+	}
+	for i := range favourites {
+		favourites[i].Cartoon = byID[favourites[i].CartoonID]
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Favourites retrieved successfully",
 		"data":    favourites,
@@ -94,11 +131,23 @@ func RemoveFavourite(c *gin.Context) {
 
 	// Extract numeric ID from favourite for queue
 	// Enqueue favourite remove job to worker pool for async processing
-	FavouriteWorkerPoolInstance.EnqueueFavouriteJob(userID, favourite.CartoonID, "remove")
+	job := jobs.NewFavouriteJob(userID, favourite.CartoonID, "remove")
+	jobID, err := FavouriteWorkerPoolInstance.Enqueue(c.Request.Context(), job)
+	if errors.Is(err, workers.ErrPoolSaturated) {
+		result, err := FavouriteWorkerPoolInstance.Process(c.Request.Context(), job)
+		respondProcessedSync(c, jobID, result, err)
+		return
+	}
+	if err != nil {
+		respondEnqueueFailed(c, FavouriteWorkerPoolInstance.RetryAfter(), jobID, err)
+		return
+	}
 
 	// Return immediate response to client
 	c.JSON(http.StatusAccepted, gin.H{
 		"message":         "Favourite remove request queued successfully",
+		"job_id":          jobID,
+		"status":          jobs.StatusQueued,
 		"user_id":         userID,
 		"cartoon_id":      favourite.CartoonID,
 		"queue_length":    FavouriteWorkerPoolInstance.GetQueueLength(),
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"disney/config"
+	"disney/database"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Health status values
+const (
+	healthOK       = "ok"
+	healthDegraded = "degraded"
+	healthDown     = "down"
+)
+
+// saturationReporter is implemented by the worker pools
+type saturationReporter interface {
+	GetQueueLength() int
+	SaturationThreshold() int
+	Saturated() bool
+}
+
+// poolHealth describes a worker pool's queue for the health check
+type poolHealth struct {
+	QueueLength int  `json:"queue_length"`
+	Threshold   int  `json:"threshold"`
+	Saturated   bool `json:"saturated"`
+}
+
+// Health reports the status of the database, Redis and worker pools
+// The status is "degraded" (200) while Redis is unavailable or a worker pool is
+// saturated, and "down" (503) when the database is unreachable
+func Health(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+	defer cancel()
+
+	status := healthOK
+	checks := gin.H{}
+
+	checks["database"] = healthOK
+	if sqlDB, err := database.DB.DB(); err != nil || sqlDB.PingContext(ctx) != nil {
+		checks["database"] = healthDown
+		status = healthDown
+	}
+
+	switch {
+	case config.RedisClient == nil:
+		checks["redis"] = "disabled"
+	case config.RedisClient.Ping(ctx).Err() != nil:
+		checks["redis"] = healthDown
+		if status == healthOK {
+			status = healthDegraded
+		}
+	default:
+		checks["redis"] = healthOK
+	}
+
+	pools := gin.H{}
+	for name, pool := range map[string]saturationReporter{
+		"views":      ViewWorkerPoolInstance,
+		"favourites": FavouriteWorkerPoolInstance,
+		"watchlists": WatchlistWorkerPoolInstance,
+	} {
+		saturated := pool.Saturated()
+		pools[name] = poolHealth{
+			QueueLength: pool.GetQueueLength(),
+			Threshold:   pool.SaturationThreshold(),
+			Saturated:   saturated,
+		}
+		if saturated && status == healthOK {
+			status = healthDegraded
+		}
+	}
+	checks["worker_pools"] = pools
+
+	code := http.StatusOK
+	if status == healthDown {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{
+		"status": status,
+		"checks": checks,
+	})
+}
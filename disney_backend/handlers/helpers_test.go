@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"disney/database"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// mockDB points database.DB at a sqlmock connection for the test
+// The returned counter holds the number of queries GORM ran
+func mockDB(t *testing.T) (sqlmock.Sqlmock, *int) {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+
+	queries := 0
+	count := func(*gorm.DB) { queries++ }
+	db.Callback().Query().After("gorm:query").Register("test:count_queries", count)
+	db.Callback().Row().After("gorm:row").Register("test:count_rows", count)
+
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = previous
+		sqlDB.Close()
+	})
+	return mock, &queries
+}
+
+// serve runs a handler as the given user, with an optional JSON body
+func serve(t *testing.T, handler gin.HandlerFunc, method, target, body string, params gin.Params, userID uint) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		c.Request.Header.Set("Content-Type", "application/json")
+	}
+	c.Params = params
+	c.Set("userID", userID)
+	handler(c)
+	return w
+}
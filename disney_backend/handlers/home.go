@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"context"
+	"disney/database"
+	"disney/models"
+	"disney/services"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// homeRowTimeout bounds how long a single home feed row may take to build
+	homeRowTimeout = 2 * time.Second
+	// homeRowSize is the maximum number of cartoons in a home feed row
+	homeRowSize = 10
+	// maxCollectionRows caps how many editorial collections appear on the home feed
+	maxCollectionRows = 5
+)
+
+// Home feed row statuses
+const (
+	rowStatusOK      = "ok"
+	rowStatusTimeout = "timeout"
+	rowStatusError   = "error"
+)
+
+// HomeFeedItem represents a cartoon shown in a home feed row
+type HomeFeedItem struct {
+	ID          uint             `json:"id"`
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	PosterURL   string           `json:"poster_url"`
+	ReleaseYear int              `json:"release_year"`
+	IMDbRating  string           `json:"imdb_rating,omitempty"`
+	Genre       *models.Genre    `json:"genre,omitempty"`
+	AgeGroup    *models.AgeGroup `json:"age_group,omitempty"`
+}
+
+// HomeFeedRow represents one titled row of the home feed
+// Rows that time out or fail are returned with an empty item list so the
+// client can fall back to the dedicated endpoint for that row
+type HomeFeedRow struct {
+	Key    string         `json:"key"`
+	Title  string         `json:"title"`
+	Status string         `json:"status"`
+	Items  []HomeFeedItem `json:"items"`
+}
+
+// homeRowBuilder builds the items of a row; it may override the row title
+type homeRowBuilder func(ctx context.Context, userID uint) (title string, items []HomeFeedItem, err error)
+
+// homeRowSpec describes a home feed row in display order
+type homeRowSpec struct {
+	key   string
+	title string
+	build homeRowBuilder
+}
+
+// homeRows lists the home feed rows in the order they are displayed
+var homeRows = []homeRowSpec{
+	{key: "continue_watching", title: "Continue watching", build: buildContinueWatchingRow},
+	{key: "recently_viewed", title: "Recently viewed", build: buildRecentlyViewedRow},
+	{key: "featured", title: "Featured", build: buildFeaturedRow},
+	{key: "trending", title: "Trending", build: buildTrendingRow},
+	{key: "because_you_favourited", title: "Because you favourited", build: buildBecauseYouFavouritedRow},
+	{key: "new_this_month", title: "New this month", build: buildNewThisMonthRow},
+}
+
+// GetHomeFeed returns the personalised home page rows in a single response
+// Rows are built concurrently, each with its own timeout, so one slow source
+// (such as an OMDb lookup) cannot stall the whole page
+func GetHomeFeed(c *gin.Context) {
+	userID := c.GetUint("userID")
+	ctx := c.Request.Context()
+
+	specs := homeRowSpecs(ctx)
+	rows := make([]HomeFeedRow, len(specs))
+	var wg sync.WaitGroup
+
+	for i, spec := range specs {
+		wg.Add(1)
+		go func(i int, spec homeRowSpec) {
+			defer wg.Done()
+			rows[i] = runHomeRow(ctx, userID, spec)
+		}(i, spec)
+	}
+	wg.Wait()
+
+	// Drop rows that have nothing to show; keep failed rows so the client can retry them
+	feed := make([]HomeFeedRow, 0, len(rows))
+	for _, row := range rows {
+		if row.Status == rowStatusOK && len(row.Items) == 0 {
+			continue
+		}
+		feed = append(feed, row)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Home feed fetched successfully",
+		"data":    feed,
+		"count":   len(feed),
+	})
+}
+
+// homeRowSpecs returns the rows to build, with public editorial collections
+// inserted after the featured row
+func homeRowSpecs(ctx context.Context) []homeRowSpec {
+	var collections []models.Watchlist
+	if err := database.DB.WithContext(ctx).Select("id", "name").
+		Where("is_editorial = ? AND visibility = ?", true, models.WatchlistPublic).
+		Order("updated_at DESC").
+		Limit(maxCollectionRows).
+		Find(&collections).Error; err != nil {
+		log.Printf("WARNING: Failed to load editorial collections for home feed: %v", err)
+		return homeRows
+	}
+
+	specs := make([]homeRowSpec, 0, len(homeRows)+len(collections))
+	for _, spec := range homeRows {
+		specs = append(specs, spec)
+		if spec.key != "featured" {
+			continue
+		}
+		for _, collection := range collections {
+			specs = append(specs, homeRowSpec{
+				key:   fmt.Sprintf("collection_%d", collection.ID),
+				title: collection.Name,
+				build: collectionRowBuilder(collection.ID),
+			})
+		}
+	}
+	return specs
+}
+
+// runHomeRow builds a single row, giving up after homeRowTimeout
+func runHomeRow(parent context.Context, userID uint, spec homeRowSpec) HomeFeedRow {
+	ctx, cancel := context.WithTimeout(parent, homeRowTimeout)
+	defer cancel()
+
+	type rowResult struct {
+		title string
+		items []HomeFeedItem
+		err   error
+	}
+
+	// Buffered so the builder can finish and exit even after we stopped waiting
+	done := make(chan rowResult, 1)
+	go func() {
+		title, items, err := spec.build(ctx, userID)
+		done <- rowResult{title: title, items: items, err: err}
+	}()
+
+	row := HomeFeedRow{Key: spec.key, Title: spec.title, Items: []HomeFeedItem{}}
+
+	select {
+	case result := <-done:
+		if result.err != nil {
+			log.Printf("WARNING: Home feed row %s failed for user %d: %v", spec.key, userID, result.err)
+			row.Status = rowStatusError
+			return row
+		}
+		if result.title != "" {
+			row.Title = result.title
+		}
+		if result.items != nil {
+			row.Items = result.items
+		}
+		row.Status = rowStatusOK
+	case <-ctx.Done():
+		log.Printf("WARNING: Home feed row %s timed out for user %d", spec.key, userID)
+		row.Status = rowStatusTimeout
+	}
+
+	return row
+}
+
+// cartoonToHomeItem converts a cartoon with loaded relations into a feed item
+func cartoonToHomeItem(cartoon models.Cartoon) HomeFeedItem {
+	return HomeFeedItem{
+		ID:          cartoon.ID,
+		Title:       cartoon.Title,
+		Description: cartoon.Description,
+		PosterURL:   cartoon.PosterURL,
+		ReleaseYear: cartoon.ReleaseYear,
+		Genre:       &cartoon.Genre,
+		AgeGroup:    &cartoon.AgeGroup,
+	}
+}
+
+// homeItemsByIDs loads cartoons by ID and returns them as feed items in the given order
+func homeItemsByIDs(ctx context.Context, ids []uint) ([]HomeFeedItem, error) {
+	cartoons, err := services.LoadCartoons(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]HomeFeedItem, 0, len(cartoons))
+	for _, cartoon := range cartoons {
+		items = append(items, cartoonToHomeItem(cartoon))
+	}
+	return items, nil
+}
+
+// buildContinueWatchingRow lists cartoons the user keeps coming back to:
+// viewed more than once in the last 30 days, most recently viewed first
+func buildContinueWatchingRow(ctx context.Context, userID uint) (string, []HomeFeedItem, error) {
+	var ids []uint
+	err := database.DB.WithContext(ctx).Model(&models.View{}).
+		Where("user_id = ? AND viewed_at >= ?", userID, time.Now().AddDate(0, 0, -30)).
+		Group("cartoon_id").
+		Having("COUNT(*) > 1").
+		Order("MAX(viewed_at) DESC").
+		Limit(homeRowSize).
+		Pluck("cartoon_id", &ids).Error
+	if err != nil {
+		return "", nil, err
+	}
+
+	items, err := homeItemsByIDs(ctx, ids)
+	return "", items, err
+}
+
+// buildRecentlyViewedRow lists the user's recently viewed history
+func buildRecentlyViewedRow(ctx context.Context, userID uint) (string, []HomeFeedItem, error) {
+	cartoonIDs, err := services.GetRecentlyViewed(int(userID))
+	if err != nil {
+		return "", nil, err
+	}
+
+	ids := make([]uint, len(cartoonIDs))
+	for i, id := range cartoonIDs {
+		ids[i] = uint(id)
+	}
+
+	items, err := homeItemsByIDs(ctx, ids)
+	return "", items, err
+}
+
+// buildFeaturedRow lists cartoons flagged as featured by admins
+func buildFeaturedRow(ctx context.Context, userID uint) (string, []HomeFeedItem, error) {
+	var cartoons []models.Cartoon
+	if err := database.DB.WithContext(ctx).Preload("Genre").Preload("AgeGroup").
+		Where("is_featured = ?", true).
+		Order("updated_at DESC").
+		Limit(homeRowSize).
+		Find(&cartoons).Error; err != nil {
+		return "", nil, err
+	}
+
+	items := make([]HomeFeedItem, 0, len(cartoons))
+	for _, cartoon := range cartoons {
+		items = append(items, cartoonToHomeItem(cartoon))
+	}
+	return "", items, nil
+}
+
+// collectionRowBuilder returns a builder listing an editorial collection's cartoons in order
+func collectionRowBuilder(watchlistID uint) homeRowBuilder {
+	return func(ctx context.Context, userID uint) (string, []HomeFeedItem, error) {
+		var ids []uint
+		if err := database.DB.WithContext(ctx).Model(&models.WatchlistItem{}).
+			Where("watchlist_id = ?", watchlistID).
+			Order("position ASC").Order("id ASC").
+			Limit(homeRowSize).
+			Pluck("cartoon_id", &ids).Error; err != nil {
+			return "", nil, err
+		}
+
+		items, err := homeItemsByIDs(ctx, ids)
+		return "", items, err
+	}
+}
+
+// buildTrendingRow lists trending cartoons
+func buildTrendingRow(ctx context.Context, userID uint) (string, []HomeFeedItem, error) {
+	trending, err := loadTrendingCartoons(ctx, services.DefaultTrendingWindow, homeRowSize)
+	if err != nil {
+		return "", nil, err
+	}
+
+	items := make([]HomeFeedItem, 0, len(trending))
+	for _, cartoon := range trending {
+		items = append(items, HomeFeedItem{
+			ID:          cartoon.ID,
+			Title:       cartoon.Title,
+			Description: cartoon.Description,
+			PosterURL:   cartoon.PosterURL,
+			ReleaseYear: cartoon.ReleaseYear,
+			IMDbRating:  cartoon.IMDbRating,
+			Genre:       cartoon.Genre,
+			AgeGroup:    cartoon.AgeGroup,
+		})
+	}
+	return "", items, nil
+}
+
+// buildBecauseYouFavouritedRow lists cartoons related to the user's latest favourite
+func buildBecauseYouFavouritedRow(ctx context.Context, userID uint) (string, []HomeFeedItem, error) {
+	var favourite models.Favourite
+	err := database.DB.WithContext(ctx).Preload("Cartoon").
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		First(&favourite).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	related, err := services.GetRelatedCartoons(ctx, favourite.CartoonID, homeRowSize)
+	if err != nil {
+		return "", nil, err
+	}
+
+	ids := make([]uint, len(related))
+	for i, item := range related {
+		ids[i] = item.CartoonID
+	}
+
+	items, err := homeItemsByIDs(ctx, ids)
+	return "Because you favourited " + favourite.Cartoon.Title, items, err
+}
+
+// buildNewThisMonthRow lists cartoons added since the start of the current month
+func buildNewThisMonthRow(ctx context.Context, userID uint) (string, []HomeFeedItem, error) {
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	var cartoons []models.Cartoon
+	if err := database.DB.WithContext(ctx).Preload("Genre").Preload("AgeGroup").
+		Where("created_at >= ?", monthStart).
+		Order("created_at DESC").
+		Limit(homeRowSize).
+		Find(&cartoons).Error; err != nil {
+		return "", nil, err
+	}
+
+	items := make([]HomeFeedItem, 0, len(cartoons))
+	for _, cartoon := range cartoons {
+		items = append(items, cartoonToHomeItem(cartoon))
+	}
+	return "", items, nil
+}
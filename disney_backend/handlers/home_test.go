@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestRunHomeRow(t *testing.T) {
+	items := []HomeFeedItem{{ID: 1}, {ID: 2}}
+	tests := []struct {
+		name       string
+		build      homeRowBuilder
+		wantStatus string
+		wantTitle  string
+		wantItems  int
+	}{
+		{
+			name: "ok",
+			build: func(context.Context, uint) (string, []HomeFeedItem, error) {
+				return "", items, nil
+			},
+			wantStatus: rowStatusOK, wantTitle: "Row", wantItems: 2,
+		},
+		{
+			name: "title override",
+			build: func(context.Context, uint) (string, []HomeFeedItem, error) {
+				return "Because you favourited Bambi", items, nil
+			},
+			wantStatus: rowStatusOK, wantTitle: "Because you favourited Bambi", wantItems: 2,
+		},
+		{
+			name: "no items",
+			build: func(context.Context, uint) (string, []HomeFeedItem, error) {
+				return "", nil, nil
+			},
+			wantStatus: rowStatusOK, wantTitle: "Row",
+		},
+		{
+			name: "error",
+			build: func(context.Context, uint) (string, []HomeFeedItem, error) {
+				return "Ignored", items, errors.New("query failed")
+			},
+			wantStatus: rowStatusError, wantTitle: "Row",
+		},
+	}
+	for _, tt := range tests {
+		row := runHomeRow(context.Background(), 1, homeRowSpec{key: "row", title: "Row", build: tt.build})
+		if row.Status != tt.wantStatus || row.Title != tt.wantTitle || len(row.Items) != tt.wantItems {
+			t.Errorf("%s: got %+v, want status %s, title %q and %d items", tt.name, row, tt.wantStatus, tt.wantTitle, tt.wantItems)
+		}
+		if row.Items == nil {
+			t.Errorf("%s: items are nil, want an empty list", tt.name)
+		}
+	}
+}
+
+func TestRunHomeRowTimesOut(t *testing.T) {
+	// The builder ignores cancellation; the row must not wait for it
+	release := make(chan struct{})
+	defer close(release)
+	slow := func(context.Context, uint) (string, []HomeFeedItem, error) {
+		<-release
+		return "", []HomeFeedItem{{ID: 1}}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	row := runHomeRow(ctx, 1, homeRowSpec{key: "slow", title: "Slow", build: slow})
+	if row.Status != rowStatusTimeout || len(row.Items) != 0 {
+		t.Errorf("got %+v, want an empty timed out row", row)
+	}
+	if elapsed := time.Since(start); elapsed > homeRowTimeout/2 {
+		t.Errorf("row took %s, want it to give up at the deadline", elapsed)
+	}
+}
+
+func TestHomeRowSpecsInsertsCollectionsAfterFeatured(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(`FROM "watchlists" WHERE is_editorial = \$1 AND visibility = \$2`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(4, "Summer picks").AddRow(9, "Classics"))
+
+	var keys []string
+	for _, spec := range homeRowSpecs(context.Background()) {
+		keys = append(keys, spec.key)
+	}
+	want := []string{
+		"continue_watching", "recently_viewed", "featured", "collection_4", "collection_9",
+		"trending", "because_you_favourited", "new_this_month",
+	}
+	if len(keys) != len(want) {
+		t.Fatalf("rows %v, want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("rows %v, want %v", keys, want)
+		}
+	}
+}
+
+func TestHomeRowSpecsWithoutCollections(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(`FROM "watchlists"`).WillReturnError(errors.New("connection refused"))
+
+	if specs := homeRowSpecs(context.Background()); len(specs) != len(homeRows) {
+		t.Errorf("got %d rows, want the %d built-in rows", len(specs), len(homeRows))
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"disney/jobs"
+	"disney/workers"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobTrackerInstance is the global job tracker shared by the worker pools
+// Initialized in main.go and used by handlers
+var JobTrackerInstance *workers.JobTracker
+
+// GetJobStatus returns the status of one of the logged-in user's async jobs
+// Jobs of other users are reported as not found
+func GetJobStatus(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	status, ok := JobTrackerInstance.Get(c.Param("id"))
+	if !ok || status.UserID != userID {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Job not found or expired",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Job status retrieved successfully",
+		"data":    status,
+	})
+}
+
+// workerPoolStats describes one worker pool for the admin stats endpoint
+type workerPoolStats struct {
+	Name        string              `json:"name"`
+	Concurrency int                 `json:"concurrency"`
+	QueueLength int                 `json:"queue_length"`
+	Batch       *workers.BatchStats `json:"batch,omitempty"`
+}
+
+// GetWorkerStats returns queue lengths and view batch counters of the worker pools (Admin only)
+func GetWorkerStats(c *gin.Context) {
+	viewBatch := ViewWorkerPoolInstance.BatchStats()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Worker stats retrieved successfully",
+		"data": []workerPoolStats{
+			{
+				Name:        ViewWorkerPoolInstance.Name(),
+				Concurrency: ViewWorkerPoolInstance.Concurrency(),
+				QueueLength: ViewWorkerPoolInstance.GetQueueLength(),
+				Batch:       &viewBatch,
+			},
+			{
+				Name:        FavouriteWorkerPoolInstance.Name(),
+				Concurrency: FavouriteWorkerPoolInstance.Concurrency(),
+				QueueLength: FavouriteWorkerPoolInstance.GetQueueLength(),
+			},
+			{
+				Name:        WatchlistWorkerPoolInstance.Name(),
+				Concurrency: WatchlistWorkerPoolInstance.Concurrency(),
+				QueueLength: WatchlistWorkerPoolInstance.GetQueueLength(),
+			},
+		},
+	})
+}
+
+// respondEnqueueFailed tells the client its job could not be queued and was dropped
+// Retry-After tells well-behaved clients when to try again
+func respondEnqueueFailed(c *gin.Context, retryAfter time.Duration, jobID string, err error) {
+	seconds := int(retryAfter.Round(time.Second) / time.Second)
+	c.Header("Retry-After", strconv.Itoa(max(seconds, 1)))
+	c.JSON(http.StatusServiceUnavailable, gin.H{
+		"error":   "Server is busy, please try again",
+		"details": err.Error(),
+		"job_id":  jobID,
+		"status":  jobs.StatusDropped,
+	})
+}
+
+// respondProcessedSync reports a job that was processed synchronously because its pool was saturated
+func respondProcessedSync(c *gin.Context, jobID string, result interface{}, err error) {
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to process request",
+			"details": err.Error(),
+			"job_id":  jobID,
+			"status":  jobs.StatusFailed,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Request processed successfully",
+		"job_id":          jobID,
+		"status":          jobs.StatusSucceeded,
+		"data":            result,
+		"processing_note": "Server is busy, so the request was processed synchronously",
+	})
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"disney/jobs"
+	"disney/workers"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetJobStatusOnlyShowsOwnJobs(t *testing.T) {
+	previous := JobTrackerInstance
+	JobTrackerInstance = workers.NewJobTracker(nil, time.Minute)
+	t.Cleanup(func() { JobTrackerInstance = previous })
+	JobTrackerInstance.Queued("job-1", jobs.TypeFavourite, 5)
+
+	params := gin.Params{{Key: "id", Value: "job-1"}}
+	if w := serve(t, GetJobStatus, http.MethodGet, "/", "", params, 5); w.Code != http.StatusOK {
+		t.Errorf("owner: status %d, want 200", w.Code)
+	}
+	if w := serve(t, GetJobStatus, http.MethodGet, "/", "", params, 6); w.Code != http.StatusNotFound {
+		t.Errorf("other user: status %d, want 404", w.Code)
+	}
+	missing := gin.Params{{Key: "id", Value: "job-2"}}
+	if w := serve(t, GetJobStatus, http.MethodGet, "/", "", missing, 5); w.Code != http.StatusNotFound {
+		t.Errorf("unknown job: status %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"disney/database"
+	"disney/models"
+	"disney/services"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetadataProviderInstance is the global metadata provider used for IMDb data
+// Initialized in main.go and used by handlers
+var MetadataProviderInstance services.MetadataProvider
+
+// PinIMDbIDRequest represents the request to pin a cartoon's IMDb ID
+type PinIMDbIDRequest struct {
+	// IMDbID such as "tt0092345"; an empty ID unpins the cartoon so its ID is looked up by title again
+	IMDbID string `json:"imdb_id"`
+}
+
+// GetCartoonMetadata returns external metadata for a cartoon: plot, runtime, rating, awards and IDs
+func GetCartoonMetadata(c *gin.Context) {
+	var cartoon models.Cartoon
+	if err := database.DB.Select("id", "title", "release_year", "imdb_id").First(&cartoon, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "Cartoon not found",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	query := services.MetadataQuery{Title: cartoon.Title, Year: cartoon.ReleaseYear}
+	if cartoon.IMDbID != "" {
+		query = services.MetadataQuery{IMDbID: cartoon.IMDbID}
+	}
+	metadata, err := MetadataProviderInstance.Lookup(c.Request.Context(), query)
+	switch {
+	case errors.Is(err, services.ErrMetadataNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "No metadata found for this cartoon",
+			"error":   err.Error(),
+		})
+		return
+	case errors.Is(err, services.ErrMetadataUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"message": "Metadata provider is unavailable",
+			"error":   err.Error(),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadGateway, gin.H{
+			"message": "Failed to fetch cartoon metadata",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cartoon metadata fetched successfully",
+		"data":    metadata,
+	})
+}
+
+// PinCartoonIMDbID sets the exact IMDb ID of a cartoon whose title is ambiguous (Admin only)
+// The rating is fetched right away; if that fails the background refresh retries it
+func PinCartoonIMDbID(c *gin.Context) {
+	var req PinIMDbIDRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid request",
+			"error":   err.Error(),
+		})
+		return
+	}
+	req.IMDbID = strings.TrimSpace(req.IMDbID)
+	if req.IMDbID != "" && !services.ValidIMDbID(req.IMDbID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid IMDb ID",
+			"error":   "IMDb IDs look like tt0092345",
+		})
+		return
+	}
+
+	var cartoon models.Cartoon
+	if err := database.DB.First(&cartoon, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "Cartoon not found",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// The stored rating may belong to the wrong show, so it is cleared until refetched
+	updates := map[string]interface{}{
+		"imdb_id":                req.IMDbID,
+		"imdb_id_pinned":         req.IMDbID != "",
+		"imdb_rating":            nil,
+		"imdb_rating_fetched_at": nil,
+	}
+	if err := database.DB.Model(&cartoon).UpdateColumns(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to update IMDb ID",
+			"error":   err.Error(),
+		})
+		return
+	}
+	cartoon.IMDbID = req.IMDbID
+	cartoon.IMDbIDPinned = req.IMDbID != ""
+	cartoon.IMDbRating = nil
+	cartoon.IMDbRatingFetchedAt = nil
+
+	refreshError := ""
+	if err := services.RefreshCartoonIMDb(c.Request.Context(), MetadataProviderInstance, &cartoon); err != nil {
+		refreshError = err.Error()
+	}
+	// Cached details, lists and names show the IMDb rating
+	services.InvalidateResponses(services.CartoonTag(cartoon.ID), services.TagCartoonNames)
+
+	action := "Pinned IMDb ID " + req.IMDbID
+	if req.IMDbID == "" {
+		action = "Unpinned IMDb ID"
+	}
+	if adminID, exists := c.Get("userID"); exists {
+		database.DB.Create(&models.AdminLog{
+			AdminID: adminID.(uint),
+			Action:  "UPDATE",
+			Entity:  "Cartoon: " + cartoon.Title + " (" + action + ")",
+		})
+	}
+
+	response := gin.H{
+		"message": "IMDb ID updated successfully",
+		"data": gin.H{
+			"id":                     cartoon.ID,
+			"title":                  cartoon.Title,
+			"imdb_id":                cartoon.IMDbID,
+			"imdb_id_pinned":         cartoon.IMDbIDPinned,
+			"imdb_rating":            services.FormatIMDbRating(cartoon.IMDbRating),
+			"imdb_rating_fetched_at": cartoon.IMDbRatingFetchedAt,
+		},
+	}
+	if refreshError != "" {
+		response["warning"] = "Rating could not be fetched yet, it will be retried in the background: " + refreshError
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetMetadataProviderStatus returns the metadata provider's circuit breaker, quota and call counters (Admin only)
+func GetMetadataProviderStatus(c *gin.Context) {
+	reporter, ok := MetadataProviderInstance.(services.MetadataStatusReporter)
+	if !ok {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Metadata provider has no resilience state",
+			"data": gin.H{
+				"provider": MetadataProviderInstance.Name(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Metadata provider status fetched successfully",
+		"data":    reporter.Status(c.Request.Context()),
+	})
+}
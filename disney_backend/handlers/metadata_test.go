@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"disney/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+// stubMetadataProvider returns a fixed lookup result and records the query
+type stubMetadataProvider struct {
+	metadata *services.Metadata
+	err      error
+	query    services.MetadataQuery
+}
+
+func (p *stubMetadataProvider) Name() string { return "stub" }
+
+func (p *stubMetadataProvider) Lookup(ctx context.Context, query services.MetadataQuery) (*services.Metadata, error) {
+	p.query = query
+	return p.metadata, p.err
+}
+
+// setMetadataProvider replaces MetadataProviderInstance for the test
+func setMetadataProvider(t *testing.T, provider services.MetadataProvider) {
+	t.Helper()
+	previous := MetadataProviderInstance
+	MetadataProviderInstance = provider
+	t.Cleanup(func() { MetadataProviderInstance = previous })
+}
+
+func TestGetCartoonMetadataMapsProviderErrors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, http.StatusOK},
+		{services.ErrMetadataNotFound, http.StatusNotFound},
+		{services.ErrMetadataUnavailable, http.StatusServiceUnavailable},
+		{errors.New("connection reset"), http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		provider := &stubMetadataProvider{metadata: &services.Metadata{Title: "DuckTales"}, err: tt.err}
+		setMetadataProvider(t, provider)
+		mock, _ := mockDB(t)
+		mock.ExpectQuery(`SELECT "id","title","release_year","imdb_id" FROM "cartoons"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "release_year", "imdb_id"}).AddRow(3, "DuckTales", 1987, ""))
+
+		w := serve(t, GetCartoonMetadata, http.MethodGet, "/", "", gin.Params{{Key: "id", Value: "3"}}, 0)
+		if w.Code != tt.want {
+			t.Errorf("provider error %v: status %d, want %d", tt.err, w.Code, tt.want)
+		}
+		if provider.query != (services.MetadataQuery{Title: "DuckTales", Year: 1987}) {
+			t.Errorf("looked up %+v, want title and year", provider.query)
+		}
+	}
+}
+
+func TestPinCartoonIMDbIDRejectsInvalidIDs(t *testing.T) {
+	mock, _ := mockDB(t)
+	for _, body := range []string{`{"imdb_id": "0092345"}`, `{"imdb_id": "tt12"}`, `not json`} {
+		w := serve(t, PinCartoonIMDbID, http.MethodPut, "/", body, gin.Params{{Key: "id", Value: "3"}}, 99)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", body, w.Code)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPinCartoonIMDbIDClearsRatingAndRefetches(t *testing.T) {
+	setMetadataProvider(t, &stubMetadataProvider{metadata: &services.Metadata{IMDbRating: "7.9"}})
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(`SELECT \* FROM "cartoons" WHERE "cartoons"."id" = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "imdb_id", "imdb_rating"}).AddRow(3, "DuckTales", "tt0000001", 5.2))
+	mock.ExpectExec(`UPDATE "cartoons" SET "imdb_id"=\$1,"imdb_id_pinned"=\$2,"imdb_rating"=\$3,"imdb_rating_fetched_at"=\$4 WHERE "id" = \$5`).
+		WithArgs("tt0092345", true, nil, nil, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "cartoons" SET "imdb_rating"=\$1,"imdb_rating_fetched_at"=\$2 WHERE "id" = \$3`).
+		WithArgs(7.9, sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "admin_logs"`).
+		WithArgs(99, "UPDATE", "Cartoon: DuckTales (Pinned IMDb ID tt0092345)", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	w := serve(t, PinCartoonIMDbID, http.MethodPut, "/", `{"imdb_id": " tt0092345 "}`, gin.Params{{Key: "id", Value: "3"}}, 99)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var body struct {
+		Data struct {
+			IMDbID     string `json:"imdb_id"`
+			Pinned     bool   `json:"imdb_id_pinned"`
+			IMDbRating string `json:"imdb_rating"`
+		} `json:"data"`
+		Warning string `json:"warning"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if body.Data.IMDbID != "tt0092345" || !body.Data.Pinned || body.Data.IMDbRating != "7.9" || body.Warning != "" {
+		t.Errorf("response %s", w.Body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
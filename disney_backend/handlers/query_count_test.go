@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"disney/cache"
+	"disney/config"
+	"disney/services"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+// The list endpoints load their cartoons with services.LoadCartoons, so the
+// number of queries they run must not grow with the number of cartoons listed
+
+const (
+	loadCartoonsQuery = `SELECT .* FROM "cartoons" LEFT JOIN "genres" "Genre" .* LEFT JOIN "age_groups" "AgeGroup" .* WHERE cartoons.id IN`
+	catalogQuery      = `SELECT "id","genre_id","age_group_id","release_year","is_featured" FROM "cartoons"$`
+)
+
+// cartoonIDs returns the IDs 1..n
+func cartoonIDs(n int) []uint {
+	ids := make([]uint, n)
+	for i := range ids {
+		ids[i] = uint(i + 1)
+	}
+	return ids
+}
+
+// cartoonRows returns LoadCartoons rows: cartoons with their genre and age group joined in
+func cartoonRows(ids []uint) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"id", "title", "genre_id", "age_group_id",
+		"Genre__id", "Genre__name", "AgeGroup__id", "AgeGroup__label",
+	})
+	for _, id := range ids {
+		rows.AddRow(id, fmt.Sprintf("Cartoon %d", id), 1, 2, 1, "Comedy", 2, "7+")
+	}
+	return rows
+}
+
+// expectCatalog expects the queries loading the recommendation catalogue of n cartoons
+func expectCatalog(mock sqlmock.Sqlmock, n int) {
+	catalog := sqlmock.NewRows([]string{"id", "genre_id", "age_group_id", "release_year", "is_featured"})
+	for _, id := range cartoonIDs(n) {
+		catalog.AddRow(id, 1, 2, 2000, false)
+	}
+	mock.ExpectQuery(catalogQuery).WillReturnRows(catalog)
+	mock.ExpectQuery(`FROM "age_groups"`).WillReturnRows(sqlmock.NewRows([]string{"id", "label"}).AddRow(2, "7+"))
+	mock.ExpectQuery(`FROM "characters"`).WillReturnRows(sqlmock.NewRows([]string{"cartoon_id", "name"}))
+}
+
+// assertConstantQueries runs an endpoint over a short and a long list of cartoons
+// and checks both ran want queries
+func assertConstantQueries(t *testing.T, want int, run func(t *testing.T, n int) int) {
+	t.Helper()
+	for _, n := range []int{2, 40} {
+		if got := run(t, n); got != want {
+			t.Errorf("with %d cartoons: ran %d queries, want %d", n, got, want)
+		}
+	}
+}
+
+func TestGetRecentlyViewedQueryCount(t *testing.T) {
+	assertConstantQueries(t, 2, func(t *testing.T, n int) int {
+		mock, queries := mockDB(t)
+		history := cache.NewMemory(10)
+		ids := make([]int, n)
+		for i := range ids {
+			ids[i] = i + 1
+		}
+		key := fmt.Sprintf("%s%d", services.RedisKeyPrefix, 1)
+		if err := cache.SetJSON(context.Background(), history, key, ids, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		recentlyViewed := config.Default().RecentlyViewed
+		recentlyViewed.Limit = n
+		services.SetRecentlyViewedCache(history, recentlyViewed)
+
+		mock.ExpectQuery(`SELECT "history_paused" FROM "users"`).
+			WillReturnRows(sqlmock.NewRows([]string{"history_paused"}).AddRow(false))
+		mock.ExpectQuery(loadCartoonsQuery).WillReturnRows(cartoonRows(cartoonIDs(n)))
+
+		if code := serve(t, GetRecentlyViewed, http.MethodGet, "/", "", nil, 1).Code; code != http.StatusOK {
+			t.Fatalf("status %d", code)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		return *queries
+	})
+}
+
+func TestGetUserFavouritesQueryCount(t *testing.T) {
+	assertConstantQueries(t, 2, func(t *testing.T, n int) int {
+		mock, queries := mockDB(t)
+		favourites := sqlmock.NewRows([]string{"id", "user_id", "cartoon_id"})
+		for _, id := range cartoonIDs(n) {
+			favourites.AddRow(id, 1, id)
+		}
+		mock.ExpectQuery(`FROM "favourites" WHERE user_id = \$1`).WillReturnRows(favourites)
+		mock.ExpectQuery(loadCartoonsQuery).WillReturnRows(cartoonRows(cartoonIDs(n)))
+
+		if code := serve(t, GetUserFavourites, http.MethodGet, "/", "", nil, 1).Code; code != http.StatusOK {
+			t.Fatalf("status %d", code)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		return *queries
+	})
+}
+
+func TestGetRecommendationsQueryCount(t *testing.T) {
+	// The user, the catalogue (cartoons, age groups, characters), the user's
+	// ratings, favourites and views, then the recommended cartoons
+	assertConstantQueries(t, 8, func(t *testing.T, n int) int {
+		mock, queries := mockDB(t)
+		mock.ExpectQuery(`FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"id", "age"}).AddRow(1, 30))
+		expectCatalog(mock, n)
+		mock.ExpectQuery(`FROM "ratings"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "cartoon_id", "rating"}))
+		mock.ExpectQuery(`FROM "favourites"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "cartoon_id"}))
+		mock.ExpectQuery(`FROM "views"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "cartoon_id", "views"}))
+		// With no history every cartoon is recommended as popular
+		mock.ExpectQuery(loadCartoonsQuery).WillReturnRows(cartoonRows(cartoonIDs(n)))
+
+		if code := serve(t, GetRecommendations, http.MethodGet, "/?limit=50", "", nil, 1).Code; code != http.StatusOK {
+			t.Fatalf("status %d", code)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		return *queries
+	})
+}
+
+func TestGetRelatedCartoonsQueryCount(t *testing.T) {
+	// The cartoon, the catalogue (cartoons, age groups, characters),
+	// co-engagement, then the related cartoons
+	assertConstantQueries(t, 6, func(t *testing.T, n int) int {
+		mock, queries := mockDB(t)
+		mock.ExpectQuery(`SELECT "id" FROM "cartoons"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		expectCatalog(mock, n+1)
+		mock.ExpectQuery(`WITH engaged AS`).WillReturnRows(sqlmock.NewRows([]string{"cartoon_id", "users"}))
+		// Every other cartoon shares the genre; the endpoint lists at most 20
+		mock.ExpectQuery(loadCartoonsQuery).WillReturnRows(cartoonRows(cartoonIDs(min(n, 20))))
+
+		params := gin.Params{{Key: "id", Value: "1"}}
+		if code := serve(t, GetRelatedCartoons, http.MethodGet, "/?limit=20", "", params, 1).Code; code != http.StatusOK {
+			t.Fatalf("status %d", code)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		return *queries
+	})
+}
@@ -3,23 +3,76 @@ package handlers
 import (
 	"disney/database"
 	"disney/models"
+	"disney/services"
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UpdateRatingRequest represents the request payload to update a rating
+// Review fields are optional; nil means leave the current value unchanged
 type UpdateRatingRequest struct {
-	Rating int `json:"rating" binding:"required,min=1,max=10"`
+	Rating      *int    `json:"rating" binding:"required"`
+	ReviewTitle *string `json:"review_title" binding:"omitempty,max=255"`
+	ReviewText  *string `json:"review_text" binding:"omitempty,max=5000"`
+	IsSpoiler   *bool   `json:"is_spoiler"`
 }
 
 // AddRatingRequest represents the request payload to add a rating
+// A written review can optionally be attached to the score
+// Posting again for the same cartoon replaces the existing rating
 type AddRatingRequest struct {
-	CartoonID uint `json:"cartoon_id" binding:"required"`
-	Rating    int  `json:"rating" binding:"required,min=1,max=10"`
+	CartoonID   uint   `json:"cartoon_id" binding:"required"`
+	Rating      *int   `json:"rating" binding:"required"`
+	ReviewTitle string `json:"review_title" binding:"max=255"`
+	ReviewText  string `json:"review_text" binding:"max=5000"`
+	IsSpoiler   bool   `json:"is_spoiler"`
 }
 
-// AddRating adds a rating for a cartoon (User only)
+// ratingResponse builds the JSON payload returned for a single rating
+func ratingResponse(rating models.Rating) gin.H {
+	return gin.H{
+		"id":            rating.ID,
+		"user_id":       rating.UserID,
+		"cartoon_id":    rating.CartoonID,
+		"rating":        rating.Rating,
+		"review_title":  rating.ReviewTitle,
+		"review_text":   rating.ReviewText,
+		"is_spoiler":    rating.IsSpoiler,
+		"review_status": rating.ReviewStatus,
+		"created_at":    rating.CreatedAt,
+		"updated_at":    rating.UpdatedAt,
+	}
+}
+
+// recordRatingHistory appends an entry to the rating audit trail
+func recordRatingHistory(tx *gorm.DB, rating models.Rating, action string, oldRating, newRating *int) error {
+	history := models.RatingHistory{
+		RatingID:  rating.ID,
+		UserID:    rating.UserID,
+		CartoonID: rating.CartoonID,
+		Action:    action,
+		OldRating: oldRating,
+		NewRating: newRating,
+	}
+	return tx.Create(&history).Error
+}
+
+// GetRatingScale returns the scale accepted by the rating endpoints
+func GetRatingScale(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Rating scale retrieved successfully",
+		"data":    services.GetRatingScale(),
+	})
+}
+
+// AddRating adds or replaces the user's rating for a cartoon (User only)
+// The unique (user_id, cartoon_id) index makes concurrent submissions safe:
+// only one first submission inserts, the others update its row
 func AddRating(c *gin.Context) {
 	userID := c.GetUint("userID")
 
@@ -31,10 +84,9 @@ func AddRating(c *gin.Context) {
 		return
 	}
 
-	// Validate rating is between 1-10
-	if req.Rating < 1 || req.Rating > 10 {
+	if err := services.GetRatingScale().Validate(*req.Rating); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Rating must be between 1 and 10",
+			"error": err.Error(),
 		})
 		return
 	}
@@ -48,38 +100,82 @@ func AddRating(c *gin.Context) {
 		return
 	}
 
-	// Check if user already rated this cartoon
-	var existingRating models.Rating
-	if result := database.DB.Where("user_id = ? AND cartoon_id = ?", userID, req.CartoonID).First(&existingRating); result.RowsAffected > 0 {
-		c.JSON(http.StatusConflict, gin.H{
-			"error": "You have already rated this cartoon",
-		})
-		return
+	rating := models.Rating{
+		UserID:       userID,
+		CartoonID:    req.CartoonID,
+		Rating:       *req.Rating,
+		ReviewTitle:  strings.TrimSpace(req.ReviewTitle),
+		ReviewText:   strings.TrimSpace(req.ReviewText),
+		IsSpoiler:    req.IsSpoiler,
+		ReviewStatus: models.ReviewStatusVisible,
 	}
+	created := false
 
-	// Create new rating entry
-	newRating := models.Rating{
-		UserID:    userID,
-		CartoonID: req.CartoonID,
-		Rating:    req.Rating,
-	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the insert itself tells whether the rating is new: a concurrent first
+		// submission makes it do nothing, after waiting for the other transaction
+		insert := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "cartoon_id"}},
+			DoNothing: true,
+		}).Create(&rating)
+		if insert.Error != nil {
+			return insert.Error
+		}
+		newRating := rating.Rating
+		if insert.RowsAffected > 0 {
+			created = true
+			return recordRatingHistory(tx, rating, models.RatingActionCreate, nil, &newRating)
+		}
 
-	if err := database.DB.Create(&newRating).Error; err != nil {
+		// The rating exists; lock it so the history entry sees the value being replaced
+		var existing models.Rating
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND cartoon_id = ?", userID, req.CartoonID).
+			First(&existing).Error; err != nil {
+			return err
+		}
+
+		// Updates writes the new values into existing, so keep the old score first
+		oldRating := existing.Rating
+		rating.ID = existing.ID
+		rating.CreatedAt = existing.CreatedAt
+		// Moderation state is only changed by moderators or edited text;
+		// an approval applies to the text that was moderated
+		rating.ReviewStatus = existing.ReviewStatus
+		if existing.ReviewStatus == models.ReviewStatusApproved && existing.ReviewText != rating.ReviewText {
+			rating.ReviewStatus = models.ReviewStatusVisible
+		}
+		if err := tx.Model(&existing).Updates(map[string]interface{}{
+			"rating":        rating.Rating,
+			"review_title":  rating.ReviewTitle,
+			"review_text":   rating.ReviewText,
+			"is_spoiler":    rating.IsSpoiler,
+			"review_status": rating.ReviewStatus,
+			"updated_at":    gorm.Expr("NOW()"),
+		}).Error; err != nil {
+			return err
+		}
+
+		return recordRatingHistory(tx, rating, models.RatingActionUpdate, &oldRating, &newRating)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to submit rating",
 		})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "Rating submitted successfully",
-		"data": gin.H{
-			"id":         newRating.ID,
-			"user_id":    newRating.UserID,
-			"cartoon_id": newRating.CartoonID,
-			"rating":     newRating.Rating,
-			"created_at": newRating.CreatedAt,
-		},
+	if created {
+		c.JSON(http.StatusCreated, gin.H{
+			"message": "Rating submitted successfully",
+			"data":    ratingResponse(rating),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Rating updated successfully",
+		"data":    ratingResponse(rating),
 	})
 }
 
@@ -96,25 +192,54 @@ func UpdateRating(c *gin.Context) {
 		return
 	}
 
-	// Validate rating is between 1-5
-	if req.Rating < 1 || req.Rating > 5 {
+	if err := services.GetRatingScale().Validate(*req.Rating); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Rating must be between 1 and 5",
+			"error": err.Error(),
 		})
 		return
 	}
 
-	// Find existing rating
 	var rating models.Rating
-	if result := database.DB.Where("user_id = ? AND cartoon_id = ?", userID, cartoonID).First(&rating); result.RowsAffected == 0 {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Find and lock the existing rating
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND cartoon_id = ?", userID, cartoonID).
+			First(&rating).Error; err != nil {
+			return err
+		}
+		oldRating := rating.Rating
+
+		// Update the rating and any review fields that were provided
+		updates := map[string]interface{}{"rating": *req.Rating}
+		if req.ReviewTitle != nil {
+			updates["review_title"] = strings.TrimSpace(*req.ReviewTitle)
+		}
+		if req.ReviewText != nil {
+			updates["review_text"] = strings.TrimSpace(*req.ReviewText)
+			// An approval applies to the text that was moderated, so edited
+			// reviews go back to the normal visible state
+			if rating.ReviewStatus == models.ReviewStatusApproved {
+				updates["review_status"] = models.ReviewStatusVisible
+			}
+		}
+		if req.IsSpoiler != nil {
+			updates["is_spoiler"] = *req.IsSpoiler
+		}
+
+		if err := tx.Model(&rating).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		newRating := rating.Rating
+		return recordRatingHistory(tx, rating, models.RatingActionUpdate, &oldRating, &newRating)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Rating not found for this cartoon",
 		})
 		return
 	}
-
-	// Update the rating
-	if err := database.DB.Model(&rating).Update("rating", req.Rating).Error; err != nil {
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to update rating",
 		})
@@ -123,16 +248,75 @@ func UpdateRating(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Rating updated successfully",
+		"data":    ratingResponse(rating),
+	})
+}
+
+// DeleteRating removes the user's rating (and written review) for a cartoon (User only)
+func DeleteRating(c *gin.Context) {
+	userID := c.GetUint("userID")
+	cartoonID := c.Param("cartoon_id")
+
+	var rating models.Rating
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND cartoon_id = ?", userID, cartoonID).
+			First(&rating).Error; err != nil {
+			return err
+		}
+
+		// Votes and reports on the review are removed by ON DELETE CASCADE
+		if err := tx.Delete(&rating).Error; err != nil {
+			return err
+		}
+
+		oldRating := rating.Rating
+		return recordRatingHistory(tx, rating, models.RatingActionDelete, &oldRating, nil)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Rating not found for this cartoon",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete rating",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Rating deleted successfully",
 		"data": gin.H{
 			"id":         rating.ID,
-			"user_id":    rating.UserID,
 			"cartoon_id": rating.CartoonID,
-			"rating":     rating.Rating,
-			"created_at": rating.CreatedAt,
 		},
 	})
 }
 
+// GetRatingHistory returns the user's edit history for their rating of a cartoon
+func GetRatingHistory(c *gin.Context) {
+	userID := c.GetUint("userID")
+	cartoonID := c.Param("cartoon_id")
+
+	history := []models.RatingHistory{}
+	if err := database.DB.Where("user_id = ? AND cartoon_id = ?", userID, cartoonID).
+		Order("created_at DESC").Order("id DESC").
+		Find(&history).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch rating history",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Rating history retrieved successfully",
+		"data":    history,
+		"count":   len(history),
+	})
+}
+
 // GetUserRating retrieves user's rating for a specific cartoon
 func GetUserRating(c *gin.Context) {
 	userID := c.GetUint("userID")
@@ -151,13 +335,6 @@ func GetUserRating(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Rating retrieved successfully",
-		"data": gin.H{
-			"id":         rating.ID,
-			"user_id":    rating.UserID,
-			"cartoon_id": rating.CartoonID,
-			"rating":     rating.Rating,
-			"created_at": rating.CreatedAt,
-		},
+		"data":    ratingResponse(rating),
 	})
 }
-
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"disney/services"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	findCartoonQuery  = `SELECT \* FROM "cartoons" WHERE "cartoons"."id" = \$1`
+	insertRatingQuery = `INSERT INTO "ratings" .* ON CONFLICT \("user_id","cartoon_id"\) DO NOTHING RETURNING "id"`
+	lockRatingQuery   = `SELECT \* FROM "ratings" WHERE user_id = \$1 AND cartoon_id = \$2 .* FOR UPDATE`
+	insertHistory     = `INSERT INTO "rating_histories"`
+)
+
+func TestAddRatingCreatesNewRating(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(findCartoonQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectBegin()
+	mock.ExpectQuery(insertRatingQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+	mock.ExpectQuery(insertHistory).
+		WithArgs(11, 5, 3, "create", nil, 8, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	w := serve(t, AddRating, http.MethodPost, "/", `{"cartoon_id": 3, "rating": 8}`, nil, 5)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status %d, want 201: %s", w.Code, w.Body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAddRatingUpdatesExistingRating(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(findCartoonQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectBegin()
+	// A rating already exists, or a concurrent first submission won the insert
+	mock.ExpectQuery(insertRatingQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(lockRatingQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "cartoon_id", "rating", "review_text", "review_status"}).
+			AddRow(11, 5, 3, 4, "Old text", "approved"))
+	// Edited text loses the approval
+	mock.ExpectExec(`UPDATE "ratings" SET .*"review_status"=\$\d`).
+		WithArgs(false, 8, "visible", "New text", "", 11).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(insertHistory).
+		WithArgs(11, 5, 3, "update", 4, 8, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectCommit()
+
+	w := serve(t, AddRating, http.MethodPost, "/", `{"cartoon_id": 3, "rating": 8, "review_text": " New text "}`, nil, 5)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", w.Code, w.Body)
+	}
+	if status := responseStatus(t, w); status != "visible" {
+		t.Errorf("review_status %q, want visible", status)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateRatingRecordsOldAndNewScore(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockRatingQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "cartoon_id", "rating", "review_status"}).
+			AddRow(11, 5, 3, 4, "approved"))
+	// Only the score changed, so the approval stands
+	mock.ExpectExec(`UPDATE "ratings" SET "rating"=\$1,"updated_at"=\$2 WHERE "id" = \$3`).
+		WithArgs(2, sqlmock.AnyArg(), 11).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(insertHistory).
+		WithArgs(11, 5, 3, "update", 4, 2, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectCommit()
+
+	params := gin.Params{{Key: "cartoon_id", Value: "3"}}
+	w := serve(t, UpdateRating, http.MethodPut, "/", `{"rating": 2}`, params, 5)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	if status := responseStatus(t, w); status != "approved" {
+		t.Errorf("review_status %q, want approved", status)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRatingEndpointsRejectScoresOutsideScale(t *testing.T) {
+	services.SetRatingScale(services.RatingScale{Min: 1, Max: 5})
+	t.Cleanup(func() { services.SetRatingScale(services.DefaultRatingScale) })
+	mock, queries := mockDB(t)
+
+	if w := serve(t, AddRating, http.MethodPost, "/", `{"cartoon_id": 3, "rating": 6}`, nil, 5); w.Code != http.StatusBadRequest {
+		t.Errorf("AddRating: status %d, want 400", w.Code)
+	}
+	params := gin.Params{{Key: "cartoon_id", Value: "3"}}
+	if w := serve(t, UpdateRating, http.MethodPut, "/", `{"rating": 0}`, params, 5); w.Code != http.StatusBadRequest {
+		t.Errorf("UpdateRating: status %d, want 400", w.Code)
+	}
+	if *queries != 0 {
+		t.Errorf("ran %d queries, want 0", *queries)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDeleteRatingRecordsHistory(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockRatingQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "cartoon_id", "rating"}).AddRow(11, 5, 3, 7))
+	mock.ExpectExec(`DELETE FROM "ratings" WHERE "ratings"."id" = \$1`).WithArgs(11).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(insertHistory).
+		WithArgs(11, 5, 3, "delete", 7, nil, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectCommit()
+
+	params := gin.Params{{Key: "cartoon_id", Value: "3"}}
+	if w := serve(t, DeleteRating, http.MethodDelete, "/", "", params, 5); w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDeleteRatingNotFound(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockRatingQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	params := gin.Params{{Key: "cartoon_id", Value: "3"}}
+	if w := serve(t, DeleteRating, http.MethodDelete, "/", "", params, 5); w.Code != http.StatusNotFound {
+		t.Errorf("status %d, want 404", w.Code)
+	}
+}
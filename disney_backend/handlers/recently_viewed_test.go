@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"disney/cache"
+	"disney/config"
+	"disney/jobs"
+	"disney/services"
+	"disney/workers"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+// useRecentlyViewedCache gives the recently viewed service an in-memory cache for the test
+func useRecentlyViewedCache(t *testing.T) {
+	t.Helper()
+	services.SetRecentlyViewedCache(cache.NewMemory(10), config.Default().RecentlyViewed)
+	t.Cleanup(func() { services.SetRecentlyViewedCache(nil, config.Default().RecentlyViewed) })
+}
+
+func TestRecordViewWhilePausedHidesViewFromHistory(t *testing.T) {
+	useRecentlyViewedCache(t)
+	queue := workers.NewMemoryQueue(10)
+	_, favourite, watchlist := idlePools(10, workers.PoolOptions{})
+	setPools(t, workers.NewViewWorkerPool(queue, workers.NewJobTracker(nil, time.Minute), workers.PoolOptions{}), favourite, watchlist)
+
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(`FROM "cartoons" WHERE "cartoons"."id" = \$1`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery(`SELECT "history_paused" FROM "users"`).WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"history_paused"}).AddRow(true))
+
+	w := serve(t, RecordView, http.MethodPost, "/", `{"cartoon_id": 3}`, nil, 5)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status %d, want 202: %s", w.Code, w.Body)
+	}
+
+	// The view still counts, but is kept out of the history
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	delivery, err := queue.Receive(ctx)
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	var job jobs.ViewJob
+	json.Unmarshal(delivery.Payload, &job)
+	if job.CartoonID != 3 || job.UserID != 5 || !job.HiddenFromHistory {
+		t.Errorf("queued job %+v, want it hidden from history", job)
+	}
+}
+
+func TestRemoveRecentlyViewedRejectsInvalidID(t *testing.T) {
+	mock, _ := mockDB(t)
+	w := serve(t, RemoveRecentlyViewed, http.MethodDelete, "/", "", gin.Params{{Key: "cartoon_id", Value: "abc"}}, 5)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want 400", w.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUpdateRecentlyViewedSettings(t *testing.T) {
+	mock, _ := mockDB(t)
+	if w := serve(t, UpdateRecentlyViewedSettings, http.MethodPut, "/", `{}`, nil, 5); w.Code != http.StatusBadRequest {
+		t.Errorf("missing paused: status %d, want 400", w.Code)
+	}
+
+	mock.ExpectExec(`UPDATE "users" SET "history_paused"=\$1,"updated_at"=\$2 WHERE id = \$3`).
+		WithArgs(false, sqlmock.AnyArg(), 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	w := serve(t, UpdateRecentlyViewedSettings, http.MethodPut, "/", `{"paused": false}`, nil, 5)
+	var body struct {
+		Data struct {
+			HistoryPaused *bool `json:"history_paused"`
+		} `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusOK || body.Data.HistoryPaused == nil || *body.Data.HistoryPaused {
+		t.Errorf("status %d: %s", w.Code, w.Body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"disney/models"
+	"disney/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecommendationResponse represents a recommended cartoon with the reason it was picked
+type RecommendationResponse struct {
+	ID          uint             `json:"id"`
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	PosterURL   string           `json:"poster_url"`
+	ReleaseYear int              `json:"release_year"`
+	Genre       *models.Genre    `json:"genre,omitempty"`
+	AgeGroup    *models.AgeGroup `json:"age_group,omitempty"`
+	Score       float64          `json:"score"`
+	Source      string           `json:"source"`
+	BecauseOf   uint             `json:"because_of,omitempty"`
+}
+
+// GetRecommendations returns personalised cartoon recommendations for the logged-in user
+// Already watched, rated or favourited titles and titles above the user's age are excluded
+func GetRecommendations(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	limit := 20
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 50 {
+			limit = parsed
+		}
+	}
+
+	recommendations, err := services.RecommendForUser(c.Request.Context(), userID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to build recommendations",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ids := make([]uint, len(recommendations))
+	for i, rec := range recommendations {
+		ids[i] = rec.CartoonID
+	}
+
+	// Loaded in the ranking order from the recommender
+	cartoons, err := services.LoadCartoons(c.Request.Context(), ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to fetch cartoons",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	recsByID := make(map[uint]services.Recommendation, len(recommendations))
+	for _, rec := range recommendations {
+		recsByID[rec.CartoonID] = rec
+	}
+
+	response := make([]RecommendationResponse, 0, len(cartoons))
+	for _, cartoon := range cartoons {
+		rec := recsByID[cartoon.ID]
+		response = append(response, RecommendationResponse{
+			ID:          cartoon.ID,
+			Title:       cartoon.Title,
+			Description: cartoon.Description,
+			PosterURL:   cartoon.PosterURL,
+			ReleaseYear: cartoon.ReleaseYear,
+			Genre:       &cartoon.Genre,
+			AgeGroup:    &cartoon.AgeGroup,
+			Score:       rec.Score,
+			Source:      rec.Source,
+			BecauseOf:   rec.BecauseOf,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Recommendations fetched successfully",
+		"data":       response,
+		"count":      len(response),
+		"trained_at": services.RecommendationsTrainedAt(),
+	})
+}
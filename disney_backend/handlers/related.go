@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"disney/database"
+	"disney/models"
+	"disney/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RelatedCartoonResponse represents a "more like this" cartoon with its similarity score
+type RelatedCartoonResponse struct {
+	ID          uint             `json:"id"`
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	PosterURL   string           `json:"poster_url"`
+	ReleaseYear int              `json:"release_year"`
+	Genre       *models.Genre    `json:"genre,omitempty"`
+	AgeGroup    *models.AgeGroup `json:"age_group,omitempty"`
+	Score       float64          `json:"score"`
+}
+
+// GetRelatedCartoons returns cartoons similar to the given cartoon
+func GetRelatedCartoons(c *gin.Context) {
+	var cartoon models.Cartoon
+	if err := database.DB.Select("id").First(&cartoon, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "Cartoon not found",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	limit := 10
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 20 {
+			limit = parsed
+		}
+	}
+
+	related, err := services.GetRelatedCartoons(c.Request.Context(), cartoon.ID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to fetch related cartoons",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ids := make([]uint, len(related))
+	for i, item := range related {
+		ids[i] = item.CartoonID
+	}
+
+	// Loaded in similarity order; cached entries for deleted cartoons are skipped
+	cartoons, err := services.LoadCartoons(c.Request.Context(), ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to fetch cartoons",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	scores := make(map[uint]float64, len(related))
+	for _, item := range related {
+		scores[item.CartoonID] = item.Score
+	}
+
+	response := make([]RelatedCartoonResponse, 0, len(cartoons))
+	for _, relatedCartoon := range cartoons {
+		response = append(response, RelatedCartoonResponse{
+			ID:          relatedCartoon.ID,
+			Title:       relatedCartoon.Title,
+			Description: relatedCartoon.Description,
+			PosterURL:   relatedCartoon.PosterURL,
+			ReleaseYear: relatedCartoon.ReleaseYear,
+			Genre:       &relatedCartoon.Genre,
+			AgeGroup:    &relatedCartoon.AgeGroup,
+			Score:       scores[relatedCartoon.ID],
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Related cartoons fetched successfully",
+		"data":    response,
+		"count":   len(response),
+	})
+}
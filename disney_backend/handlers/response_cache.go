@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"disney/services"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serveCachedResponse writes the cached response of key, reporting whether there was one
+// name groups the lookup in the hit rate stats
+func serveCachedResponse(c *gin.Context, name, key string) bool {
+	entry, ok := services.CachedResponse(c.Request.Context(), name, key)
+	if !ok {
+		return false
+	}
+	writeCachedResponse(c, entry)
+	return true
+}
+
+// writeCachedResponse writes a cached 200 JSON response
+func writeCachedResponse(c *gin.Context, entry services.ResponseEntry) {
+	setLastModified(c, entry.LastModified)
+	c.Header("X-Cache", "HIT")
+	c.Data(http.StatusOK, "application/json; charset=utf-8", entry.Body)
+}
+
+// respondAndCache writes a 200 JSON response and caches it under key and tags
+// lastModified is when the response's data last changed, or zero if unknown
+func respondAndCache(c *gin.Context, key string, tags []string, lastModified time.Time, response gin.H) {
+	body, err := json.Marshal(response)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to encode response",
+			"error":   err.Error(),
+		})
+		return
+	}
+	services.CacheResponse(c.Request.Context(), key, tags, services.ResponseEntry{Body: body, LastModified: lastModified})
+	setLastModified(c, lastModified)
+	c.Header("X-Cache", "MISS")
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// setLastModified sets the Last-Modified header, which the HTTP cache
+// middleware uses to answer If-Modified-Since
+func setLastModified(c *gin.Context, lastModified time.Time) {
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// GetResponseCacheStats returns the catalogue response cache's hit rates on this replica (Admin only)
+func GetResponseCacheStats(c *gin.Context) {
+	stats, ok := services.GetResponseCacheStats()
+	if !ok {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Response caching is disabled",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Response cache stats fetched successfully",
+		"data":    stats,
+	})
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"disney/config"
+	"disney/middleware"
+	"disney/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+// useResponseCache enables an in-memory response cache for the test
+func useResponseCache(t *testing.T) {
+	t.Helper()
+	services.SetResponseCache(services.NewResponseCache(nil, time.Minute, 100))
+	t.Cleanup(func() { services.SetResponseCache(nil) })
+}
+
+func TestGetAllCartoonNamesIsCachedUntilInvalidated(t *testing.T) {
+	useResponseCache(t)
+	mock, queries := mockDB(t)
+	namesRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "title", "release_year", "imdb_rating"}).AddRow(3, "DuckTales", 1987, 8.1)
+	}
+	mock.ExpectQuery(`SELECT "id","title","poster_url","release_year","imdb_rating" FROM "cartoons"`).WillReturnRows(namesRows())
+
+	first := serve(t, GetAllCartoonNames, http.MethodGet, "/", "", nil, 0)
+	second := serve(t, GetAllCartoonNames, http.MethodGet, "/", "", nil, 0)
+	if first.Header().Get("X-Cache") != "MISS" || second.Header().Get("X-Cache") != "HIT" || *queries != 1 {
+		t.Fatalf("X-Cache %q then %q after %d queries, want one query", first.Header().Get("X-Cache"), second.Header().Get("X-Cache"), *queries)
+	}
+	if first.Body.String() != second.Body.String() {
+		t.Errorf("cached body %s differs from %s", second.Body, first.Body)
+	}
+
+	services.InvalidateResponses(services.TagCartoonNames)
+	mock.ExpectQuery(`FROM "cartoons"`).WillReturnRows(namesRows())
+	if w := serve(t, GetAllCartoonNames, http.MethodGet, "/", "", nil, 0); w.Header().Get("X-Cache") != "MISS" || *queries != 2 {
+		t.Errorf("after invalidation: X-Cache %q with %d queries", w.Header().Get("X-Cache"), *queries)
+	}
+
+	stats := serve(t, GetResponseCacheStats, http.MethodGet, "/", "", nil, 99)
+	if stats.Code != http.StatusOK {
+		t.Errorf("stats status %d", stats.Code)
+	}
+}
+
+func TestCachedResponsesKeepLastModified(t *testing.T) {
+	useResponseCache(t)
+	updated := time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.CachePolicy(middleware.CachePrivate))
+	router.GET("/cartoons/3", func(c *gin.Context) {
+		if serveCachedResponse(c, "detail", "cartoon:3") {
+			return
+		}
+		respondAndCache(c, "cartoon:3", []string{services.CartoonTag(3)}, updated, gin.H{"data": "DuckTales"})
+	})
+	get := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/cartoons/3", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	miss, hit := get(nil), get(nil)
+	for _, w := range []*httptest.ResponseRecorder{miss, hit} {
+		if w.Header().Get("Last-Modified") != updated.Format(http.TimeFormat) {
+			t.Errorf("X-Cache %s: Last-Modified %q", w.Header().Get("X-Cache"), w.Header().Get("Last-Modified"))
+		}
+	}
+	if miss.Header().Get("ETag") != hit.Header().Get("ETag") {
+		t.Error("cached hit has a different ETag")
+	}
+
+	// A cached hit still answers revalidation with 304
+	w := get(map[string]string{"If-Modified-Since": updated.Format(http.TimeFormat)})
+	if w.Code != http.StatusNotModified || w.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("revalidation: status %d, X-Cache %q", w.Code, w.Header().Get("X-Cache"))
+	}
+}
+
+func TestCartoonDetailRevalidatesAfterIMDbRefresh(t *testing.T) {
+	useResponseCache(t)
+	mock, _ := mockDB(t)
+	expectDetail := func(rating float64) {
+		updated := time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(`SELECT \* FROM "cartoons" WHERE "cartoons"."id" = \$1`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "genre_id", "age_group_id", "imdb_id", "imdb_rating", "updated_at"}).
+				AddRow(3, "DuckTales", 1, 2, "tt0092345", rating, updated))
+		mock.ExpectQuery(`SELECT \* FROM "age_groups"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectQuery(`SELECT \* FROM "characters"`).WillReturnRows(sqlmock.NewRows([]string{"id", "cartoon_id"}))
+		mock.ExpectQuery(`SELECT \* FROM "genres"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.CachePolicy(middleware.CachePrivate))
+	router.GET("/cartoons/:id", GetCartoonByID)
+	get := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/cartoons/3", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	expectDetail(5.2)
+	first := get(nil)
+	if first.Code != http.StatusOK || !strings.Contains(first.Body.String(), `"imdb_rating":"5.2"`) {
+		t.Fatalf("first fetch: status %d, body %s", first.Code, first.Body)
+	}
+	if lastModified := first.Header().Get("Last-Modified"); lastModified != "" {
+		t.Errorf("detail sent Last-Modified %q", lastModified)
+	}
+
+	// The refresh leaves updated_at alone
+	mock.ExpectQuery(`SELECT "id","title","release_year","imdb_id" FROM "cartoons"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "release_year", "imdb_id"}).AddRow(3, "DuckTales", 1987, "tt0092345"))
+	mock.ExpectExec(`UPDATE "cartoons" SET "imdb_rating"=\$1,"imdb_rating_fetched_at"=\$2 WHERE "id" = \$3`).
+		WithArgs(7.9, sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	provider := &stubMetadataProvider{metadata: &services.Metadata{IMDbRating: "7.9"}}
+	cfg := config.MetadataConfig{RefreshBatchSize: 10, RefreshConcurrency: 1}
+	if err := services.RefreshIMDbRatings(context.Background(), provider, cfg); err != nil {
+		t.Fatalf("RefreshIMDbRatings: %v", err)
+	}
+
+	expectDetail(7.9)
+	w := get(map[string]string{"If-Modified-Since": time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"imdb_rating":"7.9"`) {
+		t.Errorf("revalidation after refresh: status %d, body %s", w.Code, w.Body)
+	}
+
+	// The ETag still answers unchanged revalidations
+	w = get(map[string]string{"If-None-Match": w.Header().Get("ETag")})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match: status %d", w.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCartoonDetailRecordsNothing(t *testing.T) {
+	useResponseCache(t)
+	mock, queries := mockDB(t)
+	mock.ExpectQuery(`SELECT \* FROM "cartoons" WHERE "cartoons"."id" = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(3, "DuckTales"))
+	mock.ExpectQuery(`SELECT \* FROM "characters"`).WillReturnRows(sqlmock.NewRows([]string{"id", "cartoon_id"}))
+
+	// Browsers serve repeat reads of a CachePrivate route from their cache,
+	// so reading a cartoon must not count as viewing it
+	for i := 0; i < 2; i++ {
+		if w := serve(t, GetCartoonByID, http.MethodGet, "/", "", gin.Params{{Key: "id", Value: "3"}}, 5); w.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", w.Code, w.Body)
+		}
+	}
+	if *queries != 2 {
+		t.Errorf("%d queries, want the cartoon and its characters once", *queries)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,386 @@
+package handlers
+
+import (
+	"disney/database"
+	"disney/models"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VoteReviewRequest represents the request payload to vote on a review
+type VoteReviewRequest struct {
+	Helpful *bool `json:"helpful" binding:"required"`
+}
+
+// ReportReviewRequest represents the request payload to report a review
+type ReportReviewRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// ReviewResponse represents a written review with its vote and report counts
+type ReviewResponse struct {
+	ID             uint      `json:"id"`
+	UserID         uint      `json:"user_id"`
+	UserName       string    `json:"user_name"`
+	CartoonID      uint      `json:"cartoon_id"`
+	Rating         int       `json:"rating"`
+	ReviewTitle    string    `json:"review_title"`
+	ReviewText     string    `json:"review_text"`
+	IsSpoiler      bool      `json:"is_spoiler"`
+	ReviewStatus   string    `json:"review_status"`
+	HelpfulCount   int64     `json:"helpful_count"`
+	UnhelpfulCount int64     `json:"unhelpful_count"`
+	OpenReports    int64     `json:"open_reports"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// reviewSelect selects a review row together with its author and aggregate counts
+const reviewSelect = `ratings.id, ratings.user_id, users.name AS user_name, ratings.cartoon_id,
+	ratings.rating, ratings.review_title, ratings.review_text, ratings.is_spoiler,
+	ratings.review_status, ratings.created_at,
+	(SELECT COUNT(*) FROM review_votes v WHERE v.rating_id = ratings.id AND v.helpful) AS helpful_count,
+	(SELECT COUNT(*) FROM review_votes v WHERE v.rating_id = ratings.id AND NOT v.helpful) AS unhelpful_count,
+	(SELECT COUNT(*) FROM review_reports r WHERE r.rating_id = ratings.id AND NOT r.resolved) AS open_reports`
+
+// reviewQuery returns the base query for ratings that carry written review text
+func reviewQuery() *gorm.DB {
+	return database.DB.Table("ratings").
+		Select(reviewSelect).
+		Joins("JOIN users ON users.id = ratings.user_id").
+		Where("ratings.review_text <> ''")
+}
+
+// parsePagination reads page and page_size query parameters with sane bounds
+func parsePagination(c *gin.Context, defaultSize, maxSize int) (int, int) {
+	page := 1
+	pageSize := defaultSize
+
+	if p := c.Query("page"); p != "" {
+		if parsedPage, err := strconv.Atoi(p); err == nil && parsedPage > 0 {
+			page = parsedPage
+		}
+	}
+
+	if ps := c.Query("page_size"); ps != "" {
+		if parsedSize, err := strconv.Atoi(ps); err == nil && parsedSize > 0 && parsedSize <= maxSize {
+			pageSize = parsedSize
+		}
+	}
+
+	return page, pageSize
+}
+
+// GetCartoonReviews returns the visible written reviews for a cartoon, paginated
+// Supports ?sort=recent (default) or ?sort=helpful
+func GetCartoonReviews(c *gin.Context) {
+	cartoonID := c.Param("id")
+
+	var cartoon models.Cartoon
+	if err := database.DB.Select("id").First(&cartoon, cartoonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "Cartoon not found",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	query := reviewQuery().
+		Where("ratings.cartoon_id = ?", cartoon.ID).
+		Where("ratings.review_status IN ?", []string{models.ReviewStatusVisible, models.ReviewStatusApproved})
+
+	page, pageSize := parsePagination(c, 20, 100)
+
+	var totalCount int64
+	if err := query.Session(&gorm.Session{}).Count(&totalCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to count reviews",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if c.Query("sort") == "helpful" {
+		query = query.Order("helpful_count DESC").Order("ratings.created_at DESC")
+	} else {
+		query = query.Order("ratings.created_at DESC")
+	}
+
+	reviews := []ReviewResponse{}
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Scan(&reviews).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to fetch reviews",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	totalPages := (int(totalCount) + pageSize - 1) / pageSize
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reviews fetched successfully",
+		"data":    reviews,
+		"pagination": gin.H{
+			"current_page": page,
+			"page_size":    pageSize,
+			"total_count":  totalCount,
+			"total_pages":  totalPages,
+		},
+	})
+}
+
+// findReview loads a rating that carries written review text
+func findReview(reviewID string) (models.Rating, error) {
+	var rating models.Rating
+	err := database.DB.Where("review_text <> ''").First(&rating, reviewID).Error
+	return rating, err
+}
+
+// VoteReview records a helpful/unhelpful vote on a review (User only)
+// Voting again replaces the previous vote
+func VoteReview(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req VoteReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	review, err := findReview(c.Param("id"))
+	if err != nil || review.ReviewStatus == models.ReviewStatusHidden {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Review not found",
+		})
+		return
+	}
+
+	if review.UserID == userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You cannot vote on your own review",
+		})
+		return
+	}
+
+	vote := models.ReviewVote{
+		RatingID: review.ID,
+		UserID:   userID,
+		Helpful:  *req.Helpful,
+	}
+
+	// Upsert so repeated votes switch the existing vote instead of failing
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "rating_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"helpful"}),
+	}).Create(&vote).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to record vote",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Vote recorded successfully",
+		"data": gin.H{
+			"review_id": review.ID,
+			"helpful":   vote.Helpful,
+		},
+	})
+}
+
+// RemoveReviewVote removes the current user's vote from a review (User only)
+func RemoveReviewVote(c *gin.Context) {
+	userID := c.GetUint("userID")
+	reviewID := c.Param("id")
+
+	if err := database.DB.Where("rating_id = ? AND user_id = ?", reviewID, userID).
+		Delete(&models.ReviewVote{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to remove vote",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Vote removed successfully",
+	})
+}
+
+// ReportReview flags a review for moderator attention (User only)
+// Reporting the same review again reopens the report with the new reason
+func ReportReview(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var req ReportReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	review, err := findReview(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Review not found",
+		})
+		return
+	}
+
+	if review.UserID == userID {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You cannot report your own review",
+		})
+		return
+	}
+
+	report := models.ReviewReport{
+		RatingID: review.ID,
+		UserID:   userID,
+		Reason:   req.Reason,
+	}
+
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "rating_id"}, {Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"reason": req.Reason, "resolved": false}),
+	}).Create(&report).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to report review",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Review reported successfully",
+		"data": gin.H{
+			"review_id": review.ID,
+		},
+	})
+}
+
+// GetModerationQueue lists reviews that need moderator attention (Admin only)
+// ?status=reported (default) shows reviews with open reports that were not approved,
+// ?status=hidden shows hidden reviews, ?status=all shows every written review
+func GetModerationQueue(c *gin.Context) {
+	query := reviewQuery()
+
+	switch c.DefaultQuery("status", "reported") {
+	case "reported":
+		query = query.Where("EXISTS (SELECT 1 FROM review_reports r WHERE r.rating_id = ratings.id AND NOT r.resolved)")
+	case "hidden":
+		query = query.Where("ratings.review_status = ?", models.ReviewStatusHidden)
+	case "all":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid status filter",
+			"error":   "status must be one of reported, hidden, all",
+		})
+		return
+	}
+
+	if cartoonID := c.Query("cartoon_id"); cartoonID != "" {
+		query = query.Where("ratings.cartoon_id = ?", cartoonID)
+	}
+
+	page, pageSize := parsePagination(c, 50, 100)
+
+	var totalCount int64
+	if err := query.Session(&gorm.Session{}).Count(&totalCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to count reviews"})
+		return
+	}
+
+	// Most reported first, then oldest first so nothing starves in the queue
+	reviews := []ReviewResponse{}
+	offset := (page - 1) * pageSize
+	if err := query.Order("open_reports DESC").Order("ratings.created_at ASC").
+		Offset(offset).Limit(pageSize).Scan(&reviews).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch moderation queue"})
+		return
+	}
+
+	totalPages := (int(totalCount) + pageSize - 1) / pageSize
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Moderation queue fetched successfully",
+		"data":    reviews,
+		"pagination": gin.H{
+			"current_page": page,
+			"page_size":    pageSize,
+			"total_count":  totalCount,
+			"total_pages":  totalPages,
+		},
+	})
+}
+
+// HideReview hides a review from public listings (Admin only)
+func HideReview(c *gin.Context) {
+	moderateReview(c, "HIDE", models.ReviewStatusHidden, true)
+}
+
+// RestoreReview makes a hidden review visible again (Admin only)
+func RestoreReview(c *gin.Context) {
+	moderateReview(c, "RESTORE", models.ReviewStatusVisible, false)
+}
+
+// ApproveReview marks a review as checked and resolves its open reports (Admin only)
+func ApproveReview(c *gin.Context) {
+	moderateReview(c, "APPROVE", models.ReviewStatusApproved, true)
+}
+
+// moderateReview applies a moderation status change and records it in the admin log
+// resolveReports marks all open reports as handled by this action
+func moderateReview(c *gin.Context, action, status string, resolveReports bool) {
+	review, err := findReview(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Review not found"})
+		return
+	}
+
+	adminID := c.GetUint("userID")
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&review).Update("review_status", status).Error; err != nil {
+			return err
+		}
+
+		if resolveReports {
+			if err := tx.Model(&models.ReviewReport{}).
+				Where("rating_id = ? AND NOT resolved", review.ID).
+				Update("resolved", true).Error; err != nil {
+				return err
+			}
+		}
+
+		// Every moderation action is recorded alongside the status change
+		adminLog := models.AdminLog{
+			AdminID: adminID,
+			Action:  action,
+			Entity:  fmt.Sprintf("Review: #%d (cartoon %d, user %d)", review.ID, review.CartoonID, review.UserID),
+		}
+		return tx.Create(&adminLog).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to moderate review",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Review updated successfully",
+		"data": gin.H{
+			"id":            review.ID,
+			"review_status": review.ReviewStatus,
+		},
+	})
+}
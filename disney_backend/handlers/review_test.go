@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+const findReviewQuery = `SELECT \* FROM "ratings" WHERE review_text <> '' AND "ratings"."id" = \$1`
+
+// reviewRows returns a review written by authorID with the given status
+func reviewRows(authorID uint, status string) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "user_id", "cartoon_id", "rating", "review_text", "review_status"}).
+		AddRow(7, authorID, 3, 8, "Great fun", status)
+}
+
+// responseStatus returns the review_status field of a moderation response
+func responseStatus(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Data struct {
+			ReviewStatus string `json:"review_status"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return body.Data.ReviewStatus
+}
+
+func TestHideReviewResolvesReportsAndLogs(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(findReviewQuery).WillReturnRows(reviewRows(2, "visible"))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "ratings" SET "review_status"=\$1`).
+		WithArgs("hidden", sqlmock.AnyArg(), 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "review_reports" SET "resolved"=\$1 WHERE rating_id = \$2 AND NOT resolved`).
+		WithArgs(true, 7).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(`INSERT INTO "admin_logs"`).
+		WithArgs(99, "HIDE", "Review: #7 (cartoon 3, user 2)", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	w := serve(t, HideReview, http.MethodPut, "/", "", gin.Params{{Key: "id", Value: "7"}}, 99)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	if status := responseStatus(t, w); status != "hidden" {
+		t.Errorf("review_status %q, want hidden", status)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRestoreReviewKeepsReportsOpen(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(findReviewQuery).WillReturnRows(reviewRows(2, "hidden"))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "ratings" SET "review_status"=\$1`).
+		WithArgs("visible", sqlmock.AnyArg(), 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// No update of review_reports: restoring is not a verdict on the reports
+	mock.ExpectQuery(`INSERT INTO "admin_logs"`).
+		WithArgs(99, "RESTORE", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	w := serve(t, RestoreReview, http.MethodPut, "/", "", gin.Params{{Key: "id", Value: "7"}}, 99)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	if status := responseStatus(t, w); status != "visible" {
+		t.Errorf("review_status %q, want visible", status)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestModerateReviewRollsBackOnFailure(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(findReviewQuery).WillReturnRows(reviewRows(2, "visible"))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "ratings"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "review_reports"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "admin_logs"`).WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectRollback()
+
+	w := serve(t, ApproveReview, http.MethodPut, "/", "", gin.Params{{Key: "id", Value: "7"}}, 99)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d, want 500", w.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestVoteReviewRejectsOwnAndHiddenReviews(t *testing.T) {
+	params := gin.Params{{Key: "id", Value: "7"}}
+	body := `{"helpful": true}`
+
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(findReviewQuery).WillReturnRows(reviewRows(5, "visible"))
+	if w := serve(t, VoteReview, http.MethodPost, "/", body, params, 5); w.Code != http.StatusForbidden {
+		t.Errorf("voting on own review: status %d, want 403", w.Code)
+	}
+
+	mock, _ = mockDB(t)
+	mock.ExpectQuery(findReviewQuery).WillReturnRows(reviewRows(2, "hidden"))
+	if w := serve(t, VoteReview, http.MethodPost, "/", body, params, 5); w.Code != http.StatusNotFound {
+		t.Errorf("voting on hidden review: status %d, want 404", w.Code)
+	}
+}
+
+func TestVoteReviewUpsertsVote(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(findReviewQuery).WillReturnRows(reviewRows(2, "visible"))
+	mock.ExpectQuery(`INSERT INTO "review_votes" .* ON CONFLICT \("rating_id","user_id"\) DO UPDATE SET "helpful"="excluded"."helpful"`).
+		WithArgs(7, 5, false, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	w := serve(t, VoteReview, http.MethodPost, "/", `{"helpful": false}`, gin.Params{{Key: "id", Value: "7"}}, 5)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetModerationQueueRejectsUnknownStatus(t *testing.T) {
+	mockDB(t)
+	if w := serve(t, GetModerationQueue, http.MethodGet, "/?status=spam", "", nil, 99); w.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want 400", w.Code)
+	}
+}
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		query          string
+		page, pageSize int
+	}{
+		{"/", 1, 20},
+		{"/?page=3&page_size=50", 3, 50},
+		{"/?page=0&page_size=0", 1, 20},
+		{"/?page=-2&page_size=101", 1, 20},
+		{"/?page=x&page_size=y", 1, 20},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, tt.query, nil)
+		page, pageSize := parsePagination(c, 20, 100)
+		if page != tt.page || pageSize != tt.pageSize {
+			t.Errorf("%s: got page %d size %d, want %d and %d", tt.query, page, pageSize, tt.page, tt.pageSize)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"disney/database"
+	"disney/models"
+	"disney/scheduler"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SchedulerInstance is the global scheduler running maintenance jobs
+// Initialized in main.go and used by handlers
+var SchedulerInstance *scheduler.Scheduler
+
+// GetScheduledJobs lists the maintenance jobs with their schedule, next run and last run (Admin only)
+func GetScheduledJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Scheduled jobs fetched successfully",
+		"data":    SchedulerInstance.Jobs(c.Request.Context()),
+	})
+}
+
+// RunScheduledJob starts a maintenance job now (Admin only)
+// The job runs in the background; its outcome shows up in the job's runs
+func RunScheduledJob(c *gin.Context) {
+	name := c.Param("name")
+
+	if err := SchedulerInstance.RunNow(name); err != nil {
+		status := http.StatusServiceUnavailable
+		switch {
+		case errors.Is(err, scheduler.ErrUnknownJob):
+			status = http.StatusNotFound
+		case errors.Is(err, scheduler.ErrJobRunning):
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"message": "Failed to start scheduled job",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if adminID, exists := c.Get("userID"); exists {
+		database.DB.Create(&models.AdminLog{
+			AdminID: adminID.(uint),
+			Action:  "RUN",
+			Entity:  "Scheduled job: " + name,
+		})
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Scheduled job started",
+		"data": gin.H{
+			"name": name,
+		},
+	})
+}
+
+// GetScheduledJobRuns lists recorded runs of a maintenance job, newest first (Admin only)
+// Supports ?status=running|succeeded|failed
+func GetScheduledJobRuns(c *gin.Context) {
+	page, pageSize := parsePagination(c, 50, 200)
+
+	query := database.DB.Model(&models.ScheduledJobRun{}).Where("job_name = ?", c.Param("name"))
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var totalCount int64
+	if err := query.Count(&totalCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to count scheduled job runs"})
+		return
+	}
+
+	var runs []models.ScheduledJobRun
+	offset := (page - 1) * pageSize
+	if err := query.Order("started_at DESC").Offset(offset).Limit(pageSize).Find(&runs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch scheduled job runs"})
+		return
+	}
+
+	totalPages := (int(totalCount) + pageSize - 1) / pageSize
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Scheduled job runs fetched successfully",
+		"data":    runs,
+		"pagination": gin.H{
+			"current_page": page,
+			"page_size":    pageSize,
+			"total_count":  totalCount,
+			"total_pages":  totalPages,
+		},
+	})
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestLoadTrendingCartoonsBreaksTiesByIMDbRating(t *testing.T) {
+	mock, _ := mockDB(t)
+	// Cartoon 1 leads; 2, 3 and 4 tie at the boundary and are all considered
+	mock.ExpectQuery(`FROM "cartoons" LEFT JOIN trending_scores ts`).
+		WillReturnRows(sqlmock.NewRows([]string{"cartoon_id", "score"}).AddRow(1, 9).AddRow(2, 3))
+	mock.ExpectQuery(`COALESCE\(ts.score, 0\) = \$2 AND cartoons.id > \$3`).
+		WillReturnRows(sqlmock.NewRows([]string{"cartoon_id", "score"}).AddRow(3, 3).AddRow(4, 3))
+	rows := sqlmock.NewRows([]string{
+		"id", "title", "imdb_rating", "genre_id", "age_group_id",
+		"Genre__id", "Genre__name", "AgeGroup__id", "AgeGroup__label",
+	})
+	for _, cartoon := range []struct {
+		id     uint
+		rating interface{}
+	}{{1, 5.0}, {2, nil}, {3, 6.1}, {4, 8.4}} {
+		rows.AddRow(cartoon.id, "Cartoon", cartoon.rating, 1, 2, 1, "Comedy", 2, "7+")
+	}
+	mock.ExpectQuery(loadCartoonsQuery).WillReturnRows(rows)
+
+	trending, err := loadTrendingCartoons(context.Background(), "7d", 2)
+	if err != nil {
+		t.Fatalf("loadTrendingCartoons: %v", err)
+	}
+	// The engagement score ranks first; the best rated of the tied cartoons takes the last place
+	if len(trending) != 2 || trending[0].ID != 1 || trending[1].ID != 4 {
+		t.Fatalf("trending = %+v, want cartoons 1 and 4", trending)
+	}
+	if trending[1].IMDbRating != "8.4" || trending[1].TrendingScore != 3 {
+		t.Errorf("last entry %+v, want IMDb rating 8.4 and score 3", trending[1])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"disney/database"
+	"disney/jobs"
 	"disney/models"
 	"disney/services"
 	"disney/workers"
+	"errors"
 	"log"
 	"net/http"
 
@@ -46,25 +48,36 @@ func RecordView(c *gin.Context) {
 		return
 	}
 
-	// Update recently viewed in Redis IMMEDIATELY (synchronous)
+	// Update recently viewed in the cache IMMEDIATELY (synchronous)
 	// This ensures the UI updates instantly when user clicks a cartoon
 	log.Printf("Recording view: user_id=%d, cartoon_id=%d", userID, req.CartoonID)
-	if err := services.AddRecentlyViewed(int(userID), int(req.CartoonID)); err != nil {
+	job := jobs.NewViewJob(userID, req.CartoonID)
+	if err := services.AddRecentlyViewed(int(userID), int(req.CartoonID)); errors.Is(err, services.ErrHistoryPaused) {
+		// Still counted as a view, but kept out of the user's history
+		job.HiddenFromHistory = true
+	} else if err != nil {
 		// Log error and return warning message
-		log.Printf("WARNING: Failed to add to recently viewed (Redis may not be running): %v", err)
-		// Continue with database view recording even if Redis fails
+		log.Printf("WARNING: Failed to add to recently viewed: %v", err)
+		// Continue with database view recording even if the cache fails
 	} else {
 		log.Printf("SUCCESS: Added cartoon %d to recently viewed for user %d", req.CartoonID, userID)
 	}
 
 	// Enqueue view job to worker pool for async processing (database write)
 	// This returns immediately without blocking the HTTP request
-	ViewWorkerPoolInstance.EnqueueViewJob(userID, req.CartoonID)
+	jobID, err := ViewWorkerPoolInstance.Enqueue(c.Request.Context(), job)
+	if err != nil {
+		respondEnqueueFailed(c, ViewWorkerPoolInstance.RetryAfter(), jobID, err)
+		return
+	}
 
 	// Return immediate response to client
+	// The job ID can be polled at /api/user/jobs/:id
 	c.JSON(http.StatusAccepted, gin.H{
 		"message":    "View recorded successfully",
 		"cartoon_id": req.CartoonID,
+		"job_id":     jobID,
+		"status":     jobs.StatusQueued,
 	})
 }
 
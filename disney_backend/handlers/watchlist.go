@@ -0,0 +1,763 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"disney/database"
+	"disney/jobs"
+	"disney/models"
+	"disney/workers"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// WatchlistWorkerPoolInstance is the global instance of the watchlist worker pool
+// Initialized in main.go and used by handlers
+var WatchlistWorkerPoolInstance *workers.WatchlistWorkerPool
+
+// CreateWatchlistRequest represents the request payload to create a watchlist or collection
+type CreateWatchlistRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=private unlisted public"`
+}
+
+// UpdateWatchlistRequest represents the request payload to update a watchlist
+type UpdateWatchlistRequest struct {
+	Name        *string `json:"name" binding:"omitempty,max=100"`
+	Description *string `json:"description"`
+	Visibility  *string `json:"visibility" binding:"omitempty,oneof=private unlisted public"`
+}
+
+// AddWatchlistItemRequest represents the request payload to add a cartoon to a watchlist
+type AddWatchlistItemRequest struct {
+	CartoonID uint   `json:"cartoon_id" binding:"required"`
+	Note      string `json:"note" binding:"max=500"`
+}
+
+// UpdateWatchlistItemRequest represents the request payload to change an entry's note
+type UpdateWatchlistItemRequest struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
+// ReorderWatchlistRequest lists cartoon IDs in their new order
+// Cartoons left out keep their relative order after the listed ones
+type ReorderWatchlistRequest struct {
+	CartoonIDs []uint `json:"cartoon_ids" binding:"required"`
+}
+
+// WatchlistResponse represents a watchlist with its item count and, for detail views, its items
+type WatchlistResponse struct {
+	ID          uint                   `json:"id"`
+	UserID      uint                   `json:"user_id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Visibility  string                 `json:"visibility"`
+	IsEditorial bool                   `json:"is_editorial"`
+	ShareToken  string                 `json:"share_token,omitempty"`
+	ItemCount   int64                  `json:"item_count"`
+	Items       []models.WatchlistItem `json:"items,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+// newShareToken generates a random token for share links
+func newShareToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// toWatchlistResponse converts a watchlist; the share token is only shown to people who manage the list
+func toWatchlistResponse(watchlist models.Watchlist, itemCount int64, showToken bool) WatchlistResponse {
+	response := WatchlistResponse{
+		ID:          watchlist.ID,
+		UserID:      watchlist.UserID,
+		Name:        watchlist.Name,
+		Description: watchlist.Description,
+		Visibility:  watchlist.Visibility,
+		IsEditorial: watchlist.IsEditorial,
+		ItemCount:   itemCount,
+		Items:       watchlist.Items,
+		CreatedAt:   watchlist.CreatedAt,
+		UpdatedAt:   watchlist.UpdatedAt,
+	}
+	if showToken {
+		response.ShareToken = watchlist.ShareToken
+	}
+	return response
+}
+
+// watchlistSummaries builds list responses with item counts using a single count query
+func watchlistSummaries(watchlists []models.Watchlist, showToken bool) ([]WatchlistResponse, error) {
+	ids := make([]uint, len(watchlists))
+	for i, watchlist := range watchlists {
+		ids[i] = watchlist.ID
+	}
+
+	counts := map[uint]int64{}
+	if len(ids) > 0 {
+		var rows []struct {
+			WatchlistID uint
+			Items       int64
+		}
+		if err := database.DB.Model(&models.WatchlistItem{}).
+			Select("watchlist_id, COUNT(*) AS items").
+			Where("watchlist_id IN ?", ids).
+			Group("watchlist_id").
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			counts[row.WatchlistID] = row.Items
+		}
+	}
+
+	response := make([]WatchlistResponse, 0, len(watchlists))
+	for _, watchlist := range watchlists {
+		response = append(response, toWatchlistResponse(watchlist, counts[watchlist.ID], showToken))
+	}
+	return response, nil
+}
+
+// preloadWatchlistItems loads a watchlist's items in display order with their cartoons
+func preloadWatchlistItems(db *gorm.DB) *gorm.DB {
+	return db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC").Order("id ASC")
+	}).Preload("Items.Cartoon")
+}
+
+// findManagedWatchlist loads the watchlist in the :id URL parameter that the caller may manage
+// Users manage their own lists; admins manage editorial collections (editorial = true)
+// Writes the error response and returns false when the list is not found
+func findManagedWatchlist(c *gin.Context, editorial bool) (models.Watchlist, bool) {
+	var watchlist models.Watchlist
+	query := database.DB.Where("is_editorial = ?", editorial)
+	if !editorial {
+		query = query.Where("user_id = ?", c.GetUint("userID"))
+	}
+
+	if err := query.First(&watchlist, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "Watchlist not found",
+			"error":   err.Error(),
+		})
+		return watchlist, false
+	}
+	return watchlist, true
+}
+
+// logCollectionAction records admin changes to editorial collections
+func logCollectionAction(c *gin.Context, editorial bool, action, name string) {
+	if !editorial {
+		return
+	}
+	if adminID, exists := c.Get("userID"); exists {
+		adminLog := models.AdminLog{
+			AdminID: adminID.(uint),
+			Action:  action,
+			Entity:  "Collection: " + name,
+		}
+		database.DB.Create(&adminLog)
+	}
+}
+
+// CreateWatchlist creates a named watchlist for the logged-in user
+func CreateWatchlist(c *gin.Context) {
+	createWatchlist(c, false)
+}
+
+// CreateCollection creates an editorial collection shown on the home page (Admin only)
+func CreateCollection(c *gin.Context) {
+	createWatchlist(c, true)
+}
+
+// createWatchlist creates a user watchlist or an editorial collection
+func createWatchlist(c *gin.Context, editorial bool) {
+	userID := c.GetUint("userID")
+
+	var req CreateWatchlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid request",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = models.WatchlistPrivate
+		// Editorial collections exist to be shown, so they default to public
+		if editorial {
+			visibility = models.WatchlistPublic
+		}
+	}
+
+	// Check if the user already has a list with this name
+	var existing models.Watchlist
+	if result := database.DB.Where("user_id = ? AND name = ?", userID, req.Name).First(&existing); result.RowsAffected > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"message": "Watchlist name already in use",
+			"error":   "You already have a list called " + req.Name,
+		})
+		return
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to create watchlist",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	watchlist := models.Watchlist{
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+		Visibility:  visibility,
+		ShareToken:  token,
+		IsEditorial: editorial,
+	}
+
+	if err := database.DB.Create(&watchlist).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to create watchlist",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	logCollectionAction(c, editorial, "CREATE", watchlist.Name)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Watchlist created successfully",
+		"data":    toWatchlistResponse(watchlist, 0, true),
+	})
+}
+
+// GetUserWatchlists lists the logged-in user's watchlists with item counts
+func GetUserWatchlists(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var watchlists []models.Watchlist
+	if err := database.DB.Where("user_id = ? AND is_editorial = ?", userID, false).
+		Order("updated_at DESC").Find(&watchlists).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to fetch watchlists",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	response, err := watchlistSummaries(watchlists, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to fetch watchlists",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Watchlists fetched successfully",
+		"data":    response,
+		"count":   len(response),
+	})
+}
+
+// GetWatchlist returns one of the logged-in user's watchlists with its items
+func GetWatchlist(c *gin.Context) {
+	watchlist, ok := findManagedWatchlist(c, false)
+	if !ok {
+		return
+	}
+
+	if err := preloadWatchlistItems(database.DB).First(&watchlist, watchlist.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to fetch watchlist items",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Watchlist fetched successfully",
+		"data":    toWatchlistResponse(watchlist, int64(len(watchlist.Items)), true),
+	})
+}
+
+// UpdateWatchlist renames a watchlist or changes its description or visibility
+func UpdateWatchlist(c *gin.Context) {
+	updateWatchlist(c, false)
+}
+
+// UpdateCollection updates an editorial collection (Admin only)
+func UpdateCollection(c *gin.Context) {
+	updateWatchlist(c, true)
+}
+
+// updateWatchlist applies the provided fields to a managed watchlist
+func updateWatchlist(c *gin.Context, editorial bool) {
+	var req UpdateWatchlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "Invalid request",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	watchlist, ok := findManagedWatchlist(c, editorial)
+	if !ok {
+		return
+	}
+
+	if req.Name != nil && *req.Name != watchlist.Name {
+		var existing models.Watchlist
+		if result := database.DB.Where("user_id = ? AND name = ?", watchlist.UserID, *req.Name).First(&existing); result.RowsAffected > 0 {
+			c.JSON(http.StatusConflict, gin.H{
+				"message": "Watchlist name already in use",
+				"error":   "There is already a list called " + *req.Name,
+			})
+			return
+		}
+		watchlist.Name = *req.Name
+	}
+	if req.Description != nil {
+		watchlist.Description = *req.Description
+	}
+	if req.Visibility != nil {
+		watchlist.Visibility = *req.Visibility
+	}
+
+	if err := database.DB.Save(&watchlist).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to update watchlist",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	logCollectionAction(c, editorial, "UPDATE", watchlist.Name)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Watchlist updated successfully",
+		"data":    toWatchlistResponse(watchlist, 0, true),
+	})
+}
+
+// DeleteWatchlist deletes a watchlist and its items
+func DeleteWatchlist(c *gin.Context) {
+	deleteWatchlist(c, false)
+}
+
+// DeleteCollection deletes an editorial collection (Admin only)
+func DeleteCollection(c *gin.Context) {
+	deleteWatchlist(c, true)
+}
+
+// deleteWatchlist deletes a managed watchlist; items are removed by ON DELETE CASCADE
+func deleteWatchlist(c *gin.Context, editorial bool) {
+	watchlist, ok := findManagedWatchlist(c, editorial)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.Delete(&watchlist).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to delete watchlist",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	logCollectionAction(c, editorial, "DELETE", watchlist.Name)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Watchlist deleted successfully",
+		"data": gin.H{
+			"id":   watchlist.ID,
+			"name": watchlist.Name,
+		},
+	})
+}
+
+// RegenerateShareToken replaces a watchlist's share token, invalidating old links
+func RegenerateShareToken(c *gin.Context) {
+	watchlist, ok := findManagedWatchlist(c, false)
+	if !ok {
+		return
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to regenerate share link",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if err := database.DB.Model(&watchlist).Update("share_token", token).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to regenerate share link",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Share link regenerated successfully",
+		"data":    toWatchlistResponse(watchlist, 0, true),
+	})
+}
+
+// AddWatchlistItem queues adding a cartoon to one of the user's watchlists
+func AddWatchlistItem(c *gin.Context) {
+	addWatchlistItem(c, false)
+}
+
+// AddCollectionItem queues adding a cartoon to an editorial collection (Admin only)
+func AddCollectionItem(c *gin.Context) {
+	addWatchlistItem(c, true)
+}
+
+// addWatchlistItem validates the request and enqueues the add job to the worker pool
+// The item is appended to the end of the list asynchronously
+func addWatchlistItem(c *gin.Context, editorial bool) {
+	userID := c.GetUint("userID")
+
+	var req AddWatchlistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	watchlist, ok := findManagedWatchlist(c, editorial)
+	if !ok {
+		return
+	}
+
+	// Check if cartoon exists (validation query)
+	var cartoon models.Cartoon
+	if result := database.DB.First(&cartoon, req.CartoonID); result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Cartoon not found",
+		})
+		return
+	}
+
+	job := jobs.NewWatchlistJob(userID, watchlist.ID, req.CartoonID, "add", req.Note)
+	jobID, err := WatchlistWorkerPoolInstance.Enqueue(c.Request.Context(), job)
+	if errors.Is(err, workers.ErrPoolSaturated) {
+		// Watchlist edits are user-visible, so apply them now rather than ask the client to retry
+		result, err := WatchlistWorkerPoolInstance.Process(c.Request.Context(), job)
+		if err == nil {
+			logCollectionAction(c, editorial, "UPDATE", watchlist.Name+" (added "+cartoon.Title+")")
+		}
+		respondProcessedSync(c, jobID, result, err)
+		return
+	}
+	if err != nil {
+		respondEnqueueFailed(c, WatchlistWorkerPoolInstance.RetryAfter(), jobID, err)
+		return
+	}
+
+	logCollectionAction(c, editorial, "UPDATE", watchlist.Name+" (added "+cartoon.Title+")")
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":         "Watchlist add request queued successfully",
+		"job_id":          jobID,
+		"status":          jobs.StatusQueued,
+		"watchlist_id":    watchlist.ID,
+		"cartoon_id":      req.CartoonID,
+		"queue_length":    WatchlistWorkerPoolInstance.GetQueueLength(),
+		"processing_note": "Watchlist item is being added asynchronously",
+	})
+}
+
+// RemoveWatchlistItem queues removing a cartoon from one of the user's watchlists
+func RemoveWatchlistItem(c *gin.Context) {
+	removeWatchlistItem(c, false)
+}
+
+// RemoveCollectionItem queues removing a cartoon from an editorial collection (Admin only)
+func RemoveCollectionItem(c *gin.Context) {
+	removeWatchlistItem(c, true)
+}
+
+// removeWatchlistItem enqueues the remove job to the worker pool
+func removeWatchlistItem(c *gin.Context, editorial bool) {
+	userID := c.GetUint("userID")
+
+	watchlist, ok := findManagedWatchlist(c, editorial)
+	if !ok {
+		return
+	}
+
+	cartoonID, err := strconv.ParseUint(c.Param("cartoon_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid cartoon ID",
+		})
+		return
+	}
+
+	job := jobs.NewWatchlistJob(userID, watchlist.ID, uint(cartoonID), "remove", "")
+	jobID, err := WatchlistWorkerPoolInstance.Enqueue(c.Request.Context(), job)
+	if errors.Is(err, workers.ErrPoolSaturated) {
+		// Watchlist edits are user-visible, so apply them now rather than ask the client to retry
+		result, err := WatchlistWorkerPoolInstance.Process(c.Request.Context(), job)
+		if err == nil {
+			logCollectionAction(c, editorial, "UPDATE", watchlist.Name+" (removed cartoon "+c.Param("cartoon_id")+")")
+		}
+		respondProcessedSync(c, jobID, result, err)
+		return
+	}
+	if err != nil {
+		respondEnqueueFailed(c, WatchlistWorkerPoolInstance.RetryAfter(), jobID, err)
+		return
+	}
+
+	logCollectionAction(c, editorial, "UPDATE", watchlist.Name+" (removed cartoon "+c.Param("cartoon_id")+")")
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":         "Watchlist remove request queued successfully",
+		"job_id":          jobID,
+		"status":          jobs.StatusQueued,
+		"watchlist_id":    watchlist.ID,
+		"cartoon_id":      cartoonID,
+		"queue_length":    WatchlistWorkerPoolInstance.GetQueueLength(),
+		"processing_note": "Watchlist item is being removed asynchronously",
+	})
+}
+
+// UpdateWatchlistItem changes the note on an entry of one of the user's watchlists
+func UpdateWatchlistItem(c *gin.Context) {
+	updateWatchlistItem(c, false)
+}
+
+// UpdateCollectionItem changes the note on an editorial collection entry (Admin only)
+func UpdateCollectionItem(c *gin.Context) {
+	updateWatchlistItem(c, true)
+}
+
+// updateWatchlistItem updates the note of a single watchlist entry
+func updateWatchlistItem(c *gin.Context, editorial bool) {
+	var req UpdateWatchlistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	watchlist, ok := findManagedWatchlist(c, editorial)
+	if !ok {
+		return
+	}
+
+	var item models.WatchlistItem
+	if err := database.DB.Where("watchlist_id = ? AND cartoon_id = ?", watchlist.ID, c.Param("cartoon_id")).
+		First(&item).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Cartoon is not in this watchlist",
+		})
+		return
+	}
+
+	if err := database.DB.Model(&item).Update("note", req.Note).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update watchlist item",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Watchlist item updated successfully",
+		"data":    item,
+	})
+}
+
+// ReorderWatchlist changes the order of one of the user's watchlists
+func ReorderWatchlist(c *gin.Context) {
+	reorderWatchlist(c, false)
+}
+
+// ReorderCollection changes the order of an editorial collection (Admin only)
+func ReorderCollection(c *gin.Context) {
+	reorderWatchlist(c, true)
+}
+
+// reorderWatchlist rewrites item positions: requested cartoons first, in the
+// requested order, then the remaining items in their current order
+func reorderWatchlist(c *gin.Context, editorial bool) {
+	var req ReorderWatchlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	watchlist, ok := findManagedWatchlist(c, editorial)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var items []models.WatchlistItem
+		if err := tx.Where("watchlist_id = ?", watchlist.ID).
+			Order("position ASC").Order("id ASC").Find(&items).Error; err != nil {
+			return err
+		}
+
+		byCartoon := make(map[uint]models.WatchlistItem, len(items))
+		for _, item := range items {
+			byCartoon[item.CartoonID] = item
+		}
+
+		ordered := make([]models.WatchlistItem, 0, len(items))
+		placed := map[uint]bool{}
+		for _, cartoonID := range req.CartoonIDs {
+			if item, ok := byCartoon[cartoonID]; ok && !placed[cartoonID] {
+				ordered = append(ordered, item)
+				placed[cartoonID] = true
+			}
+		}
+		for _, item := range items {
+			if !placed[item.CartoonID] {
+				ordered = append(ordered, item)
+			}
+		}
+
+		for i, item := range ordered {
+			if item.Position == i+1 {
+				continue
+			}
+			if err := tx.Model(&item).Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to reorder watchlist",
+		})
+		return
+	}
+
+	logCollectionAction(c, editorial, "UPDATE", watchlist.Name+" (reordered)")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Watchlist reordered successfully",
+	})
+}
+
+// GetSharedWatchlist returns an unlisted or public watchlist by its share token (no authentication)
+func GetSharedWatchlist(c *gin.Context) {
+	var watchlist models.Watchlist
+	err := preloadWatchlistItems(database.DB).
+		Where("share_token = ? AND visibility IN ?", c.Param("token"),
+			[]string{models.WatchlistUnlisted, models.WatchlistPublic}).
+		First(&watchlist).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": "Watchlist not found",
+			"error":   "This link is invalid or the list is private",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to fetch watchlist",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Watchlist fetched successfully",
+		"data":    toWatchlistResponse(watchlist, int64(len(watchlist.Items)), false),
+	})
+}
+
+// GetPublicWatchlists lists public user watchlists for browsing, newest first
+func GetPublicWatchlists(c *gin.Context) {
+	page, pageSize := parsePagination(c, 20, 100)
+
+	query := database.DB.Model(&models.Watchlist{}).
+		Where("visibility = ? AND is_editorial = ?", models.WatchlistPublic, false)
+
+	var totalCount int64
+	if err := query.Count(&totalCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to count watchlists"})
+		return
+	}
+
+	var watchlists []models.Watchlist
+	offset := (page - 1) * pageSize
+	if err := query.Order("updated_at DESC").Offset(offset).Limit(pageSize).Find(&watchlists).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch watchlists"})
+		return
+	}
+
+	// Public lists are shared through their token, so it is included here
+	response, err := watchlistSummaries(watchlists, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch watchlists"})
+		return
+	}
+
+	totalPages := (int(totalCount) + pageSize - 1) / pageSize
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Public watchlists fetched successfully",
+		"data":    response,
+		"pagination": gin.H{
+			"current_page": page,
+			"page_size":    pageSize,
+			"total_count":  totalCount,
+			"total_pages":  totalPages,
+		},
+	})
+}
+
+// GetCollections lists the public editorial collections with their items
+func GetCollections(c *gin.Context) {
+	var collections []models.Watchlist
+	if err := preloadWatchlistItems(database.DB).
+		Where("is_editorial = ? AND visibility = ?", true, models.WatchlistPublic).
+		Order("updated_at DESC").
+		Find(&collections).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"message": "Failed to fetch collections",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	response := make([]WatchlistResponse, 0, len(collections))
+	for _, collection := range collections {
+		response = append(response, toWatchlistResponse(collection, int64(len(collection.Items)), false))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Collections fetched successfully",
+		"data":    response,
+		"count":   len(response),
+	})
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+)
+
+func TestReorderWatchlistMovesRequestedItemsFirst(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(`SELECT \* FROM "watchlists" WHERE is_editorial = \$1 AND user_id = \$2 AND "watchlists"."id" = \$3`).
+		WithArgs(false, 5, "4", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name"}).AddRow(4, 5, "Road trip"))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "watchlist_items" WHERE watchlist_id = \$1 ORDER BY position ASC,id ASC`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "watchlist_id", "cartoon_id", "position"}).
+			AddRow(1, 4, 10, 1).AddRow(2, 4, 20, 2).AddRow(3, 4, 30, 3))
+	// Requested 20 then 10: unknown and repeated cartoons are ignored, 30 keeps its place
+	mock.ExpectExec(`UPDATE "watchlist_items" SET "position"=\$1 WHERE "id" = \$2`).WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "watchlist_items" SET "position"=\$1 WHERE "id" = \$2`).WithArgs(2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	params := gin.Params{{Key: "id", Value: "4"}}
+	w := serve(t, ReorderWatchlist, http.MethodPut, "/", `{"cartoon_ids": [20, 99, 10, 20]}`, params, 5)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReorderWatchlistOfAnotherUser(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(`FROM "watchlists" WHERE is_editorial = \$1 AND user_id = \$2`).
+		WithArgs(false, 6, "4", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	params := gin.Params{{Key: "id", Value: "4"}}
+	if w := serve(t, ReorderWatchlist, http.MethodPut, "/", `{"cartoon_ids": [20]}`, params, 6); w.Code != http.StatusNotFound {
+		t.Errorf("status %d, want 404", w.Code)
+	}
+}
+
+func TestGetSharedWatchlistHidesToken(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(`FROM "watchlists" WHERE share_token = \$1 AND visibility IN \(\$2,\$3\)`).
+		WithArgs("abc123", "unlisted", "public", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "visibility", "share_token"}).
+			AddRow(4, 5, "Road trip", "unlisted", "abc123"))
+	mock.ExpectQuery(`FROM "watchlist_items" WHERE "watchlist_items"."watchlist_id" = \$1 ORDER BY position ASC,id ASC`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "watchlist_id", "cartoon_id", "position"}).AddRow(1, 4, 10, 1))
+	mock.ExpectQuery(`FROM "cartoons" WHERE "cartoons"."id" = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(10, "Bambi"))
+
+	w := serve(t, GetSharedWatchlist, http.MethodGet, "/", "", gin.Params{{Key: "token", Value: "abc123"}}, 0)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var body struct {
+		Data WatchlistResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Data.ShareToken != "" || body.Data.ItemCount != 1 || body.Data.Items[0].Cartoon.Title != "Bambi" {
+		t.Errorf("shared watchlist %+v", body.Data)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetSharedWatchlistPrivate(t *testing.T) {
+	mock, _ := mockDB(t)
+	// Private lists never match the visibility filter
+	mock.ExpectQuery(`FROM "watchlists" WHERE share_token = \$1 AND visibility IN`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	w := serve(t, GetSharedWatchlist, http.MethodGet, "/", "", gin.Params{{Key: "token", Value: "abc123"}}, 0)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status %d, want 404", w.Code)
+	}
+}
+
+func TestNewShareToken(t *testing.T) {
+	a, err := newShareToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := newShareToken()
+	if len(a) != 32 || a == b {
+		t.Errorf("tokens %q and %q, want distinct 32 character tokens", a, b)
+	}
+}
@@ -1,34 +1,143 @@
 package jobs
 
-import "time"
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Job statuses reported by the job tracker
+const (
+	StatusQueued     = "queued"
+	StatusProcessing = "processing"
+	StatusSucceeded  = "succeeded"
+	StatusFailed     = "failed"
+	StatusDropped    = "dropped" // queue was full, the job never ran
+)
+
+// Job types recorded with each job status
+const (
+	TypeView      = "view"
+	TypeFavourite = "favourite"
+	TypeWatchlist = "watchlist"
+)
+
+// NewJobID returns a random identifier for a job
+func NewJobID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to the clock
+		return hex.EncodeToString([]byte(time.Now().Format(time.RFC3339Nano)))
+	}
+	return hex.EncodeToString(b)
+}
+
+// JobStatus is the tracked state of an async job, returned by the job status endpoint
+type JobStatus struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	UserID    uint        `json:"user_id"`
+	Status    string      `json:"status"`
+	Attempts  int         `json:"attempts,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	Result    interface{} `json:"result,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
 
 // ViewJob represents a job to record a cartoon view
 // Used by worker pool to safely process view recordings under high concurrency
 type ViewJob struct {
+	ID        string
 	UserID    uint
 	CartoonID uint
 	Timestamp time.Time
+	// HiddenFromHistory is set when the user's history was paused
+	HiddenFromHistory bool
 }
 
+// NewViewJob creates a view job with a fresh ID
+func NewViewJob(userID, cartoonID uint) ViewJob {
+	return ViewJob{ID: NewJobID(), UserID: userID, CartoonID: cartoonID, Timestamp: time.Now()}
+}
+
+// JobID returns the job's ID (used by worker pools)
+func (j ViewJob) JobID() string { return j.ID }
+
+// OwnerID returns the user who requested the job (used by worker pools)
+func (j ViewJob) OwnerID() uint { return j.UserID }
+
 // FavouriteJob represents a job to add or remove a favourite
 // Used by worker pool to safely process favourite operations under high concurrency
 type FavouriteJob struct {
+	ID        string
 	UserID    uint
 	CartoonID uint
 	Action    string // "add" or "remove"
 	Timestamp time.Time
 }
 
+// NewFavouriteJob creates a favourite job with a fresh ID
+// action: "add" or "remove"
+func NewFavouriteJob(userID, cartoonID uint, action string) FavouriteJob {
+	return FavouriteJob{ID: NewJobID(), UserID: userID, CartoonID: cartoonID, Action: action, Timestamp: time.Now()}
+}
+
+// JobID returns the job's ID (used by worker pools)
+func (j FavouriteJob) JobID() string { return j.ID }
+
+// OwnerID returns the user who requested the job (used by worker pools)
+func (j FavouriteJob) OwnerID() uint { return j.UserID }
+
+// WatchlistJob represents a job to add or remove a cartoon in a watchlist
+// Used by worker pool the same way as FavouriteJob
+type WatchlistJob struct {
+	ID          string
+	WatchlistID uint
+	CartoonID   uint
+	UserID      uint   // user who requested the change
+	Action      string // "add" or "remove"
+	Note        string // only used when adding
+	Timestamp   time.Time
+}
+
+// NewWatchlistJob creates a watchlist job with a fresh ID
+// action: "add" or "remove"; note is only used when adding
+func NewWatchlistJob(userID, watchlistID, cartoonID uint, action, note string) WatchlistJob {
+	return WatchlistJob{
+		ID:          NewJobID(),
+		WatchlistID: watchlistID,
+		CartoonID:   cartoonID,
+		UserID:      userID,
+		Action:      action,
+		Note:        note,
+		Timestamp:   time.Now(),
+	}
+}
+
+// JobID returns the job's ID (used by worker pools)
+func (j WatchlistJob) JobID() string { return j.ID }
+
+// OwnerID returns the user who requested the job (used by worker pools)
+func (j WatchlistJob) OwnerID() uint { return j.UserID }
+
 // ViewJobResponse represents the result of a view job (for response tracking)
 type ViewJobResponse struct {
-	Success   bool
-	Error     string
-	ViewCount int64
+	Success   bool   `json:"success"`
+	Error     string `json:"error,omitempty"`
+	ViewCount int64  `json:"view_count"`
 }
 
 // FavouriteJobResponse represents the result of a favourite job
 type FavouriteJobResponse struct {
-	Success bool
-	Error   string
-	Message string
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+	Message string `json:"message"`
+}
+
+// WatchlistJobResponse represents the result of a watchlist job
+type WatchlistJobResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+	Message string `json:"message"`
 }
@@ -1,57 +1,181 @@
 package main
 
 import (
+	"context"
+	"disney/cache"
 	"disney/config"
 	"disney/database"
 	"disney/handlers"
 	"disney/middleware"
 	"disney/routes"
+	"disney/scheduler"
 	"disney/services"
+	"disney/utils"
 	"disney/workers"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 )
 
-// loadEnv loads .env file only in local development
-func loadEnv() {
-	if os.Getenv("RENDER") == "" {
-		// local development only
-		if err := godotenv.Load(); err != nil {
-			log.Println("No .env file found, using system env")
-		}
-	}
-}
-
 func main() {
-	loadEnv()
+	// Defaults < config file (-config or CONFIG_FILE) < environment and .env < flags
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	log.Println(cfg.Summary())
+	tokens := utils.NewTokenIssuer(cfg.Auth.JWTSecret)
 
 	// Initialize database
-	database.InitDB()
+	database.InitDB(cfg.Database)
+
+	// Apply the configured rating scale to the ratings check constraint
+	services.SetRatingScale(services.RatingScale{Min: cfg.Ratings.ScaleMin, Max: cfg.Ratings.ScaleMax})
+	database.EnsureRatingScale(cfg.Ratings.ScaleMin, cfg.Ratings.ScaleMax)
 
 	// Initialize Redis
-	config.InitRedis()
-	defer config.CloseRedis()
+	config.InitRedis(cfg.Redis)
+
+	// Shared cache: a memory L1 in front of Redis by default, serving from memory
+	// while Redis is down and reconnecting when it comes back
+	redisOptions, _ := cfg.Redis.Options()
+	appCache := services.NewCache(cfg.Cache, config.RedisClient, redisOptions)
+	services.SetRecentlyViewedCache(appCache, cfg.RecentlyViewed)
+
+	// Related-cartoon lists share the cache; the catalogue version that
+	// invalidates them lives in Redis so a change on any replica reaches all of them
+	relatedRedis := services.NewRedisCache(cfg.Cache, config.RedisClient, redisOptions)
+	services.SetRelatedCache(appCache, relatedRedis)
+
+	// Cache catalogue responses by tag; catalogue changes invalidate them on every replica via Redis pub/sub
+	responseCache := services.NewResponseCache(services.NewRedisCache(cfg.Cache, config.RedisClient, redisOptions),
+		time.Duration(cfg.Cache.ResponseTTL), cfg.Cache.ResponseLocalEntries)
+	services.SetResponseCache(responseCache)
+
+	// IMDb ratings and other metadata come from OMDb by default (METADATA_PROVIDER=fixture reads a local file)
+	metadataProvider, err := services.NewMetadataProvider(cfg.Metadata, config.RedisClient, appCache)
+	if err != nil {
+		log.Fatalf("Failed to create metadata provider: %v", err)
+	}
+	handlers.MetadataProviderInstance = metadataProvider
+
+	// Track async job statuses in Redis (in memory while Redis is unavailable)
+	jobStatusRedis := services.NewRedisCache(cfg.Cache, config.RedisClient, redisOptions)
+	jobTracker := workers.NewJobTracker(jobStatusRedis, time.Duration(cfg.Queue.JobStatusTTL))
+	handlers.JobTrackerInstance = jobTracker
 
-	// Set Redis client in services
-	services.SetRedisClient(config.RedisClient)
+	// Worker pool jobs go to Redis Streams so they survive restarts (QUEUE_BACKEND=memory keeps them in memory)
+	// Each pool's size and queue capacity come from the configuration (cfg.Workers)
+	// Each pool refuses new jobs at 80% of its queue capacity by default
+	queueOptions := func(pool config.PoolConfig) workers.QueueOptions {
+		return workers.QueueOptions{
+			Backend:           cfg.Queue.Backend,
+			Capacity:          pool.QueueCapacity,
+			VisibilityTimeout: time.Duration(cfg.Queue.VisibilityTimeout),
+			Consumer:          cfg.Queue.Consumer,
+		}
+	}
+	poolOptions := func(pool config.PoolConfig) workers.PoolOptions {
+		return workers.PoolOptions{
+			Concurrency:         pool.Workers,
+			SaturationThreshold: pool.SaturatedAt(),
+			RetryAfter:          time.Duration(cfg.Queue.RetryAfter),
+		}
+	}
 
 	// Initialize and start view worker pool
-	// 5 concurrent workers, buffer size of 100 jobs
-	viewWorkerPool := workers.NewViewWorkerPool(5, 100)
+	// Workers insert views in batches; the queue also holds the unacknowledged
+	// views of open batches, so it gets more room than the other pools
+	viewPool := cfg.Workers.View
+	viewOptions := poolOptions(viewPool)
+	viewOptions.BatchSize = cfg.Workers.ViewBatchSize
+	viewOptions.BatchWindow = time.Duration(cfg.Workers.ViewBatchWindow)
+	viewWorkerPool := workers.NewViewWorkerPool(workers.NewQueue(config.RedisClient, "views", queueOptions(viewPool)), jobTracker, viewOptions)
 	viewWorkerPool.Start()
 	handlers.ViewWorkerPoolInstance = viewWorkerPool
 
 	// Initialize and start favourite worker pool
-	// 5 concurrent workers, buffer size of 100 jobs
-	favouriteWorkerPool := workers.NewFavouriteWorkerPool(5, 100)
+	favouritePool := cfg.Workers.Favourite
+	favouriteWorkerPool := workers.NewFavouriteWorkerPool(workers.NewQueue(config.RedisClient, "favourites", queueOptions(favouritePool)), jobTracker,
+		poolOptions(favouritePool))
 	favouriteWorkerPool.Start()
 	handlers.FavouriteWorkerPoolInstance = favouriteWorkerPool
 
+	// Initialize and start watchlist worker pool
+	watchlistPool := cfg.Workers.Watchlist
+	watchlistWorkerPool := workers.NewWatchlistWorkerPool(workers.NewQueue(config.RedisClient, "watchlists", queueOptions(watchlistPool)), jobTracker,
+		poolOptions(watchlistPool))
+	watchlistWorkerPool.Start()
+	handlers.WatchlistWorkerPoolInstance = watchlistWorkerPool
+
+	// Periodic maintenance jobs; jobs that are not per-instance run on one replica at a time,
+	// elected with a Redis lock (a lease row in Postgres without Redis)
+	sqlDB, err := database.DB.DB()
+	if err != nil {
+		log.Fatalf("Failed to get sql DB: %v", err)
+	}
+	jobScheduler := scheduler.New(scheduler.NewLocker(config.RedisClient, sqlDB))
+	if err := services.SetTrendingWindows(cfg.Trending.Windows); err != nil {
+		log.Fatalf("Failed to set trending windows: %v", err)
+	}
+
+	// Each replica trains its own in-memory recommendation model
+	jobScheduler.MustRegister(scheduler.Job{
+		Name:        "recommendations.train",
+		Spec:        "@every " + cfg.Recommendations.RetrainInterval.String(),
+		Timeout:     5 * time.Minute,
+		PerInstance: true,
+		RunOnStart:  true,
+		Run:         services.TrainRecommendations,
+	})
+	// Materialise engagement-based trending scores
+	jobScheduler.MustRegister(scheduler.Job{
+		Name:       "trending.refresh",
+		Spec:       "@every " + cfg.Trending.RefreshInterval.String(),
+		Timeout:    2 * time.Minute,
+		RunOnStart: true,
+		Run:        services.RefreshTrendingScores,
+	})
+	// Delete request logs, admin logs and job runs past their retention, nightly at 03:00
+	jobScheduler.MustRegister(scheduler.Job{
+		Name: "logs.retention",
+		Spec: "0 3 * * *",
+		Run: func(ctx context.Context) error {
+			return services.PurgeOldLogs(ctx, cfg.Retention)
+		},
+	})
+	// Refresh stale IMDb ratings stored on cartoons, so handlers never wait on the provider
+	jobScheduler.MustRegister(scheduler.Job{
+		Name:       "imdb.refresh",
+		Spec:       "@hourly",
+		Timeout:    30 * time.Minute,
+		RunOnStart: true,
+		Run: func(ctx context.Context) error {
+			return services.RefreshIMDbRatings(ctx, metadataProvider, cfg.Metadata)
+		},
+	})
+	// Repair ID sequences that fell behind their tables
+	jobScheduler.MustRegister(scheduler.Job{
+		Name: "database.sequences",
+		Spec: "@hourly",
+		Run: func(ctx context.Context) error {
+			_, err := database.CheckSequences(ctx)
+			return err
+		},
+	})
+	jobScheduler.Start()
+	handlers.SchedulerInstance = jobScheduler
+
 	// Create Gin router
 	router := gin.Default()
 
@@ -59,40 +183,112 @@ func main() {
 	corsConfig := cors.DefaultConfig()
 	corsConfig.AllowAllOrigins = true
 	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", "Accept"}
-	corsConfig.ExposeHeaders = []string{"Content-Length"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", "Accept", "If-None-Match", "If-Modified-Since"}
+	corsConfig.ExposeHeaders = []string{"Content-Length", "ETag", "Last-Modified", "Retry-After"}
 	corsConfig.AllowCredentials = false
 	router.Use(cors.New(corsConfig))
 
 	// Add request logger middleware to log all requests
 	router.Use(middleware.RequestLogger())
 
+	// Health check, degraded while a worker pool is saturated
+	router.GET("/health", handlers.Health)
+
 	// Public Auth routes (no authentication required)
 	auth := router.Group("/api/auth")
 	{
-		auth.POST("/signup", handlers.Signup)
-		auth.POST("/login", handlers.Login)
-		auth.POST("/create-admin", handlers.CreateAdmin)
+		authHandler := handlers.NewAuthHandler(tokens, cfg.Auth.AdminSecret)
+		auth.POST("/signup", authHandler.Signup)
+		auth.POST("/login", authHandler.Login)
+		auth.POST("/create-admin", authHandler.CreateAdmin)
 	}
 
 	// User routes with middleware
-	routes.UserRoutes(router)
+	routes.UserRoutes(router, tokens)
+	// Shared links (no authentication required)
+	routes.SharedRoutes(router)
+	// Public catalogue reads such as reviews (no authentication required)
+	routes.PublicRoutes(router)
 	// Setup routes
 	adminGroup := router.Group("/api/admin")
-	routes.SetupAdminRoutes(adminGroup)
-
-	// Get port from environment (Render uses PORT, local uses SERVER_PORT or defaults to 8080)
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = os.Getenv("SERVER_PORT")
-		if port == "" {
-			port = "8080"
+	routes.SetupAdminRoutes(adminGroup, tokens)
+
+	// Render uses PORT, local uses SERVER_PORT or defaults to 8080
+	port := cfg.Server.Port
+
+	fmt.Printf("Server running on port %s\n", port)
+	fmt.Printf("View worker pool: %d workers, buffer: %d, saturated at: %d\n", viewPool.Workers, viewPool.QueueCapacity, viewWorkerPool.SaturationThreshold())
+	fmt.Printf("Favourite worker pool: %d workers, buffer: %d, saturated at: %d\n", favouritePool.Workers, favouritePool.QueueCapacity, favouriteWorkerPool.SaturationThreshold())
+	fmt.Printf("Watchlist worker pool: %d workers, buffer: %d, saturated at: %d\n", watchlistPool.Workers, watchlistPool.QueueCapacity, watchlistWorkerPool.SaturationThreshold())
+
+	server := &http.Server{
+		Addr:    "0.0.0.0:" + port,
+		Handler: router,
+	}
+
+	// Stop on SIGINT (Ctrl+C) or SIGTERM (sent by Render and Docker)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server failed: %v", err)
 		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	log.Println("Shutdown signal received, shutting down gracefully...")
+	shutdownTimeout := time.Duration(cfg.Server.ShutdownTimeout)
+
+	// 1. Stop accepting requests and drain in-flight ones, so no job is enqueued after the pools stop
+	httpCtx, cancelHTTP := context.WithTimeout(context.Background(), shutdownTimeout)
+	if err := server.Shutdown(httpCtx); err != nil {
+		log.Printf("HTTP server did not drain in time: %v", err)
 	}
+	cancelHTTP()
 
-	fmt.Printf("Server running on port %s\n", port)
-	fmt.Println("View worker pool: 5 workers, buffer: 100")
-	fmt.Println("Favourite worker pool: 5 workers, buffer: 100")
-	router.Run("0.0.0.0:" + port)
+	// 2. Drain the worker pools and let running scheduled jobs finish, in parallel under a shared deadline
+	poolsCtx, cancelPools := context.WithTimeout(context.Background(), shutdownTimeout)
+	var pools sync.WaitGroup
+	pools.Add(1)
+	go func() {
+		defer pools.Done()
+		if err := jobScheduler.Stop(poolsCtx); err != nil {
+			log.Printf("Scheduled jobs did not finish in time: %v", err)
+		}
+	}()
+	for _, shutdown := range []func(context.Context) (workers.ShutdownStats, error){
+		viewWorkerPool.Shutdown,
+		favouriteWorkerPool.Shutdown,
+		watchlistWorkerPool.Shutdown,
+	} {
+		pools.Add(1)
+		go func(shutdown func(context.Context) (workers.ShutdownStats, error)) {
+			defer pools.Done()
+			if _, err := shutdown(poolsCtx); err != nil {
+				log.Printf("Worker pool did not drain in time: %v", err)
+			}
+		}(shutdown)
+	}
+	pools.Wait()
+	cancelPools()
 
+	// 3. Close the caches, Redis and the database once nothing uses them
+	responseCache.Close()
+	if closer, ok := appCache.(io.Closer); ok {
+		closer.Close()
+	}
+	for _, remote := range []*cache.Redis{relatedRedis, jobStatusRedis} {
+		if remote != nil {
+			remote.Close()
+		}
+	}
+	if err := config.CloseRedis(); err != nil {
+		log.Printf("Error closing Redis: %v", err)
+	}
+	if err := database.CloseDB(); err != nil {
+		log.Printf("Error closing database: %v", err)
+	}
+	log.Println("Server stopped")
 }
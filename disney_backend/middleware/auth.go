@@ -9,7 +9,7 @@ import (
 )
 
 // AuthRequired is middleware that verifies JWT token
-func AuthRequired() gin.HandlerFunc {
+func AuthRequired(tokens *utils.TokenIssuer) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get token from Authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -32,7 +32,7 @@ func AuthRequired() gin.HandlerFunc {
 		}
 
 		// Verify token
-		claims, err := utils.VerifyToken(tokenString)
+		claims, err := tokens.Verify(tokenString)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Invalid or expired token",
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cache-Control policies for CachePolicy
+const (
+	// CachePrivate lets the browser keep catalogue responses for a minute, then
+	// revalidate them with their ETag
+	// The catalogue is served behind AuthRequired, so it must not be "public":
+	// a shared cache or CDN may then store a response to a request with an
+	// Authorization header and serve it to anonymous clients (RFC 9111 §3.5)
+	// Repeat requests within max-age never reach the server, so routes under
+	// it must not record anything (views are recorded with POST /user/views)
+	CachePrivate = "private, max-age=60"
+	// CacheRevalidate lets the browser keep a response but revalidate it on every use
+	CacheRevalidate = "private, no-cache"
+	// CacheNoStore keeps personal and admin responses out of every cache
+	CacheNoStore = "private, no-store"
+)
+
+// CachePolicy sets Cache-Control on GET and HEAD responses
+// Unless the policy is no-store, successful responses also get a strong ETag
+// (a hash of the body) and conditional requests are answered with 304 Not
+// Modified: If-None-Match against the ETag, or else If-Modified-Since against
+// the Last-Modified header the handler set, if any
+// Error responses are never stored
+func CachePolicy(policy string) gin.HandlerFunc {
+	noStore := strings.Contains(policy, "no-store")
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		if method != http.MethodGet && method != http.MethodHead {
+			c.Next()
+			return
+		}
+		if noStore {
+			c.Header("Cache-Control", policy)
+			c.Next()
+			return
+		}
+
+		// Buffer the response so the ETag can be computed before anything is sent
+		original := c.Writer
+		buffered := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = buffered
+		c.Next()
+		c.Writer = original
+
+		header := original.Header()
+		if buffered.status != http.StatusOK {
+			header.Set("Cache-Control", CacheNoStore)
+			original.WriteHeader(buffered.status)
+			original.Write(buffered.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(buffered.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		header.Set("Cache-Control", policy)
+		header.Set("ETag", etag)
+
+		if notModified(c.Request, etag, header.Get("Last-Modified")) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			original.WriteHeader(http.StatusNotModified)
+			return
+		}
+		original.WriteHeader(http.StatusOK)
+		original.Write(buffered.body.Bytes())
+	}
+}
+
+// notModified evaluates If-None-Match, or else If-Modified-Since, as in RFC 9110
+func notModified(r *http.Request, etag, lastModified string) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			// Weak comparison: W/"x" matches "x"
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// bufferedWriter holds a handler's response until CachePolicy decides what to send
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body    bytes.Buffer
+	status  int
+	written bool
+}
+
+// WriteHeader records the status without sending it
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+// WriteHeaderNow marks the response as written without sending it
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+// Write buffers body bytes
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+// WriteString buffers body bytes
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+// Status returns the recorded status
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+// Size returns the number of buffered body bytes
+func (w *bufferedWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+// Written reports whether the handler has written a response
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var lastModified = time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)
+
+// cachedRouter serves catalogue-like routes behind CachePolicy(policy)
+func cachedRouter(policy string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CachePolicy(policy))
+	router.GET("/cartoons/3", func(c *gin.Context) {
+		c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+		c.JSON(http.StatusOK, gin.H{"title": "DuckTales"})
+	})
+	router.GET("/cartoons/404", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cartoon not found"})
+	})
+	router.POST("/cartoons", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"title": "DuckTales"})
+	})
+	return router
+}
+
+// request sends a request with the given headers
+func request(router *gin.Engine, method, target string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCachePolicySetsETagAndCacheControl(t *testing.T) {
+	router := cachedRouter(CachePrivate)
+	first := request(router, http.MethodGet, "/cartoons/3", nil)
+	second := request(router, http.MethodGet, "/cartoons/3", nil)
+
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || first.Body.String() != `{"title":"DuckTales"}` {
+		t.Fatalf("status %d body %s", first.Code, first.Body)
+	}
+	if len(etag) != 34 || etag != second.Header().Get("ETag") {
+		t.Errorf("ETag %q then %q, want a stable strong ETag", etag, second.Header().Get("ETag"))
+	}
+	if cc := first.Header().Get("Cache-Control"); cc != CachePrivate {
+		t.Errorf("Cache-Control %q", cc)
+	}
+}
+
+func TestCachePolicyAnswersConditionalRequests(t *testing.T) {
+	router := cachedRouter(CachePrivate)
+	etag := request(router, http.MethodGet, "/cartoons/3", nil).Header().Get("ETag")
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{"matching ETag", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+		{"weak ETag in a list", map[string]string{"If-None-Match": `"other", W/` + etag}, http.StatusNotModified},
+		{"any ETag", map[string]string{"If-None-Match": "*"}, http.StatusNotModified},
+		{"stale ETag", map[string]string{"If-None-Match": `"other"`}, http.StatusOK},
+		{"not modified since", map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)}, http.StatusNotModified},
+		{"modified since", map[string]string{"If-Modified-Since": lastModified.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+		{"unparseable date", map[string]string{"If-Modified-Since": "yesterday"}, http.StatusOK},
+		// If-None-Match takes precedence over If-Modified-Since
+		{"stale ETag, unmodified date", map[string]string{
+			"If-None-Match":     `"other"`,
+			"If-Modified-Since": lastModified.Format(http.TimeFormat),
+		}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := request(router, http.MethodGet, "/cartoons/3", tt.headers)
+		if w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.want)
+		}
+		if tt.want == http.StatusNotModified && (w.Body.Len() != 0 || w.Header().Get("ETag") != etag) {
+			t.Errorf("%s: 304 with body %q and ETag %q", tt.name, w.Body, w.Header().Get("ETag"))
+		}
+	}
+}
+
+func TestCachePolicyDoesNotStoreErrors(t *testing.T) {
+	w := request(cachedRouter(CachePrivate), http.MethodGet, "/cartoons/404", map[string]string{"If-None-Match": "*"})
+	if w.Code != http.StatusNotFound || w.Header().Get("ETag") != "" || w.Header().Get("Cache-Control") != CacheNoStore {
+		t.Errorf("status %d, ETag %q, Cache-Control %q", w.Code, w.Header().Get("ETag"), w.Header().Get("Cache-Control"))
+	}
+	if w.Body.String() != `{"error":"Cartoon not found"}` {
+		t.Errorf("body %s", w.Body)
+	}
+}
+
+func TestCachePolicyNoStoreAndUnsafeMethods(t *testing.T) {
+	w := request(cachedRouter(CacheNoStore), http.MethodGet, "/cartoons/3", map[string]string{"If-None-Match": "*"})
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != "" || w.Header().Get("Cache-Control") != CacheNoStore {
+		t.Errorf("no-store: status %d, ETag %q, Cache-Control %q", w.Code, w.Header().Get("ETag"), w.Header().Get("Cache-Control"))
+	}
+
+	w = request(cachedRouter(CachePrivate), http.MethodPost, "/cartoons", map[string]string{"If-None-Match": "*"})
+	if w.Code != http.StatusCreated || w.Header().Get("ETag") != "" || w.Header().Get("Cache-Control") != "" {
+		t.Errorf("POST: status %d, ETag %q, Cache-Control %q", w.Code, w.Header().Get("ETag"), w.Header().Get("Cache-Control"))
+	}
+}
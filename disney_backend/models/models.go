@@ -14,6 +14,11 @@ type User struct {
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 	Age          int       `gorm:"type:int;not null" json:"age"`
+	// HistoryPaused stops views from being added to the user's recently viewed history
+	HistoryPaused bool `gorm:"default:false" json:"history_paused"`
+	// HistoryClearedAt hides views at or before it from the recently viewed
+	// history, including views still queued when the history was cleared
+	HistoryClearedAt *time.Time `json:"-"`
 }
 
 // Table naming manually
@@ -56,6 +61,13 @@ type Cartoon struct {
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 
+	// IMDb data, refreshed in the background by the metadata provider
+	// A pinned IMDb ID was set by an admin and is never replaced by a title lookup
+	IMDbID              string     `gorm:"column:imdb_id;type:varchar(20);index" json:"imdb_id,omitempty"`
+	IMDbIDPinned        bool       `gorm:"column:imdb_id_pinned;default:false" json:"imdb_id_pinned"`
+	IMDbRating          *float64   `gorm:"column:imdb_rating;type:numeric(3,1)" json:"imdb_rating,omitempty"`
+	IMDbRatingFetchedAt *time.Time `gorm:"column:imdb_rating_fetched_at;index" json:"imdb_rating_fetched_at,omitempty"`
+
 	// Foreign key relationships
 	Genre      Genre       `gorm:"foreignKey:GenreID;constraint:OnDelete:RESTRICT" json:"genre,omitempty"`
 	AgeGroup   AgeGroup    `gorm:"foreignKey:AgeGroupID;constraint:OnDelete:RESTRICT" json:"age_group,omitempty"`
@@ -86,10 +98,17 @@ func (Character) TableName() string {
 // Rating Table
 type Rating struct {
 	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserID    uint      `gorm:"not null;index:idx_user_cartoon" json:"user_id"`
-	CartoonID uint      `gorm:"not null;index:idx_user_cartoon" json:"cartoon_id"`
-	Rating    int       `gorm:"type:int;not null;check:rating >= 1 AND rating <= 10" json:"rating"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_ratings_user_cartoon" json:"user_id"`
+	CartoonID uint      `gorm:"not null;uniqueIndex:idx_ratings_user_cartoon" json:"cartoon_id"`
+	Rating    int       `gorm:"type:int;not null" json:"rating"` // range enforced by chk_ratings_rating, see database.EnsureRatingScale
 	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Optional written review attached to the rating
+	ReviewTitle  string `gorm:"type:varchar(255)" json:"review_title,omitempty"`
+	ReviewText   string `gorm:"type:text" json:"review_text,omitempty"`
+	IsSpoiler    bool   `gorm:"default:false" json:"is_spoiler"`
+	ReviewStatus string `gorm:"type:varchar(20);default:'visible';not null;index" json:"review_status"` // visible/hidden/approved
 
 	// Foreign key relationships
 	User    User    `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
@@ -101,11 +120,85 @@ func (Rating) TableName() string {
 	return "ratings"
 }
 
+// Rating history actions stored in RatingHistory.Action
+const (
+	RatingActionCreate = "create"
+	RatingActionUpdate = "update"
+	RatingActionDelete = "delete"
+)
+
+// RatingHistory Table (audit trail of rating edits)
+// RatingID is kept without a foreign key so history survives rating deletion
+type RatingHistory struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	RatingID  uint      `gorm:"not null;index" json:"rating_id"`
+	UserID    uint      `gorm:"not null;index:idx_rating_history_user_cartoon" json:"user_id"`
+	CartoonID uint      `gorm:"not null;index:idx_rating_history_user_cartoon" json:"cartoon_id"`
+	Action    string    `gorm:"type:varchar(20);not null" json:"action"` // create/update/delete
+	OldRating *int      `gorm:"type:int" json:"old_rating"`
+	NewRating *int      `gorm:"type:int" json:"new_rating"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	// Foreign key relationships
+	User    User    `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Cartoon Cartoon `gorm:"foreignKey:CartoonID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// Table naming manually
+func (RatingHistory) TableName() string {
+	return "rating_histories"
+}
+
+// Review moderation states stored in Rating.ReviewStatus
+const (
+	ReviewStatusVisible  = "visible"
+	ReviewStatusHidden   = "hidden"
+	ReviewStatusApproved = "approved"
+)
+
+// ReviewVote Table (helpful/unhelpful votes on written reviews)
+type ReviewVote struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	RatingID  uint      `gorm:"not null;uniqueIndex:idx_review_vote_user" json:"rating_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_review_vote_user" json:"user_id"`
+	Helpful   bool      `gorm:"not null" json:"helpful"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Foreign key relationships
+	Rating Rating `gorm:"foreignKey:RatingID;constraint:OnDelete:CASCADE" json:"-"`
+	User   User   `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// Table naming manually
+func (ReviewVote) TableName() string {
+	return "review_votes"
+}
+
+// ReviewReport Table (users flagging written reviews for moderation)
+type ReviewReport struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	RatingID  uint      `gorm:"not null;uniqueIndex:idx_review_report_user" json:"rating_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_review_report_user" json:"user_id"`
+	Reason    string    `gorm:"type:varchar(500)" json:"reason"`
+	Resolved  bool      `gorm:"default:false;not null;index" json:"resolved"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Foreign key relationships
+	Rating Rating `gorm:"foreignKey:RatingID;constraint:OnDelete:CASCADE" json:"-"`
+	User   User   `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// Table naming manually
+func (ReviewReport) TableName() string {
+	return "review_reports"
+}
+
 // Favourite Table
 type Favourite struct {
-	ID        uint `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserID    uint `gorm:"not null;uniqueIndex:idx_user_cartoon_fav" json:"user_id"`
-	CartoonID uint `gorm:"not null;uniqueIndex:idx_user_cartoon_fav" json:"cartoon_id"`
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_user_cartoon_fav" json:"user_id"`
+	CartoonID uint      `gorm:"not null;uniqueIndex:idx_user_cartoon_fav" json:"cartoon_id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"` // NULL for favourites added before this column existed
 
 	// Foreign key relationships
 	User    User    `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
@@ -117,12 +210,67 @@ func (Favourite) TableName() string {
 	return "favourites"
 }
 
+// Watchlist visibility levels
+// private lists are only visible to their owner, unlisted lists to anyone with
+// the share link, and public lists are also listed for browsing
+const (
+	WatchlistPrivate  = "private"
+	WatchlistUnlisted = "unlisted"
+	WatchlistPublic   = "public"
+)
+
+// Watchlist Table (named, ordered lists of cartoons; editorial ones are curated by admins)
+type Watchlist struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_watchlist_user_name" json:"user_id"`
+	Name        string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_watchlist_user_name" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	Visibility  string    `gorm:"type:varchar(20);default:'private';not null;index" json:"visibility"` // private/unlisted/public
+	ShareToken  string    `gorm:"type:varchar(64);not null;uniqueIndex" json:"share_token,omitempty"`
+	IsEditorial bool      `gorm:"default:false;not null;index" json:"is_editorial"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Foreign key relationships
+	User  User            `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Items []WatchlistItem `gorm:"foreignKey:WatchlistID;constraint:OnDelete:CASCADE" json:"items,omitempty"`
+}
+
+// Table naming manually
+func (Watchlist) TableName() string {
+	return "watchlists"
+}
+
+// WatchlistItem Table (a cartoon in a watchlist with its position and note)
+type WatchlistItem struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	WatchlistID uint      `gorm:"not null;uniqueIndex:idx_watchlist_cartoon" json:"watchlist_id"`
+	CartoonID   uint      `gorm:"not null;uniqueIndex:idx_watchlist_cartoon;index" json:"cartoon_id"`
+	Position    int       `gorm:"not null;default:0" json:"position"`
+	Note        string    `gorm:"type:varchar(500)" json:"note"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// Foreign key relationships
+	Cartoon Cartoon `gorm:"foreignKey:CartoonID;constraint:OnDelete:CASCADE" json:"cartoon,omitempty"`
+}
+
+// Table naming manually
+func (WatchlistItem) TableName() string {
+	return "watchlist_items"
+}
+
 // View Table (analytics)
 type View struct {
 	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
 	CartoonID uint      `gorm:"not null;index" json:"cartoon_id"`
 	UserID    *uint     `gorm:"index" json:"user_id,omitempty"` // nullable
 	ViewedAt  time.Time `gorm:"not null;index" json:"viewed_at"`
+	// JobID is the queue job that recorded the view; it makes redelivered jobs idempotent
+	JobID *string `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	// HiddenFromHistory views still count towards view counts and trending, but
+	// not the user's recently viewed history (removed by the user, or recorded
+	// while their history was paused)
+	HiddenFromHistory bool `gorm:"default:false;index" json:"hidden_from_history"`
 
 	// Foreign key relationships
 	Cartoon Cartoon `gorm:"foreignKey:CartoonID;constraint:OnDelete:CASCADE" json:"cartoon,omitempty"`
@@ -134,6 +282,94 @@ func (View) TableName() string {
 	return "views"
 }
 
+// HistoryRemoval Table (a cartoon the user removed from their recently viewed history)
+// Views of the cartoon at or before RemovedAt stay out of the history, including
+// views still queued when it was removed
+type HistoryRemoval struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_history_removal_user_cartoon" json:"user_id"`
+	CartoonID uint      `gorm:"not null;uniqueIndex:idx_history_removal_user_cartoon" json:"cartoon_id"`
+	RemovedAt time.Time `gorm:"not null" json:"removed_at"`
+
+	// Foreign key relationships
+	User    User    `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Cartoon Cartoon `gorm:"foreignKey:CartoonID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// Table naming manually
+func (HistoryRemoval) TableName() string {
+	return "history_removals"
+}
+
+// TrendingScore Table (materialised engagement score per cartoon and time window)
+type TrendingScore struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Window     string    `gorm:"column:time_window;type:varchar(10);not null;uniqueIndex:idx_trending_window_cartoon" json:"window"`
+	CartoonID  uint      `gorm:"not null;uniqueIndex:idx_trending_window_cartoon" json:"cartoon_id"`
+	Score      float64   `gorm:"type:double precision;not null;index" json:"score"`
+	Views      int64     `gorm:"not null;default:0" json:"views"`
+	Favourites int64     `gorm:"not null;default:0" json:"favourites"`
+	Ratings    int64     `gorm:"not null;default:0" json:"ratings"`
+	ComputedAt time.Time `gorm:"not null" json:"computed_at"`
+
+	// Foreign key relationship
+	Cartoon Cartoon `gorm:"foreignKey:CartoonID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// Table naming manually
+func (TrendingScore) TableName() string {
+	return "trending_scores"
+}
+
+// DeadLetterJob Table (worker pool jobs that failed after all retries)
+type DeadLetterJob struct {
+	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	JobType    string     `gorm:"type:varchar(50);not null;index" json:"job_type"`
+	JobID      string     `gorm:"type:varchar(64);not null;index" json:"job_id"`
+	Payload    string     `gorm:"type:text;not null" json:"payload"`
+	Error      string     `gorm:"type:text;not null" json:"error"`
+	Attempts   int        `gorm:"not null" json:"attempts"`
+	FailedAt   time.Time  `gorm:"not null;index" json:"failed_at"`
+	ReplayedAt *time.Time `json:"replayed_at,omitempty"`
+}
+
+// Table naming manually
+func (DeadLetterJob) TableName() string {
+	return "dead_letter_jobs"
+}
+
+// ScheduledJobRun Table (runs of scheduled maintenance jobs)
+type ScheduledJobRun struct {
+	ID      uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	JobName string `gorm:"type:varchar(100);not null;index:idx_scheduled_job_runs_job_started" json:"job_name"`
+	// RunKey identifies a scheduled tick so replicas run it only once (NULL for manual and startup runs)
+	RunKey     *string    `gorm:"type:varchar(255);uniqueIndex" json:"-"`
+	Trigger    string     `gorm:"type:varchar(20);not null" json:"trigger"` // schedule, startup or manual
+	Instance   string     `gorm:"type:varchar(255);not null" json:"instance"`
+	Status     string     `gorm:"type:varchar(20);not null" json:"status"` // running, succeeded or failed
+	Error      string     `gorm:"type:text" json:"error,omitempty"`
+	StartedAt  time.Time  `gorm:"not null;index:idx_scheduled_job_runs_job_started" json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	DurationMs int64      `json:"duration_ms"`
+}
+
+// Table naming manually
+func (ScheduledJobRun) TableName() string {
+	return "scheduled_job_runs"
+}
+
+// SchedulerLock Table (leases electing the replica that runs a scheduled job when Redis is unavailable)
+type SchedulerLock struct {
+	Name      string    `gorm:"primaryKey;type:varchar(100)" json:"name"`
+	Token     string    `gorm:"type:varchar(64);not null" json:"-"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+}
+
+// Table naming manually
+func (SchedulerLock) TableName() string {
+	return "scheduler_locks"
+}
+
 // AdminLog Table
 type AdminLog struct {
 	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
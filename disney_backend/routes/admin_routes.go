@@ -3,6 +3,7 @@ package routes
 import (
 	"disney/handlers"
 	"disney/middleware"
+	"disney/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,40 +13,75 @@ const (
 )
 
 // SetupAdminRoutes sets up all admin routes
-func SetupAdminRoutes(router *gin.RouterGroup) {
+func SetupAdminRoutes(router *gin.RouterGroup, tokens *utils.TokenIssuer) {
 	// Routes accessible by all authenticated users (both users and admins)
 	authenticated := router.Group("")
-	authenticated.Use(middleware.AuthRequired())
+	authenticated.Use(middleware.AuthRequired(tokens))
 	{
-		// Get all cartoon names
-		authenticated.GET("/cartoons/names", handlers.GetAllCartoonNames)
+		// Catalogue reads are the same for every user, so the browser may keep
+		// them and revalidate them with their ETag; they need authentication,
+		// so shared caches must not store them
+		// Browsers answer repeat reads from their cache, so these handlers must
+		// have no side effects
+		catalogue := authenticated.Group("")
+		catalogue.Use(middleware.CachePolicy(middleware.CachePrivate))
+		{
+			// Get all cartoon names
+			catalogue.GET("/cartoons/names", handlers.GetAllCartoonNames)
 
-		// Get top 5 trending cartoons by IMDb rating
-		authenticated.GET("/cartoons/trending", handlers.GetTrendingCartoons)
+			// Get trending cartoons by engagement (?window=24h|7d|30d)
+			catalogue.GET("/cartoons/trending", handlers.GetTrendingCartoons)
 
-		// Get specific cartoon by ID
-		authenticated.GET(cartoonsByIDPath, handlers.GetCartoonByID)
+			// Get specific cartoon by ID (views are recorded with POST /user/views)
+			catalogue.GET(cartoonsByIDPath, handlers.GetCartoonByID)
 
-		// Get cartoons by filters
-		authenticated.GET("/cartoons/by-character", handlers.GetCartoonsByCharacter)
-		authenticated.GET("/cartoons/by-genre", handlers.GetCartoonsByGenre)
-		authenticated.GET("/cartoons/by-year", handlers.GetCartoonsByYear)
-		authenticated.GET("/cartoons/by-age-group", handlers.GetCartoonsByAgeGroup)
+			// Get external metadata (plot, runtime, IMDb rating, awards) for a cartoon
+			catalogue.GET(cartoonsByIDPath+"/metadata", handlers.GetCartoonMetadata)
 
-		// Get recently viewed cartoons
-		authenticated.GET("/recently-viewed", handlers.GetRecentlyViewed)
+			// Get "more like this" cartoons
+			catalogue.GET(cartoonsByIDPath+"/related", handlers.GetRelatedCartoons)
+
+			// Get cartoons by filters
+			catalogue.GET("/cartoons/by-character", handlers.GetCartoonsByCharacter)
+			catalogue.GET("/cartoons/by-genre", handlers.GetCartoonsByGenre)
+			catalogue.GET("/cartoons/by-year", handlers.GetCartoonsByYear)
+			catalogue.GET("/cartoons/by-age-group", handlers.GetCartoonsByAgeGroup)
+
+			// Browse public watchlists and editorial collections
+			catalogue.GET("/watchlists/public", handlers.GetPublicWatchlists)
+			catalogue.GET("/collections", handlers.GetCollections)
+		}
+
+		// Get recently viewed cartoons (personal, never cached)
+		authenticated.GET("/recently-viewed", middleware.CachePolicy(middleware.CacheNoStore), handlers.GetRecentlyViewed)
+
+		// Remove one cartoon from, or clear, the recently viewed history (view counts are kept)
+		authenticated.DELETE("/recently-viewed/:cartoon_id", handlers.RemoveRecentlyViewed)
+		authenticated.DELETE("/recently-viewed", handlers.ClearRecentlyViewed)
+
+		// Pause or resume recording the recently viewed history
+		authenticated.PUT("/recently-viewed/settings", handlers.UpdateRecentlyViewedSettings)
 	}
 
 	// Routes accessible only by admins
 	admin := router.Group("")
-	admin.Use(middleware.AuthRequired(), middleware.AdminOnly())
+	admin.Use(middleware.AuthRequired(tokens), middleware.AdminOnly(), middleware.CachePolicy(middleware.CacheNoStore))
 	{
 		// Create new cartoon with characters
 		admin.POST("/cartoons", handlers.CreateCartoon)
 
+		// Import a cartoon from the metadata provider by title or IMDb ID (draft, or created directly)
+		admin.POST("/cartoons/import", handlers.ImportCartoon)
+
 		// Update cartoon by ID
 		admin.PUT(cartoonsByIDPath, handlers.UpdateCartoon)
 
+		// Pin the exact IMDb ID of a cartoon with an ambiguous title
+		admin.PUT(cartoonsByIDPath+"/imdb", handlers.PinCartoonIMDbID)
+
+		// Metadata provider circuit breaker, quota and call counters
+		admin.GET("/metadata/status", handlers.GetMetadataProviderStatus)
+
 		// Delete cartoon by ID or title
 		admin.DELETE("/cartoons", handlers.DeleteCartoon)
 
@@ -55,11 +91,44 @@ func SetupAdminRoutes(router *gin.RouterGroup) {
 		admin.PUT("/characters/:id", handlers.UpdateCharacter)
 		admin.DELETE("/characters/:id", handlers.DeleteCharacter)
 
+		// Editorial collection management
+		admin.POST("/collections", handlers.CreateCollection)
+		admin.PUT("/collections/:id", handlers.UpdateCollection)
+		admin.DELETE("/collections/:id", handlers.DeleteCollection)
+		admin.PUT("/collections/:id/order", handlers.ReorderCollection)
+		admin.POST("/collections/:id/items", handlers.AddCollectionItem)
+		admin.PUT("/collections/:id/items/:cartoon_id", handlers.UpdateCollectionItem)
+		admin.DELETE("/collections/:id/items/:cartoon_id", handlers.RemoveCollectionItem)
+
 		// Admin logs management
 		admin.POST("/logs", handlers.CreateAdminLog)
 		admin.GET("/logs", handlers.GetAdminLogs)
 		admin.GET("/logs/stats", handlers.GetAdminLogStats)
 
+		// Review moderation
+		admin.GET("/reviews/moderation", handlers.GetModerationQueue)
+		admin.PUT("/reviews/:id/hide", handlers.HideReview)
+		admin.PUT("/reviews/:id/restore", handlers.RestoreReview)
+		admin.PUT("/reviews/:id/approve", handlers.ApproveReview)
+
+		// Dead-letter store for worker pool jobs that failed after all retries
+		admin.GET("/jobs/dead-letter", handlers.GetDeadLetterJobs)
+		admin.GET("/jobs/dead-letter/:id", handlers.GetDeadLetterJob)
+		admin.POST("/jobs/dead-letter/:id/replay", handlers.ReplayDeadLetterJob)
+		admin.DELETE("/jobs/dead-letter/:id", handlers.PurgeDeadLetterJob)
+		admin.DELETE("/jobs/dead-letter", handlers.PurgeDeadLetterJobs)
+
+		// Catalogue response cache hit rates on this replica
+		admin.GET("/cache/stats", handlers.GetResponseCacheStats)
+
+		// Worker pool queue lengths and view batch counters
+		admin.GET("/workers/stats", handlers.GetWorkerStats)
+
+		// Scheduled maintenance jobs
+		admin.GET("/scheduler/jobs", handlers.GetScheduledJobs)
+		admin.POST("/scheduler/jobs/:name/run", handlers.RunScheduledJob)
+		admin.GET("/scheduler/jobs/:name/runs", handlers.GetScheduledJobRuns)
+
 		// Request logs management
 		admin.GET("/request-logs", handlers.GetRequestLogs)
 		admin.GET("/request-logs/stats", handlers.GetRequestLogStats)
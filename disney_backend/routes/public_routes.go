@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"disney/handlers"
+	"disney/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PublicRoutes defines catalogue routes anyone may read (no authentication required)
+func PublicRoutes(router *gin.Engine) {
+	public := router.Group("/api/public")
+	// Moderation can hide a review at any time, so caches revalidate on every use
+	public.Use(middleware.CachePolicy(middleware.CacheRevalidate))
+	{
+		// Get visible written reviews for a cartoon (?page, ?page_size, ?sort=recent|helpful)
+		public.GET(cartoonsByIDPath+"/reviews", handlers.GetCartoonReviews)
+	}
+}
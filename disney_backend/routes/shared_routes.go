@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"disney/handlers"
+	"disney/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SharedRoutes defines routes for content shared by link (no authentication required)
+func SharedRoutes(router *gin.Engine) {
+	shared := router.Group("/api/shared")
+	// Anyone with the link may read these, but they change whenever the owner edits the list
+	shared.Use(middleware.CachePolicy(middleware.CacheRevalidate))
+	{
+		// Unlisted and public watchlists by share token
+		shared.GET("/watchlists/:token", handlers.GetSharedWatchlist)
+	}
+}
@@ -3,25 +3,56 @@ package routes
 import (
 	"disney/handlers"
 	"disney/middleware"
+	"disney/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 // UserRoutes defines all user-specific routes
-func UserRoutes(router *gin.Engine) {
+func UserRoutes(router *gin.Engine, tokens *utils.TokenIssuer) {
 	// Protected User routes (authentication required, not admin)
 	user := router.Group("/api/user")
-	user.Use(middleware.AuthRequired())
+	// Personal data must not be kept by browsers or shared caches
+	user.Use(middleware.AuthRequired(tokens), middleware.CachePolicy(middleware.CacheNoStore))
 	{
 		// Favourites endpoints
 		user.POST("/favourites", handlers.AddFavourite)
 		user.GET("/favourites", handlers.GetUserFavourites)
 		user.DELETE("/favourites/:cartoon_id", handlers.RemoveFavourite)
 
+		// Watchlist endpoints (named, ordered lists; item add/remove is processed asynchronously)
+		user.POST("/watchlists", handlers.CreateWatchlist)
+		user.GET("/watchlists", handlers.GetUserWatchlists)
+		user.GET("/watchlists/:id", handlers.GetWatchlist)
+		user.PUT("/watchlists/:id", handlers.UpdateWatchlist)
+		user.DELETE("/watchlists/:id", handlers.DeleteWatchlist)
+		user.POST("/watchlists/:id/share-token", handlers.RegenerateShareToken)
+		user.PUT("/watchlists/:id/order", handlers.ReorderWatchlist)
+		user.POST("/watchlists/:id/items", handlers.AddWatchlistItem)
+		user.PUT("/watchlists/:id/items/:cartoon_id", handlers.UpdateWatchlistItem)
+		user.DELETE("/watchlists/:id/items/:cartoon_id", handlers.RemoveWatchlistItem)
+
 		// Rating endpoints
+		user.GET("/ratings/scale", handlers.GetRatingScale)
 		user.POST("/ratings", handlers.AddRating)
 		user.GET("/ratings/:cartoon_id", handlers.GetUserRating)
 		user.PUT("/ratings/:cartoon_id", handlers.UpdateRating)
+		user.DELETE("/ratings/:cartoon_id", handlers.DeleteRating)
+		user.GET("/ratings/:cartoon_id/history", handlers.GetRatingHistory)
+
+		// Review feedback endpoints
+		user.POST("/reviews/:id/vote", handlers.VoteReview)
+		user.DELETE("/reviews/:id/vote", handlers.RemoveReviewVote)
+		user.POST("/reviews/:id/report", handlers.ReportReview)
+
+		// Personalised home feed (all home page rows in one response)
+		user.GET("/home", handlers.GetHomeFeed)
+
+		// Personalised recommendations
+		user.GET("/recommendations", handlers.GetRecommendations)
+
+		// Async job status (job IDs are returned by 202 responses)
+		user.GET("/jobs/:id", handlers.GetJobStatus)
 
 		// View/Tracking endpoints
 		user.POST("/views", handlers.RecordView)
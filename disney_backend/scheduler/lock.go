@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Locker elects a single runner for a job across replicas
+type Locker interface {
+	// TryLock takes the named lock without waiting; ok is false if another
+	// replica holds it. The lock must be released with unlock
+	TryLock(ctx context.Context, name string, ttl time.Duration) (unlock func(), ok bool, err error)
+}
+
+// NewLocker returns a Redis lock when Redis is available and a Postgres
+// lease otherwise
+// All replicas must use the same backend, so they should share Redis availability
+func NewLocker(client *redis.Client, db *sql.DB) Locker {
+	if client != nil {
+		return &RedisLocker{client: client}
+	}
+	return &PostgresLocker{db: db}
+}
+
+// redisUnlockScript deletes a lock only if it still holds this runner's token
+var redisUnlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// RedisLocker takes locks with SET NX and an expiry, so a crashed runner's lock frees itself
+type RedisLocker struct {
+	client *redis.Client
+}
+
+// TryLock sets scheduler:lock:<name> unless it exists; it expires after ttl
+func (l *RedisLocker) TryLock(ctx context.Context, name string, ttl time.Duration) (func(), bool, error) {
+	key := "scheduler:lock:" + name
+	token, err := newLockToken()
+	if err != nil {
+		return nil, false, err
+	}
+
+	ok, err := l.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	unlock := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		redisUnlockScript.Run(ctx, l.client, []string{key}, token)
+	}
+	return unlock, true, nil
+}
+
+// PostgresLocker takes leases in the scheduler_locks table (models.SchedulerLock)
+// Taking and releasing a lease are single statements, so no connection is held
+// while the job runs; like a Redis lock, a crashed runner's lease expires
+type PostgresLocker struct {
+	db *sql.DB
+}
+
+// postgresLockQuery takes the lease unless another runner holds an unexpired one
+// Expiry uses the database clock, so replicas' clocks need not agree
+const postgresLockQuery = `INSERT INTO scheduler_locks (name, token, expires_at)
+VALUES ($1, $2, now() + $3 * interval '1 millisecond')
+ON CONFLICT (name) DO UPDATE SET token = EXCLUDED.token, expires_at = EXCLUDED.expires_at
+WHERE scheduler_locks.expires_at <= now()`
+
+// TryLock takes the lease of the named lock; it expires after ttl
+func (l *PostgresLocker) TryLock(ctx context.Context, name string, ttl time.Duration) (func(), bool, error) {
+	token, err := newLockToken()
+	if err != nil {
+		return nil, false, err
+	}
+
+	result, err := l.db.ExecContext(ctx, postgresLockQuery, name, token, ttl.Milliseconds())
+	if err != nil {
+		return nil, false, err
+	}
+	if taken, err := result.RowsAffected(); err != nil || taken == 0 {
+		return nil, false, err
+	}
+
+	unlock := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		// Only this runner's lease is released; a failed release expires after ttl
+		if _, err := l.db.ExecContext(ctx, "DELETE FROM scheduler_locks WHERE name = $1 AND token = $2", name, token); err != nil {
+			log.Printf("WARNING: Failed to release scheduler lock %s, it expires in %s: %v\n", name, ttl, err)
+		}
+	}
+	return unlock, true, nil
+}
+
+// newLockToken returns a random token identifying one runner's lock
+func newLockToken() (string, error) {
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(tokenBytes), nil
+}
@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisLockerElectsOneRunner(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	locker := NewLocker(client, nil)
+	ctx := context.Background()
+
+	unlock, ok, err := locker.TryLock(ctx, "cleanup", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("TryLock = %v, %v", ok, err)
+	}
+	if _, ok, err := locker.TryLock(ctx, "cleanup", time.Minute); err != nil || ok {
+		t.Fatalf("second TryLock = %v, %v, want the lock refused", ok, err)
+	}
+	if _, ok, _ := locker.TryLock(ctx, "other", time.Minute); !ok {
+		t.Error("lock on another job refused")
+	}
+	unlock()
+	if server.Exists("scheduler:lock:cleanup") {
+		t.Error("unlock left the lock in place")
+	}
+
+	// An expired lock can be taken by another replica, and the late unlock must not free it
+	late, _, _ := locker.TryLock(ctx, "cleanup", time.Minute)
+	server.FastForward(time.Minute)
+	if _, ok, _ := locker.TryLock(ctx, "cleanup", time.Minute); !ok {
+		t.Fatal("expired lock could not be taken")
+	}
+	late()
+	if !server.Exists("scheduler:lock:cleanup") {
+		t.Error("late unlock freed another runner's lock")
+	}
+}
+
+// sameToken matches the token of the first statement it sees in later ones
+type sameToken struct {
+	token *string
+}
+
+func (a sameToken) Match(v driver.Value) bool {
+	token, ok := v.(string)
+	if *a.token == "" {
+		*a.token = token
+	}
+	return ok && token == *a.token
+}
+
+func TestPostgresLockerTakesLeases(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	locker := NewLocker(nil, db)
+
+	var token string
+	mock.ExpectExec(`INSERT INTO scheduler_locks .* ON CONFLICT \(name\) DO UPDATE .* WHERE scheduler_locks.expires_at <= now\(\)`).
+		WithArgs("cleanup", sameToken{&token}, int64(60000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM scheduler_locks WHERE name = \$1 AND token = \$2`).WithArgs("cleanup", sameToken{&token}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Another runner holds an unexpired lease
+	mock.ExpectExec(`INSERT INTO scheduler_locks`).WithArgs("cleanup", sqlmock.AnyArg(), int64(60000)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	unlock, ok, err := locker.TryLock(context.Background(), "cleanup", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("TryLock = %v, %v", ok, err)
+	}
+	unlock()
+	if _, ok, err := locker.TryLock(context.Background(), "cleanup", time.Minute); err != nil || ok {
+		t.Errorf("TryLock while held elsewhere = %v, %v", ok, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
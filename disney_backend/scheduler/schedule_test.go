@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRejectsInvalidSpecs(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-x * * * *",
+		"@fortnightly",
+		"@every soon",
+		"@every 500ms",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// Saturday 14 March 2026
+	from := time.Date(2026, 3, 14, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"*/15 * * * *", from, time.Date(2026, 3, 14, 10, 15, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 14, 10, 15, 0, 0, time.UTC), time.Date(2026, 3, 14, 10, 30, 0, 0, time.UTC)},
+		{"5/20 * * * *", from, time.Date(2026, 3, 14, 10, 25, 0, 0, time.UTC)},
+		{"0,45 * * * *", from, time.Date(2026, 3, 14, 10, 45, 0, 0, time.UTC)},
+		{"@hourly", from, time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{"@daily", from, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", from, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", from, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 2 * * 1-5", from, time.Date(2026, 3, 16, 2, 30, 0, 0, time.UTC)},
+		// 7 and 0 are both Sunday
+		{"0 0 * * 7", from, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		// With both day fields restricted, either one matching is enough
+		{"0 9 13 * 5", from, time.Date(2026, 3, 20, 9, 0, 0, 0, time.UTC)},
+		{"0 9 15 * 5", from, time.Date(2026, 3, 15, 9, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", from, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@every 10m", from, time.Date(2026, 3, 14, 10, 10, 0, 0, time.UTC)},
+		{"@every 1h", from, time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		schedule, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.spec, err)
+		}
+		if got := schedule.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q after %s: got %s, want %s", tt.spec, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleNextNeverMatching(t *testing.T) {
+	schedule, err := Parse("0 0 31 2 *")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if next := schedule.Next(time.Now()); !next.IsZero() {
+		t.Errorf("31 February scheduled at %s, want never", next)
+	}
+}
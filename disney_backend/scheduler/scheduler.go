@@ -0,0 +1,343 @@
+package scheduler
+
+import (
+	"context"
+	"disney/database"
+	"disney/models"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// Run statuses and triggers recorded in ScheduledJobRun
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+
+	TriggerSchedule = "schedule"
+	TriggerStartup  = "startup"
+	TriggerManual   = "manual"
+)
+
+var (
+	// ErrUnknownJob is returned for job names that were never registered
+	ErrUnknownJob = errors.New("unknown scheduled job")
+	// ErrJobRunning is returned when the job is already running
+	ErrJobRunning = errors.New("job is already running")
+)
+
+// Job is a periodic maintenance task
+type Job struct {
+	// Name identifies the job in logs, locks and the admin API
+	Name string
+	// Spec is a cron expression, descriptor or "@every <duration>" (see Parse)
+	Spec string
+	// Timeout bounds one run (default 10m); locks expire after it
+	Timeout time.Duration
+	// PerInstance jobs run on every replica (e.g. to refresh in-memory state);
+	// other jobs run on one replica per scheduled time
+	PerInstance bool
+	// RunOnStart also runs the job when the scheduler starts
+	RunOnStart bool
+	// Run does the work; it should stop when ctx ends
+	Run func(ctx context.Context) error
+}
+
+// entry is a registered job with its schedule and local state
+type entry struct {
+	job      Job
+	schedule Schedule
+
+	mu      sync.Mutex
+	running bool
+	nextRun time.Time
+}
+
+// JobInfo describes a registered job for the admin API
+type JobInfo struct {
+	Name        string                  `json:"name"`
+	Spec        string                  `json:"spec"`
+	PerInstance bool                    `json:"per_instance"`
+	Running     bool                    `json:"running"`
+	NextRun     time.Time               `json:"next_run"`
+	LastRun     *models.ScheduledJobRun `json:"last_run,omitempty"`
+}
+
+// Scheduler runs registered jobs on their schedules
+// Each job has its own goroutine; a job never overlaps itself on one replica,
+// and jobs that are not PerInstance are guarded by a Locker across replicas
+type Scheduler struct {
+	locker   Locker
+	instance string
+
+	mu      sync.Mutex
+	entries map[string]*entry
+
+	// ctx stops scheduling; jobCtx is passed to runs and only cancelled when Stop gives up waiting
+	ctx        context.Context
+	stop       context.CancelFunc
+	jobCtx     context.Context
+	cancelJobs context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// New creates a scheduler; register jobs, then call Start
+func New(locker Locker) *Scheduler {
+	ctx, stop := context.WithCancel(context.Background())
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	return &Scheduler{
+		locker:     locker,
+		instance:   instanceName(),
+		entries:    map[string]*entry{},
+		ctx:        ctx,
+		stop:       stop,
+		jobCtx:     jobCtx,
+		cancelJobs: cancelJobs,
+	}
+}
+
+// instanceName identifies this replica in recorded runs
+func instanceName() string {
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return fmt.Sprintf("%s:%d", host, os.Getpid())
+	}
+	return fmt.Sprintf("pid:%d", os.Getpid())
+}
+
+// Register adds a job; it fails for invalid specs and duplicate names
+func (s *Scheduler) Register(job Job) error {
+	schedule, err := Parse(job.Spec)
+	if err != nil {
+		return err
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = 10 * time.Minute
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.entries[job.Name]; exists {
+		return fmt.Errorf("scheduled job %q is already registered", job.Name)
+	}
+	s.entries[job.Name] = &entry{job: job, schedule: schedule}
+	return nil
+}
+
+// MustRegister registers a job and exits on error, for jobs registered at startup
+func (s *Scheduler) MustRegister(job Job) {
+	if err := s.Register(job); err != nil {
+		log.Fatalf("Failed to register scheduled job %s: %v", job.Name, err)
+	}
+}
+
+// Start launches one goroutine per registered job
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	log.Printf("Starting scheduler with %d jobs\n", len(s.entries))
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go s.loop(e)
+	}
+}
+
+// loop runs a job at each scheduled time until the scheduler stops
+func (s *Scheduler) loop(e *entry) {
+	defer s.wg.Done()
+
+	if e.job.RunOnStart {
+		s.run(e, TriggerStartup, time.Time{})
+	}
+
+	for {
+		next := e.schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Printf("Scheduled job %s never runs again, stopping it\n", e.job.Name)
+			return
+		}
+		e.mu.Lock()
+		e.nextRun = next
+		e.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			s.run(e, TriggerSchedule, next)
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// run executes a job once unless it is already running
+// Scheduled runs of jobs that are not PerInstance take the cluster lock and
+// claim the scheduled time, so only one replica runs each tick
+func (s *Scheduler) run(e *entry, trigger string, scheduledAt time.Time) error {
+	e.mu.Lock()
+	if e.running {
+		e.mu.Unlock()
+		return ErrJobRunning
+	}
+	e.running = true
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		e.running = false
+		e.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(s.jobCtx, e.job.Timeout)
+	defer cancel()
+
+	if !e.job.PerInstance {
+		unlock, ok, err := s.locker.TryLock(ctx, e.job.Name, e.job.Timeout)
+		if err != nil {
+			log.Printf("WARNING: Scheduled job %s skipped, could not take its lock: %v\n", e.job.Name, err)
+			return err
+		}
+		if !ok {
+			return ErrJobRunning
+		}
+		defer unlock()
+	}
+
+	run := models.ScheduledJobRun{
+		JobName:   e.job.Name,
+		Trigger:   trigger,
+		Instance:  s.instance,
+		Status:    StatusRunning,
+		StartedAt: time.Now(),
+	}
+	if trigger == TriggerSchedule {
+		key := fmt.Sprintf("%s@%d", e.job.Name, scheduledAt.Unix())
+		if e.job.PerInstance {
+			key += "@" + s.instance
+		}
+		run.RunKey = &key
+	}
+
+	// A scheduled tick another replica already ran inserts nothing
+	result := database.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&run)
+	if result.Error != nil {
+		log.Printf("WARNING: Failed to record run of scheduled job %s: %v\n", e.job.Name, result.Error)
+	} else if result.RowsAffected == 0 {
+		return nil
+	}
+
+	err := e.job.Run(ctx)
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.DurationMs = finished.Sub(run.StartedAt).Milliseconds()
+	run.Status = StatusSucceeded
+	if err != nil {
+		run.Status = StatusFailed
+		run.Error = err.Error()
+		log.Printf("WARNING: Scheduled job %s failed after %dms: %v\n", e.job.Name, run.DurationMs, err)
+	} else {
+		log.Printf("Scheduled job %s succeeded in %dms\n", e.job.Name, run.DurationMs)
+	}
+
+	if run.ID != 0 {
+		// The job's context may have ended; record the outcome regardless
+		saveCtx, cancelSave := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelSave()
+		if saveErr := database.DB.WithContext(saveCtx).Save(&run).Error; saveErr != nil {
+			log.Printf("WARNING: Failed to record outcome of scheduled job %s: %v\n", e.job.Name, saveErr)
+		}
+	}
+	return err
+}
+
+// RunNow starts a job immediately in the background
+// It fails with ErrUnknownJob, or ErrJobRunning if this replica is running it;
+// a run already in progress on another replica makes the manual run a no-op
+func (s *Scheduler) RunNow(name string) error {
+	s.mu.Lock()
+	e, ok := s.entries[name]
+	s.mu.Unlock()
+	if !ok {
+		return ErrUnknownJob
+	}
+	if s.ctx.Err() != nil {
+		return errors.New("scheduler is stopped")
+	}
+
+	e.mu.Lock()
+	running := e.running
+	e.mu.Unlock()
+	if running {
+		return ErrJobRunning
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := s.run(e, TriggerManual, time.Time{}); errors.Is(err, ErrJobRunning) {
+			log.Printf("Manual run of scheduled job %s skipped, it is already running\n", name)
+		}
+	}()
+	return nil
+}
+
+// Jobs describes the registered jobs, sorted by name, with their latest recorded run
+func (s *Scheduler) Jobs(ctx context.Context) []JobInfo {
+	s.mu.Lock()
+	infos := make([]JobInfo, 0, len(s.entries))
+	for _, e := range s.entries {
+		e.mu.Lock()
+		infos = append(infos, JobInfo{
+			Name:        e.job.Name,
+			Spec:        e.job.Spec,
+			PerInstance: e.job.PerInstance,
+			Running:     e.running,
+			NextRun:     e.nextRun,
+		})
+		e.mu.Unlock()
+	}
+	s.mu.Unlock()
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	// The latest run may have happened on another replica, so it comes from the database
+	for i := range infos {
+		var last models.ScheduledJobRun
+		if err := database.DB.WithContext(ctx).Where("job_name = ?", infos[i].Name).
+			Order("started_at DESC").Limit(1).Find(&last).Error; err == nil && last.ID != 0 {
+			infos[i].LastRun = &last
+		}
+	}
+	return infos
+}
+
+// Stop stops scheduling and waits for running jobs until ctx ends, then cancels them
+// Cancelled jobs still record their outcome
+func (s *Scheduler) Stop(ctx context.Context) error {
+	log.Println("Stopping scheduler...")
+	s.stop()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.cancelJobs()
+		<-done
+		err = ctx.Err()
+	}
+	s.cancelJobs()
+	return err
+}
@@ -0,0 +1,190 @@
+package scheduler
+
+import (
+	"context"
+	"disney/database"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// mockDB points database.DB at a sqlmock connection for the test
+func mockDB(t *testing.T) sqlmock.Sqlmock {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = previous
+		sqlDB.Close()
+	})
+	return mock
+}
+
+// fakeLocker grants its lock unless held is set
+type fakeLocker struct {
+	held     bool
+	unlocked int
+}
+
+func (l *fakeLocker) TryLock(context.Context, string, time.Duration) (func(), bool, error) {
+	if l.held {
+		return nil, false, nil
+	}
+	return func() { l.unlocked++ }, true, nil
+}
+
+// expectRecordedRun expects a run to be inserted and its outcome saved
+func expectRecordedRun(mock sqlmock.Sqlmock, trigger, status, errText string) {
+	mock.ExpectQuery(`INSERT INTO "scheduled_job_runs" .* ON CONFLICT DO NOTHING RETURNING "id"`).
+		WithArgs("cleanup", sqlmock.AnyArg(), trigger, sqlmock.AnyArg(), StatusRunning, "", sqlmock.AnyArg(), nil, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(`UPDATE "scheduled_job_runs" SET`).
+		WithArgs("cleanup", sqlmock.AnyArg(), trigger, sqlmock.AnyArg(), status, errText,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+// register adds a job named cleanup and returns its entry
+func register(t *testing.T, s *Scheduler, job Job) *entry {
+	t.Helper()
+	job.Name = "cleanup"
+	if job.Spec == "" {
+		job.Spec = "@hourly"
+	}
+	if err := s.Register(job); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	return s.entries["cleanup"]
+}
+
+func TestRegisterRejectsInvalidAndDuplicateJobs(t *testing.T) {
+	s := New(&fakeLocker{})
+	noop := func(context.Context) error { return nil }
+	if err := s.Register(Job{Name: "cleanup", Spec: "every hour", Run: noop}); err == nil {
+		t.Error("invalid spec accepted")
+	}
+	e := register(t, s, Job{Run: noop})
+	if e.job.Timeout != 10*time.Minute {
+		t.Errorf("default timeout %s, want 10m", e.job.Timeout)
+	}
+	if err := s.Register(Job{Name: "cleanup", Spec: "@daily", Run: noop}); err == nil {
+		t.Error("duplicate job accepted")
+	}
+}
+
+func TestRunRecordsOutcome(t *testing.T) {
+	mock := mockDB(t)
+	locker := &fakeLocker{}
+	s := New(locker)
+	e := register(t, s, Job{Run: func(context.Context) error { return errors.New("disk full") }})
+
+	expectRecordedRun(mock, TriggerManual, StatusFailed, "disk full")
+	if err := s.run(e, TriggerManual, time.Time{}); err == nil || err.Error() != "disk full" {
+		t.Errorf("run: %v, want the job's error", err)
+	}
+	if locker.unlocked != 1 {
+		t.Errorf("lock released %d times, want 1", locker.unlocked)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRunSkipsTickClaimedByAnotherReplica(t *testing.T) {
+	mock := mockDB(t)
+	s := New(&fakeLocker{})
+	ran := false
+	e := register(t, s, Job{Run: func(context.Context) error { ran = true; return nil }})
+
+	tick := time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`INSERT INTO "scheduled_job_runs"`).
+		WithArgs("cleanup", "cleanup@1773482400", TriggerSchedule, sqlmock.AnyArg(), StatusRunning, "", sqlmock.AnyArg(), nil, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	if err := s.run(e, TriggerSchedule, tick); err != nil || ran {
+		t.Errorf("run = %v, ran %v; want the claimed tick skipped", err, ran)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRunSkipsWhileLockHeldElsewhere(t *testing.T) {
+	mockDB(t)
+	s := New(&fakeLocker{held: true})
+	ran := false
+	e := register(t, s, Job{Run: func(context.Context) error { ran = true; return nil }})
+
+	if err := s.run(e, TriggerManual, time.Time{}); !errors.Is(err, ErrJobRunning) || ran {
+		t.Errorf("run = %v, ran %v; want ErrJobRunning", err, ran)
+	}
+
+	// Per-instance jobs ignore the cluster lock
+	e.job.PerInstance = true
+	mock := mockDB(t)
+	expectRecordedRun(mock, TriggerManual, StatusSucceeded, "")
+	if err := s.run(e, TriggerManual, time.Time{}); err != nil || !ran {
+		t.Errorf("per-instance run = %v, ran %v", err, ran)
+	}
+}
+
+func TestRunNowRejectsUnknownAndRunningJobs(t *testing.T) {
+	s := New(&fakeLocker{})
+	e := register(t, s, Job{Run: func(context.Context) error { return nil }})
+
+	if err := s.RunNow("missing"); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("RunNow(missing) = %v, want ErrUnknownJob", err)
+	}
+	e.running = true
+	if err := s.RunNow("cleanup"); !errors.Is(err, ErrJobRunning) {
+		t.Errorf("RunNow while running = %v, want ErrJobRunning", err)
+	}
+	e.running = false
+	s.Stop(context.Background())
+	if err := s.RunNow("cleanup"); err == nil {
+		t.Error("RunNow after Stop succeeded")
+	}
+}
+
+func TestStopCancelsJobsAfterDeadline(t *testing.T) {
+	mock := mockDB(t)
+	s := New(&fakeLocker{})
+	started := make(chan struct{})
+	register(t, s, Job{Run: func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	expectRecordedRun(mock, TriggerManual, StatusFailed, context.Canceled.Error())
+
+	if err := s.RunNow("cleanup"); err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop: %v, want the deadline error", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"disney/cache"
+	"disney/config"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NewCache builds the cache selected by cfg.Backend:
+// "tiered" keeps a memory L1 in front of Redis, "redis" uses Redis only and
+// "memory" this process only
+// Without Redis configuration every backend is memory only; when Redis is down
+// it is retried every cfg.ReconnectInterval
+// The memory cache holds cfg.MemoryMaxEntries entries and, in tiered mode,
+// keeps copies of Redis values for cfg.L1TTL
+func NewCache(cfg config.CacheConfig, client *redis.Client, options *redis.Options) cache.Cache {
+	memory := cache.NewMemory(cfg.MemoryMaxEntries)
+	if cfg.Backend == "memory" {
+		return memory
+	}
+
+	remote := NewRedisCache(cfg, client, options)
+	if remote == nil {
+		log.Println("WARNING: Redis not configured, caching in memory only")
+		return memory
+	}
+	if cfg.Backend == "redis" {
+		return remote
+	}
+	return cache.NewTiered(memory, remote, time.Duration(cfg.L1TTL))
+}
+
+// NewRedisCache creates a Redis cache retried every cfg.ReconnectInterval while
+// Redis is down; it returns nil if Redis is not configured
+func NewRedisCache(cfg config.CacheConfig, client *redis.Client, options *redis.Options) *cache.Redis {
+	if client == nil && options == nil {
+		return nil
+	}
+	return cache.NewRedis(client, options, time.Duration(cfg.ReconnectInterval))
+}
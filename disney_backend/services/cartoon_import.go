@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"disney/database"
+	"disney/models"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrCartoonExists is returned when importing a cartoon that is already in the catalogue
+	ErrCartoonExists = errors.New("cartoon already exists")
+	// ErrImportLookup wraps the metadata provider's errors in DraftCartoonImport,
+	// so callers can tell them from database errors
+	ErrImportLookup = errors.New("metadata lookup failed")
+)
+
+// ratingAgeGroups maps content ratings (MPAA and US TV) to the label prefix of
+// the seeded age group they fit
+var ratingAgeGroups = map[string]string{
+	"TV-Y":     "Preschool",
+	"TV-Y7":    "Kids",
+	"TV-Y7-FV": "Kids",
+	"G":        "Kids",
+	"TV-G":     "Kids",
+	"PG":       "Tweens",
+	"TV-PG":    "Tweens",
+	"PG-13":    "Teens",
+	"TV-14":    "Teens",
+	"R":        "Adults",
+	"NC-17":    "Adults",
+	"TV-MA":    "Adults",
+}
+
+// CartoonImport is a cartoon pre-filled from provider metadata, for review before it is created
+type CartoonImport struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	PosterURL   string `json:"poster_url"`
+	ReleaseYear int    `json:"release_year"`
+
+	// GenreID is the first provider genre matching an existing genre; when none
+	// matches and new genres are allowed, GenreName is created on import (NewGenre)
+	GenreID        uint     `json:"genre_id,omitempty"`
+	GenreName      string   `json:"genre_name,omitempty"`
+	NewGenre       bool     `json:"new_genre"`
+	ProviderGenres []string `json:"provider_genres"`
+
+	// AgeGroupID is inferred from the content rating, if it is a known one
+	AgeGroupID    uint   `json:"age_group_id,omitempty"`
+	AgeGroupLabel string `json:"age_group_label,omitempty"`
+	Rated         string `json:"rated,omitempty"`
+
+	IMDbID     string   `json:"imdb_id,omitempty"`
+	IMDbRating *float64 `json:"imdb_rating,omitempty"`
+
+	// ExistingCartoonID is set when the catalogue already has this cartoon
+	ExistingCartoonID uint `json:"existing_cartoon_id,omitempty"`
+}
+
+// DraftCartoonImport looks a title up with the provider and maps the answer
+// onto the catalogue's genres and age groups; nothing is written
+func DraftCartoonImport(ctx context.Context, provider MetadataProvider, query MetadataQuery, allowNewGenres bool) (*CartoonImport, error) {
+	metadata, err := provider.Lookup(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrImportLookup, err)
+	}
+
+	draft := &CartoonImport{
+		Title:          metadata.Title,
+		Description:    metadata.Plot,
+		PosterURL:      metadata.PosterURL,
+		ReleaseYear:    metadataYear(metadata.Year),
+		ProviderGenres: metadata.Genres,
+		Rated:          metadata.Rated,
+		IMDbID:         metadata.IMDbID(),
+	}
+	if rating, err := strconv.ParseFloat(metadata.IMDbRating, 64); err == nil {
+		draft.IMDbRating = &rating
+	}
+	if draft.ProviderGenres == nil {
+		draft.ProviderGenres = []string{}
+	}
+
+	if err := matchImportGenre(ctx, draft, allowNewGenres); err != nil {
+		return nil, err
+	}
+	if err := matchImportAgeGroup(ctx, draft); err != nil {
+		return nil, err
+	}
+	if draft.ExistingCartoonID, err = findImportedCartoon(ctx, draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// metadataYear reads the first year of "1987", "1987–1990" or "2017–"
+func metadataYear(year string) int {
+	if len(year) < 4 {
+		return 0
+	}
+	n, err := strconv.Atoi(year[:4])
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// matchImportGenre picks the first provider genre that exists (case-insensitive)
+func matchImportGenre(ctx context.Context, draft *CartoonImport, allowNewGenres bool) error {
+	var genres []models.Genre
+	if err := database.DB.WithContext(ctx).Find(&genres).Error; err != nil {
+		return err
+	}
+	byName := make(map[string]models.Genre, len(genres))
+	for _, genre := range genres {
+		byName[strings.ToLower(genre.Name)] = genre
+	}
+
+	for _, name := range draft.ProviderGenres {
+		if genre, ok := byName[strings.ToLower(name)]; ok {
+			draft.GenreID = genre.ID
+			draft.GenreName = genre.Name
+			return nil
+		}
+	}
+	if allowNewGenres && len(draft.ProviderGenres) > 0 {
+		draft.GenreName = draft.ProviderGenres[0]
+		draft.NewGenre = true
+	}
+	return nil
+}
+
+// matchImportAgeGroup infers the age group from the content rating
+func matchImportAgeGroup(ctx context.Context, draft *CartoonImport) error {
+	prefix, ok := ratingAgeGroups[strings.ToUpper(strings.TrimSpace(draft.Rated))]
+	if !ok {
+		return nil
+	}
+
+	var ageGroup models.AgeGroup
+	err := database.DB.WithContext(ctx).Where("label ILIKE ?", prefix+"%").Order("id").First(&ageGroup).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	draft.AgeGroupID = ageGroup.ID
+	draft.AgeGroupLabel = ageGroup.Label
+	return nil
+}
+
+// findImportedCartoon returns the ID of a cartoon with the draft's IMDb ID, or
+// else the same title and release year, if there is one
+func findImportedCartoon(ctx context.Context, draft *CartoonImport) (uint, error) {
+	query := database.DB.WithContext(ctx).Model(&models.Cartoon{}).Select("id")
+	if draft.IMDbID != "" {
+		query = query.Where("imdb_id = ? OR (LOWER(title) = LOWER(?) AND release_year = ?)", draft.IMDbID, draft.Title, draft.ReleaseYear)
+	} else {
+		query = query.Where("LOWER(title) = LOWER(?) AND release_year = ?", draft.Title, draft.ReleaseYear)
+	}
+
+	var ids []uint
+	if err := query.Limit(1).Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return ids[0], nil
+}
+
+// CreateImportedCartoon creates the cartoon described by a reviewed draft,
+// creating its genre first if it is new
+// The draft must have an age group, and a genre ID or a new genre name
+func CreateImportedCartoon(ctx context.Context, draft *CartoonImport, isFeatured bool) (*models.Cartoon, error) {
+	if draft.ExistingCartoonID != 0 {
+		return nil, ErrCartoonExists
+	}
+
+	cartoon := models.Cartoon{
+		Title:        draft.Title,
+		Description:  draft.Description,
+		PosterURL:    draft.PosterURL,
+		ReleaseYear:  draft.ReleaseYear,
+		GenreID:      draft.GenreID,
+		AgeGroupID:   draft.AgeGroupID,
+		IsFeatured:   isFeatured,
+		IMDbID:       draft.IMDbID,
+		IMDbIDPinned: draft.IMDbID != "",
+		IMDbRating:   draft.IMDbRating,
+	}
+	if draft.IMDbID != "" {
+		// The rating came with the import, so the background refresh can wait
+		now := time.Now()
+		cartoon.IMDbRatingFetchedAt = &now
+	}
+
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if draft.NewGenre {
+			genre := models.Genre{Name: draft.GenreName}
+			// Another import may have created the genre since the draft was made
+			if err := tx.Where("LOWER(name) = LOWER(?)", draft.GenreName).FirstOrCreate(&genre).Error; err != nil {
+				return err
+			}
+			cartoon.GenreID = genre.ID
+		}
+		return tx.Create(&cartoon).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &cartoon, nil
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const (
+	genresQuery          = `SELECT \* FROM "genres"`
+	importAgeGroupQuery  = `SELECT \* FROM "age_groups" WHERE label ILIKE \$1 ORDER BY id`
+	importedCartoonQuery = `SELECT "id" FROM "cartoons" WHERE`
+)
+
+func TestMetadataYear(t *testing.T) {
+	for year, want := range map[string]int{
+		"1987":      1987,
+		"1987–1990": 1987,
+		"2017–":     2017,
+		"":          0,
+		"87":        0,
+		"N/A":       0,
+		"soon":      0,
+	} {
+		if got := metadataYear(year); got != want {
+			t.Errorf("metadataYear(%q) = %d, want %d", year, got, want)
+		}
+	}
+}
+
+func TestDraftCartoonImportMapsGenreAndAgeGroup(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(genresQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Comedy").AddRow(2, "Action"))
+	mock.ExpectQuery(importAgeGroupQuery).WithArgs("Kids%", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "label"}).AddRow(2, "Kids (7-12)"))
+	mock.ExpectQuery(importedCartoonQuery).WithArgs("tt0092345", "DuckTales", 1987, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	provider := &stubProvider{metadata: &Metadata{
+		Title:       "DuckTales",
+		Year:        "1987–1990",
+		Plot:        "Scrooge McDuck and his nephews",
+		Rated:       " tv-y7",
+		IMDbRating:  "8.1",
+		Genres:      []string{"Animation", "comedy"},
+		ExternalIDs: map[string]string{"imdb": "tt0092345"},
+	}}
+	draft, err := DraftCartoonImport(context.Background(), provider, MetadataQuery{Title: "DuckTales"}, true)
+	if err != nil {
+		t.Fatalf("DraftCartoonImport: %v", err)
+	}
+	if draft.GenreID != 1 || draft.GenreName != "Comedy" || draft.NewGenre {
+		t.Errorf("genre %d %q (new %v), want the existing Comedy genre", draft.GenreID, draft.GenreName, draft.NewGenre)
+	}
+	if draft.AgeGroupID != 2 || draft.ReleaseYear != 1987 || draft.IMDbRating == nil || *draft.IMDbRating != 8.1 {
+		t.Errorf("draft %+v", draft)
+	}
+	if draft.ExistingCartoonID != 0 {
+		t.Errorf("draft matched existing cartoon %d", draft.ExistingCartoonID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDraftCartoonImportNewGenreAndUnknownRating(t *testing.T) {
+	provider := &stubProvider{metadata: &Metadata{Title: "Gargoyles", Year: "1994", Rated: "Unrated", Genres: []string{"Animation", "Fantasy"}}}
+
+	for _, allowNewGenres := range []bool{false, true} {
+		mock, _ := mockDB(t)
+		mock.ExpectQuery(genresQuery).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Comedy"))
+		// An unknown rating infers no age group, so age groups are not queried
+		mock.ExpectQuery(importedCartoonQuery).WithArgs("Gargoyles", 1994, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8))
+
+		draft, err := DraftCartoonImport(context.Background(), provider, MetadataQuery{Title: "Gargoyles"}, allowNewGenres)
+		if err != nil {
+			t.Fatalf("DraftCartoonImport: %v", err)
+		}
+		wantName := ""
+		if allowNewGenres {
+			wantName = "Animation"
+		}
+		if draft.GenreID != 0 || draft.NewGenre != allowNewGenres || draft.GenreName != wantName {
+			t.Errorf("allow new genres %v: genre %d %q (new %v)", allowNewGenres, draft.GenreID, draft.GenreName, draft.NewGenre)
+		}
+		if draft.AgeGroupID != 0 || draft.ExistingCartoonID != 8 {
+			t.Errorf("draft %+v, want no age group and existing cartoon 8", draft)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestCreateImportedCartoonCreatesNewGenre(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "genres" WHERE LOWER\(name\) = LOWER\(\$1\)`).WithArgs("Animation", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+	mock.ExpectQuery(`INSERT INTO "genres"`).WithArgs("Animation").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectQuery(`INSERT INTO "cartoons"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+	mock.ExpectCommit()
+
+	draft := &CartoonImport{Title: "Gargoyles", ReleaseYear: 1994, GenreName: "Animation", NewGenre: true, AgeGroupID: 3, IMDbID: "tt0108783"}
+	cartoon, err := CreateImportedCartoon(context.Background(), draft, true)
+	if err != nil {
+		t.Fatalf("CreateImportedCartoon: %v", err)
+	}
+	if cartoon.ID != 12 || cartoon.GenreID != 5 || !cartoon.IsFeatured || !cartoon.IMDbIDPinned || cartoon.IMDbRatingFetchedAt == nil {
+		t.Errorf("cartoon %+v", cartoon)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCreateImportedCartoonRefusesExisting(t *testing.T) {
+	mock, _ := mockDB(t)
+	if _, err := CreateImportedCartoon(context.Background(), &CartoonImport{ExistingCartoonID: 8}, false); !errors.Is(err, ErrCartoonExists) {
+		t.Errorf("CreateImportedCartoon: %v, want ErrCartoonExists", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
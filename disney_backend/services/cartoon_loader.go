@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"disney/database"
+	"disney/models"
+)
+
+// LoadCartoons fetches cartoons by ID in one query, with genre and age group joined in
+// Cartoons come back in the order of ids; IDs that no longer exist are skipped
+// and repeated IDs are returned once, at their first position
+func LoadCartoons(ctx context.Context, ids []uint) ([]models.Cartoon, error) {
+	if len(ids) == 0 {
+		return []models.Cartoon{}, nil
+	}
+
+	var cartoons []models.Cartoon
+	if err := database.DB.WithContext(ctx).Joins("Genre").Joins("AgeGroup").
+		Where("cartoons.id IN ?", ids).Find(&cartoons).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]models.Cartoon, len(cartoons))
+	for _, cartoon := range cartoons {
+		byID[cartoon.ID] = cartoon
+	}
+
+	ordered := make([]models.Cartoon, 0, len(cartoons))
+	for _, id := range ids {
+		if cartoon, ok := byID[id]; ok {
+			ordered = append(ordered, cartoon)
+			delete(byID, id)
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"disney/database"
+	"disney/models"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// mockDB points database.DB at a sqlmock connection for the test
+// The returned counter holds the number of queries GORM ran
+func mockDB(t *testing.T) (sqlmock.Sqlmock, *int) {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+
+	queries := 0
+	count := func(*gorm.DB) { queries++ }
+	db.Callback().Query().After("gorm:query").Register("test:count_queries", count)
+	db.Callback().Row().After("gorm:row").Register("test:count_rows", count)
+
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = previous
+		sqlDB.Close()
+	})
+	return mock, &queries
+}
+
+// cartoonRows returns cartoon rows with their joined genre and age group, in the order given
+func cartoonRows(ids ...uint) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{
+		"id", "title", "genre_id", "age_group_id",
+		"Genre__id", "Genre__name", "AgeGroup__id", "AgeGroup__label",
+	})
+	for _, id := range ids {
+		rows.AddRow(id, "Cartoon", 1, 2, 1, "Comedy", 2, "7+")
+	}
+	return rows
+}
+
+const loadCartoonsQuery = `SELECT .* FROM "cartoons" LEFT JOIN "genres" "Genre" .* LEFT JOIN "age_groups" "AgeGroup" .* WHERE cartoons.id IN`
+
+func TestLoadCartoonsKeepsRequestedOrder(t *testing.T) {
+	mock, _ := mockDB(t)
+	// The database returns rows in its own order
+	mock.ExpectQuery(loadCartoonsQuery).WillReturnRows(cartoonRows(1, 2, 3))
+
+	cartoons, err := LoadCartoons(context.Background(), []uint{3, 1, 2})
+	if err != nil {
+		t.Fatalf("LoadCartoons: %v", err)
+	}
+	assertCartoonIDs(t, cartoons, 3, 1, 2)
+	if cartoons[0].Genre.Name != "Comedy" || cartoons[0].AgeGroup.Label != "7+" {
+		t.Errorf("genre and age group not joined in: %+v", cartoons[0])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLoadCartoonsSkipsMissingAndRepeatedIDs(t *testing.T) {
+	mock, _ := mockDB(t)
+	// Cartoon 4 was deleted
+	mock.ExpectQuery(loadCartoonsQuery).WillReturnRows(cartoonRows(5, 7))
+
+	cartoons, err := LoadCartoons(context.Background(), []uint{7, 4, 5, 7, 5})
+	if err != nil {
+		t.Fatalf("LoadCartoons: %v", err)
+	}
+	assertCartoonIDs(t, cartoons, 7, 5)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLoadCartoonsEmptyRunsNoQuery(t *testing.T) {
+	_, queries := mockDB(t)
+
+	cartoons, err := LoadCartoons(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("LoadCartoons: %v", err)
+	}
+	if cartoons == nil || len(cartoons) != 0 {
+		t.Errorf("got %v, want an empty slice", cartoons)
+	}
+	if *queries != 0 {
+		t.Errorf("ran %d queries, want 0", *queries)
+	}
+}
+
+func TestLoadCartoonsRunsOneQuery(t *testing.T) {
+	for _, n := range []int{1, 10, 100} {
+		mock, queries := mockDB(t)
+		ids := make([]uint, n)
+		for i := range ids {
+			ids[i] = uint(i + 1)
+		}
+		mock.ExpectQuery(loadCartoonsQuery).WillReturnRows(cartoonRows(ids...))
+
+		cartoons, err := LoadCartoons(context.Background(), ids)
+		if err != nil {
+			t.Fatalf("LoadCartoons(%d ids): %v", n, err)
+		}
+		if len(cartoons) != n {
+			t.Errorf("LoadCartoons(%d ids) returned %d cartoons", n, len(cartoons))
+		}
+		if *queries != 1 {
+			t.Errorf("LoadCartoons(%d ids) ran %d queries, want 1", n, *queries)
+		}
+	}
+}
+
+func assertCartoonIDs(t *testing.T, cartoons []models.Cartoon, want ...uint) {
+	t.Helper()
+	got := make([]uint, len(cartoons))
+	for i, cartoon := range cartoons {
+		got[i] = cartoon.ID
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got cartoons %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got cartoons %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// FixtureMetadataProvider serves metadata from a local JSON file, for development
+// and tests without network access
+// The file holds an array of Metadata objects; entries are matched by IMDb ID
+// (external_ids.imdb) or by case-insensitive title
+type FixtureMetadataProvider struct {
+	byIMDbID map[string]Metadata
+	byTitle  map[string]Metadata
+}
+
+// NewFixtureMetadataProvider loads the fixture file at path
+func NewFixtureMetadataProvider(path string) (*FixtureMetadataProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Metadata
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	p := &FixtureMetadataProvider{
+		byIMDbID: make(map[string]Metadata, len(entries)),
+		byTitle:  make(map[string]Metadata, len(entries)),
+	}
+	for _, entry := range entries {
+		entry.Source = p.Name()
+		if id := entry.IMDbID(); id != "" {
+			p.byIMDbID[id] = entry
+		}
+		p.byTitle[strings.ToLower(strings.TrimSpace(entry.Title))] = entry
+	}
+	return p, nil
+}
+
+// Name returns "fixture"
+func (p *FixtureMetadataProvider) Name() string {
+	return "fixture"
+}
+
+// Lookup finds the entry for the query's IMDb ID, or else its title
+func (p *FixtureMetadataProvider) Lookup(ctx context.Context, query MetadataQuery) (*Metadata, error) {
+	entry, ok := p.byIMDbID[query.IMDbID]
+	if !ok {
+		entry, ok = p.byTitle[strings.ToLower(strings.TrimSpace(query.Title))]
+	}
+	if !ok {
+		return nil, ErrMetadataNotFound
+	}
+	return &entry, nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"disney/config"
+	"disney/database"
+	"disney/models"
+	"errors"
+	"log"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// imdbIDPattern matches IMDb title IDs such as tt0092345
+var imdbIDPattern = regexp.MustCompile(`^tt\d{7,10}$`)
+
+// ValidIMDbID reports whether id looks like an IMDb title ID
+func ValidIMDbID(id string) bool {
+	return imdbIDPattern.MatchString(id)
+}
+
+// FormatIMDbRating formats a stored rating for responses ("N/A" if unknown)
+func FormatIMDbRating(rating *float64) string {
+	if rating == nil {
+		return "N/A"
+	}
+	return strconv.FormatFloat(*rating, 'f', 1, 64)
+}
+
+// RefreshCartoonIMDb looks up a cartoon's IMDb data and stores it on the record
+// Cartoons with an IMDb ID are looked up by ID, others by title and release year;
+// an ID found by title is kept so later lookups are exact
+func RefreshCartoonIMDb(ctx context.Context, provider MetadataProvider, cartoon *models.Cartoon) error {
+	metadata, err := provider.Lookup(ctx, imdbQuery(cartoon))
+	return storeIMDbMetadata(ctx, cartoon, metadata, err)
+}
+
+// imdbQuery looks a cartoon up by IMDb ID when known, else by title and release year
+func imdbQuery(cartoon *models.Cartoon) MetadataQuery {
+	if cartoon.IMDbID != "" {
+		return MetadataQuery{IMDbID: cartoon.IMDbID}
+	}
+	return MetadataQuery{Title: cartoon.Title, Year: cartoon.ReleaseYear}
+}
+
+// storeIMDbMetadata saves a lookup's outcome on the cartoon
+// Unknown titles are marked as fetched without a rating so they are not
+// retried until they go stale; other lookup errors are returned unchanged
+func storeIMDbMetadata(ctx context.Context, cartoon *models.Cartoon, metadata *Metadata, lookupErr error) error {
+	now := time.Now()
+	updates := map[string]interface{}{"imdb_rating_fetched_at": now}
+
+	switch {
+	case errors.Is(lookupErr, ErrMetadataNotFound):
+		updates["imdb_rating"] = nil
+		cartoon.IMDbRating = nil
+	case lookupErr != nil:
+		return lookupErr
+	default:
+		var rating *float64
+		if parsed, err := strconv.ParseFloat(metadata.IMDbRating, 64); err == nil {
+			rating = &parsed
+		}
+		updates["imdb_rating"] = rating
+		cartoon.IMDbRating = rating
+		if cartoon.IMDbID == "" && metadata.IMDbID() != "" {
+			updates["imdb_id"] = metadata.IMDbID()
+			cartoon.IMDbID = metadata.IMDbID()
+		}
+	}
+
+	cartoon.IMDbRatingFetchedAt = &now
+	// UpdateColumns leaves updated_at alone; this is not an editorial change
+	return database.DB.WithContext(ctx).Model(cartoon).UpdateColumns(updates).Error
+}
+
+// RefreshIMDbRatings refreshes cartoons whose IMDb rating was never fetched or
+// is older than cfg.RatingMaxAge, stalest first, up to cfg.RefreshBatchSize per run
+// Lookups run concurrently, bounded by cfg.RefreshConcurrency
+func RefreshIMDbRatings(ctx context.Context, provider MetadataProvider, cfg config.MetadataConfig) error {
+	var cartoons []models.Cartoon
+	if err := database.DB.WithContext(ctx).
+		Select("id", "title", "release_year", "imdb_id").
+		Where("imdb_rating_fetched_at IS NULL OR imdb_rating_fetched_at < ?", time.Now().Add(-time.Duration(cfg.RatingMaxAge))).
+		Order("imdb_rating_fetched_at ASC NULLS FIRST").Order("id").
+		Limit(cfg.RefreshBatchSize).
+		Find(&cartoons).Error; err != nil {
+		return err
+	}
+
+	queries := make([]MetadataQuery, len(cartoons))
+	for i := range cartoons {
+		queries[i] = imdbQuery(&cartoons[i])
+	}
+	results := LookupMany(ctx, provider, queries, cfg.RefreshConcurrency)
+
+	refreshed, failed, skipped := 0, 0, 0
+	// Cached details, lists and names show the IMDb rating
+	staleTags := []string{TagCartoonNames}
+	for i, result := range results {
+		err := storeIMDbMetadata(ctx, &cartoons[i], result.Metadata, result.Err)
+		switch {
+		case errors.Is(err, ErrMetadataUnavailable):
+			// Breaker open, quota spent or no API key; the next run retries
+			skipped++
+		case err != nil:
+			log.Printf("WARNING: IMDb refresh failed for cartoon %d (%s): %v", cartoons[i].ID, cartoons[i].Title, err)
+			failed++
+		default:
+			refreshed++
+			staleTags = append(staleTags, CartoonTag(cartoons[i].ID))
+		}
+	}
+	if refreshed > 0 {
+		InvalidateResponses(staleTags...)
+	}
+
+	log.Printf("IMDb refresh updated %d of %d stale cartoons (%d failed, %d skipped)", refreshed, len(cartoons), failed, skipped)
+	if failed > 0 && refreshed == 0 {
+		return errors.New("every IMDb lookup failed")
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"disney/models"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestValidIMDbID(t *testing.T) {
+	for id, want := range map[string]bool{
+		"tt0092345":     true,
+		"tt12345678":    true,
+		"tt009234":      false,
+		"0092345":       false,
+		"nm0092345":     false,
+		"tt0092345 ":    false,
+		"tt00923451234": false,
+	} {
+		if got := ValidIMDbID(id); got != want {
+			t.Errorf("ValidIMDbID(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestFormatIMDbRating(t *testing.T) {
+	rating := 8.14
+	if got := FormatIMDbRating(&rating); got != "8.1" {
+		t.Errorf("FormatIMDbRating(8.14) = %q", got)
+	}
+	if got := FormatIMDbRating(nil); got != "N/A" {
+		t.Errorf("FormatIMDbRating(nil) = %q, want N/A", got)
+	}
+}
+
+func TestRefreshCartoonIMDbStoresRatingAndFoundID(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectExec(`UPDATE "cartoons" SET "imdb_id"=\$1,"imdb_rating"=\$2,"imdb_rating_fetched_at"=\$3 WHERE "id" = \$4`).
+		WithArgs("tt0092345", 8.1, sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	provider := &stubProvider{metadata: &Metadata{IMDbRating: "8.1", ExternalIDs: map[string]string{"imdb": "tt0092345"}}}
+	cartoon := models.Cartoon{ID: 3, Title: "DuckTales", ReleaseYear: 1987}
+	if err := RefreshCartoonIMDb(context.Background(), provider, &cartoon); err != nil {
+		t.Fatalf("RefreshCartoonIMDb: %v", err)
+	}
+	if provider.queries[0] != (MetadataQuery{Title: "DuckTales", Year: 1987}) {
+		t.Errorf("looked up %+v, want title and year", provider.queries[0])
+	}
+	if cartoon.IMDbID != "tt0092345" || cartoon.IMDbRating == nil || *cartoon.IMDbRating != 8.1 || cartoon.IMDbRatingFetchedAt == nil {
+		t.Errorf("cartoon not updated: %+v", cartoon)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRefreshCartoonIMDbByPinnedID(t *testing.T) {
+	mock, _ := mockDB(t)
+	// A known ID is never replaced, and "N/A" ratings are stored as NULL
+	mock.ExpectExec(`UPDATE "cartoons" SET "imdb_rating"=\$1,"imdb_rating_fetched_at"=\$2 WHERE "id" = \$3`).
+		WithArgs(nil, sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	provider := &stubProvider{metadata: &Metadata{ExternalIDs: map[string]string{"imdb": "tt9999999"}}}
+	cartoon := models.Cartoon{ID: 3, Title: "DuckTales", IMDbID: "tt0092345", IMDbIDPinned: true}
+	if err := RefreshCartoonIMDb(context.Background(), provider, &cartoon); err != nil {
+		t.Fatalf("RefreshCartoonIMDb: %v", err)
+	}
+	if provider.queries[0] != (MetadataQuery{IMDbID: "tt0092345"}) || cartoon.IMDbID != "tt0092345" {
+		t.Errorf("looked up %+v and kept ID %q", provider.queries[0], cartoon.IMDbID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRefreshCartoonIMDbLookupFailures(t *testing.T) {
+	mock, _ := mockDB(t)
+	// Unknown titles are marked as fetched so they are not retried until stale
+	mock.ExpectExec(`UPDATE "cartoons" SET "imdb_rating"=\$1,"imdb_rating_fetched_at"=\$2 WHERE "id" = \$3`).
+		WithArgs(nil, sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	cartoon := models.Cartoon{ID: 3, Title: "Recess"}
+	if err := RefreshCartoonIMDb(context.Background(), &stubProvider{err: ErrMetadataNotFound}, &cartoon); err != nil {
+		t.Errorf("not found: %v, want it stored", err)
+	}
+
+	// Other failures leave the record alone for the next refresh
+	cartoon = models.Cartoon{ID: 3, Title: "Recess"}
+	failure := errors.New("connection reset")
+	if err := RefreshCartoonIMDb(context.Background(), &stubProvider{err: failure}, &cartoon); !errors.Is(err, failure) {
+		t.Errorf("lookup failure: %v, want it returned", err)
+	}
+	if cartoon.IMDbRatingFetchedAt != nil {
+		t.Error("failed lookup marked the cartoon as fetched")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
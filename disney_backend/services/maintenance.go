@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+	"disney/config"
+	"disney/database"
+	"disney/models"
+	"log"
+	"time"
+)
+
+// PurgeOldLogs deletes request logs, admin logs and scheduled job runs past their retention
+func PurgeOldLogs(ctx context.Context, cfg config.RetentionConfig) error {
+	now := time.Now()
+	purges := []struct {
+		name   string
+		model  interface{}
+		column string
+		keep   time.Duration
+	}{
+		{"request logs", &models.RequestLog{}, "created_at", time.Duration(cfg.RequestLogs)},
+		{"admin logs", &models.AdminLog{}, "created_at", time.Duration(cfg.AdminLogs)},
+		{"scheduled job runs", &models.ScheduledJobRun{}, "started_at", time.Duration(cfg.JobRuns)},
+	}
+
+	for _, purge := range purges {
+		result := database.DB.WithContext(ctx).Where(purge.column+" < ?", now.Add(-purge.keep)).Delete(purge.model)
+		if result.Error != nil {
+			return result.Error
+		}
+		log.Printf("Purged %d %s older than %s", result.RowsAffected, purge.name, purge.keep)
+	}
+	return nil
+}
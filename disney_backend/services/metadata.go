@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"disney/cache"
+	"disney/config"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrMetadataNotFound is returned when the provider has no entry for a cartoon
+	ErrMetadataNotFound = errors.New("metadata not found")
+	// ErrMetadataUnavailable is returned when the provider cannot be used (e.g. no API key)
+	ErrMetadataUnavailable = errors.New("metadata provider unavailable")
+)
+
+// Metadata is what a provider knows about a title
+// Fields the provider doesn't have are left empty
+type Metadata struct {
+	Title      string `json:"title"`
+	Year       string `json:"year,omitempty"`
+	Plot       string `json:"plot,omitempty"`
+	Runtime    string `json:"runtime,omitempty"`
+	Rated      string `json:"rated,omitempty"`
+	Awards     string `json:"awards,omitempty"`
+	IMDbRating string `json:"imdb_rating,omitempty"`
+	// Genres are the provider's genre names, most relevant first
+	Genres    []string `json:"genres,omitempty"`
+	PosterURL string   `json:"poster_url,omitempty"`
+	// ExternalIDs maps an ID namespace such as "imdb" to the title's ID there
+	ExternalIDs map[string]string `json:"external_ids,omitempty"`
+	// Source names the provider the metadata came from
+	Source string `json:"source"`
+}
+
+// IMDbID returns the title's IMDb ID, if known
+func (m *Metadata) IMDbID() string {
+	return m.ExternalIDs["imdb"]
+}
+
+// MetadataQuery identifies the title to look up
+// An IMDb ID is the most precise key; otherwise the title (and year, if set) is used
+type MetadataQuery struct {
+	Title  string
+	Year   int
+	IMDbID string
+}
+
+// cacheKey identifies a query in caches
+func (q MetadataQuery) cacheKey() string {
+	if q.IMDbID != "" {
+		return "id:" + q.IMDbID
+	}
+	key := "title:" + strings.ToLower(strings.TrimSpace(q.Title))
+	if q.Year > 0 {
+		key += ":" + strconv.Itoa(q.Year)
+	}
+	return key
+}
+
+// MetadataProvider looks up cartoon metadata such as IMDb ratings from an external source
+type MetadataProvider interface {
+	// Name identifies the provider, e.g. "omdb"
+	Name() string
+	// Lookup returns the metadata of a title, ErrMetadataNotFound if the provider
+	// doesn't know it, or ErrMetadataUnavailable if the provider cannot be used
+	Lookup(ctx context.Context, query MetadataQuery) (*Metadata, error)
+}
+
+// NewMetadataProvider builds the provider selected by cfg.Provider:
+// "omdb" (uses cfg.OMDbAPIKey) or "fixture" (reads cfg.FixtureFile)
+// OMDb is wrapped in a ResilientMetadataProvider (its daily quota is counted in
+// Redis when a client is given), and found lookups are kept in c for 24 hours
+func NewMetadataProvider(cfg config.MetadataConfig, client *redis.Client, c cache.Cache) (MetadataProvider, error) {
+	if cfg.Provider == "fixture" {
+		fixture, err := NewFixtureMetadataProvider(cfg.FixtureFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load metadata fixtures from %s: %w", cfg.FixtureFile, err)
+		}
+		return fixture, nil
+	}
+
+	if cfg.OMDbAPIKey == "" {
+		log.Println("WARNING: OMDB_API_KEY not set, IMDb ratings are disabled")
+	}
+	provider := NewResilientMetadataProvider(NewOMDbProvider(cfg.OMDbAPIKey), c, client, NewResilienceOptions(cfg))
+	return NewCachedMetadataProvider(provider, c, 24*time.Hour), nil
+}
+
+// CachedMetadataProvider caches another provider's found lookups
+type CachedMetadataProvider struct {
+	provider MetadataProvider
+	cache    cache.Cache
+	ttl      time.Duration
+}
+
+// NewCachedMetadataProvider wraps provider with a cache keeping entries for ttl
+func NewCachedMetadataProvider(provider MetadataProvider, c cache.Cache, ttl time.Duration) *CachedMetadataProvider {
+	return &CachedMetadataProvider{provider: provider, cache: c, ttl: ttl}
+}
+
+// Name returns the wrapped provider's name
+func (p *CachedMetadataProvider) Name() string {
+	return p.provider.Name()
+}
+
+// key returns the cache key of a query
+func (p *CachedMetadataProvider) key(query MetadataQuery) string {
+	return "metadata:" + p.provider.Name() + ":" + query.cacheKey()
+}
+
+// Lookup returns cached metadata or asks the wrapped provider
+// Cache errors are ignored so a cache outage only costs extra lookups
+func (p *CachedMetadataProvider) Lookup(ctx context.Context, query MetadataQuery) (*Metadata, error) {
+	key := p.key(query)
+	var cached Metadata
+	if cache.GetJSON(ctx, p.cache, key, &cached) == nil {
+		return &cached, nil
+	}
+
+	metadata, err := p.provider.Lookup(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	cache.SetJSON(ctx, p.cache, key, metadata, p.ttl)
+	return metadata, nil
+}
+
+// Status reports the wrapped provider's resilience state, if it has any
+func (p *CachedMetadataProvider) Status(ctx context.Context) MetadataProviderStatus {
+	if reporter, ok := p.provider.(MetadataStatusReporter); ok {
+		return reporter.Status(ctx)
+	}
+	return MetadataProviderStatus{Provider: p.provider.Name(), BreakerState: BreakerClosed}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"disney/cache"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// stubProvider returns a fixed lookup result and counts calls
+type stubProvider struct {
+	metadata *Metadata
+	err      error
+	calls    int
+	queries  []MetadataQuery
+}
+
+func (p *stubProvider) Name() string { return "stub" }
+
+func (p *stubProvider) Lookup(ctx context.Context, query MetadataQuery) (*Metadata, error) {
+	p.calls++
+	p.queries = append(p.queries, query)
+	return p.metadata, p.err
+}
+
+// omdbServer serves body for every request and records the last query
+func omdbServer(t *testing.T, status int, body string) (*OMDbProvider, *http.Request) {
+	t.Helper()
+	var last http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = *r
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	provider := NewOMDbProvider("secret")
+	provider.baseURL = server.URL + "/"
+	return provider, &last
+}
+
+func TestMetadataQueryCacheKey(t *testing.T) {
+	tests := []struct {
+		query MetadataQuery
+		want  string
+	}{
+		{MetadataQuery{Title: " DuckTales ", Year: 1987}, "title:ducktales:1987"},
+		{MetadataQuery{Title: "DuckTales"}, "title:ducktales"},
+		{MetadataQuery{Title: "DuckTales", IMDbID: "tt0092345"}, "id:tt0092345"},
+	}
+	for _, tt := range tests {
+		if got := tt.query.cacheKey(); got != tt.want {
+			t.Errorf("%+v: key %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestFixtureMetadataProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	fixtures := `[{"title": "DuckTales", "imdb_rating": "8.1", "external_ids": {"imdb": "tt0092345"}}, {"title": "Gargoyles"}]`
+	if err := os.WriteFile(path, []byte(fixtures), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	provider, err := NewFixtureMetadataProvider(path)
+	if err != nil {
+		t.Fatalf("NewFixtureMetadataProvider: %v", err)
+	}
+	ctx := context.Background()
+
+	byID, err := provider.Lookup(ctx, MetadataQuery{IMDbID: "tt0092345"})
+	if err != nil || byID.Title != "DuckTales" || byID.Source != "fixture" {
+		t.Errorf("lookup by ID = %+v, %v", byID, err)
+	}
+	if byTitle, err := provider.Lookup(ctx, MetadataQuery{Title: " gargoyles"}); err != nil || byTitle.Title != "Gargoyles" {
+		t.Errorf("lookup by title = %+v, %v", byTitle, err)
+	}
+	if _, err := provider.Lookup(ctx, MetadataQuery{Title: "Recess"}); !errors.Is(err, ErrMetadataNotFound) {
+		t.Errorf("unknown title: %v, want ErrMetadataNotFound", err)
+	}
+
+	if _, err := NewFixtureMetadataProvider(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("missing fixture file accepted")
+	}
+}
+
+func TestOMDbProviderLookup(t *testing.T) {
+	provider, last := omdbServer(t, http.StatusOK, `{"Title": "DuckTales", "Year": "1987–1990", "Rated": "TV-Y",
+		"Runtime": "22 min", "Plot": "N/A", "Genre": "Animation, Adventure ,Comedy", "Poster": "N/A",
+		"Awards": "N/A", "imdbRating": "8.1", "imdbID": "tt0092345", "Response": "True"}`)
+
+	metadata, err := provider.Lookup(context.Background(), MetadataQuery{Title: "DuckTales", Year: 1987})
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	want := &Metadata{
+		Title:       "DuckTales",
+		Year:        "1987–1990",
+		Runtime:     "22 min",
+		Rated:       "TV-Y",
+		IMDbRating:  "8.1",
+		Genres:      []string{"Animation", "Adventure", "Comedy"},
+		ExternalIDs: map[string]string{"imdb": "tt0092345"},
+		Source:      "omdb",
+	}
+	if !reflect.DeepEqual(metadata, want) {
+		t.Errorf("metadata %+v, want %+v", metadata, want)
+	}
+	query := last.URL.Query()
+	if query.Get("t") != "DuckTales" || query.Get("y") != "1987" || query.Get("type") != "series" || query.Get("apikey") != "secret" {
+		t.Errorf("title lookup sent %s", last.URL.RawQuery)
+	}
+
+	provider.Lookup(context.Background(), MetadataQuery{Title: "DuckTales", IMDbID: "tt0092345"})
+	if query := last.URL.Query(); query.Get("i") != "tt0092345" || query.Has("t") {
+		t.Errorf("ID lookup sent %s", last.URL.RawQuery)
+	}
+}
+
+func TestOMDbProviderErrors(t *testing.T) {
+	ctx := context.Background()
+	query := MetadataQuery{Title: "Recess"}
+
+	if _, err := NewOMDbProvider("").Lookup(ctx, query); !errors.Is(err, ErrMetadataUnavailable) {
+		t.Errorf("without API key: %v, want ErrMetadataUnavailable", err)
+	}
+
+	provider, _ := omdbServer(t, http.StatusOK, `{"Response": "False", "Error": "Series not found!"}`)
+	if _, err := provider.Lookup(ctx, query); !errors.Is(err, ErrMetadataNotFound) {
+		t.Errorf("unknown title: %v, want ErrMetadataNotFound", err)
+	}
+
+	provider, _ = omdbServer(t, http.StatusOK, `{"Response": "False", "Error": "Invalid API key!"}`)
+	if _, err := provider.Lookup(ctx, query); err == nil || errors.Is(err, ErrMetadataNotFound) {
+		t.Errorf("OMDb error: %v, want a plain error", err)
+	}
+
+	provider, _ = omdbServer(t, http.StatusInternalServerError, "")
+	if _, err := provider.Lookup(ctx, query); err == nil {
+		t.Error("server error accepted")
+	}
+}
+
+func TestCachedMetadataProviderCachesFoundLookups(t *testing.T) {
+	ctx := context.Background()
+	found := &stubProvider{metadata: &Metadata{Title: "DuckTales", IMDbRating: "8.1"}}
+	provider := NewCachedMetadataProvider(found, cache.NewMemory(10), time.Hour)
+
+	for i := 0; i < 2; i++ {
+		metadata, err := provider.Lookup(ctx, MetadataQuery{Title: "DuckTales"})
+		if err != nil || metadata.IMDbRating != "8.1" {
+			t.Fatalf("Lookup = %+v, %v", metadata, err)
+		}
+	}
+	if found.calls != 1 {
+		t.Errorf("provider called %d times, want the second lookup cached", found.calls)
+	}
+
+	missing := &stubProvider{err: ErrMetadataNotFound}
+	provider = NewCachedMetadataProvider(missing, cache.NewMemory(10), time.Hour)
+	for i := 0; i < 2; i++ {
+		if _, err := provider.Lookup(ctx, MetadataQuery{Title: "Recess"}); !errors.Is(err, ErrMetadataNotFound) {
+			t.Fatalf("Lookup: %v, want ErrMetadataNotFound", err)
+		}
+	}
+	if missing.calls != 2 {
+		t.Errorf("provider called %d times, want errors left uncached", missing.calls)
+	}
+}
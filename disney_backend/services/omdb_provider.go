@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// omdbResponse represents the response structure from OMDb API
+type omdbResponse struct {
+	Title      string `json:"Title"`
+	Year       string `json:"Year"`
+	Rated      string `json:"Rated"`
+	Runtime    string `json:"Runtime"`
+	Plot       string `json:"Plot"`
+	Genre      string `json:"Genre"`
+	Poster     string `json:"Poster"`
+	Awards     string `json:"Awards"`
+	ImdbRating string `json:"imdbRating"`
+	ImdbID     string `json:"imdbID"`
+	Response   string `json:"Response"`
+	Error      string `json:"Error"`
+}
+
+// OMDbProvider looks up metadata with the OMDb API (https://www.omdbapi.com)
+type OMDbProvider struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewOMDbProvider creates an OMDb provider; without an API key every lookup
+// fails with ErrMetadataUnavailable
+func NewOMDbProvider(apiKey string) *OMDbProvider {
+	return &OMDbProvider{
+		apiKey:  apiKey,
+		baseURL: "http://www.omdbapi.com/",
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Name returns "omdb"
+func (p *OMDbProvider) Name() string {
+	return "omdb"
+}
+
+// Lookup queries OMDb by IMDb ID, or by title (and year) among series
+func (p *OMDbProvider) Lookup(ctx context.Context, query MetadataQuery) (*Metadata, error) {
+	if p.apiKey == "" {
+		return nil, ErrMetadataUnavailable
+	}
+
+	params := url.Values{}
+	params.Set("apikey", p.apiKey)
+	params.Set("plot", "short")
+	if query.IMDbID != "" {
+		params.Set("i", query.IMDbID)
+	} else {
+		params.Set("t", query.Title)
+		params.Set("type", "series") // Search for series/cartoons specifically
+		if query.Year > 0 {
+			params.Set("y", strconv.Itoa(query.Year))
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("omdb returned status %d", resp.StatusCode)
+	}
+
+	var omdbResp omdbResponse
+	if err := json.NewDecoder(resp.Body).Decode(&omdbResp); err != nil {
+		return nil, fmt.Errorf("decode omdb response: %w", err)
+	}
+
+	// OMDb reports unknown titles as Response "False" with an error message
+	if omdbResp.Response == "False" {
+		if omdbResp.Error == "Movie not found!" || omdbResp.Error == "Series not found!" || omdbResp.Error == "Incorrect IMDb ID." {
+			return nil, ErrMetadataNotFound
+		}
+		return nil, fmt.Errorf("omdb error: %s", omdbResp.Error)
+	}
+
+	metadata := &Metadata{
+		Title:      omdbResp.Title,
+		Year:       omdbValue(omdbResp.Year),
+		Plot:       omdbValue(omdbResp.Plot),
+		Runtime:    omdbValue(omdbResp.Runtime),
+		Rated:      omdbValue(omdbResp.Rated),
+		Awards:     omdbValue(omdbResp.Awards),
+		IMDbRating: omdbValue(omdbResp.ImdbRating),
+		PosterURL:  omdbValue(omdbResp.Poster),
+		Source:     p.Name(),
+	}
+	// OMDb lists genres as "Animation, Adventure, Comedy"
+	for _, genre := range strings.Split(omdbValue(omdbResp.Genre), ",") {
+		if genre = strings.TrimSpace(genre); genre != "" {
+			metadata.Genres = append(metadata.Genres, genre)
+		}
+	}
+	if id := omdbValue(omdbResp.ImdbID); id != "" {
+		metadata.ExternalIDs = map[string]string{"imdb": id}
+	}
+	return metadata, nil
+}
+
+// omdbValue maps OMDb's "N/A" placeholder to an empty string
+func omdbValue(v string) string {
+	if v == "N/A" {
+		return ""
+	}
+	return v
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"fmt"
+)
+
+// RatingScale is the inclusive range of scores a user can give a cartoon
+type RatingScale struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// DefaultRatingScale matches the 1-10 range ratings were originally stored with
+var DefaultRatingScale = RatingScale{Min: 1, Max: 10}
+
+var ratingScale = DefaultRatingScale
+
+// SetRatingScale sets the rating scale accepted by the rating endpoints
+func SetRatingScale(scale RatingScale) {
+	ratingScale = scale
+}
+
+// GetRatingScale returns the rating scale currently in use
+func GetRatingScale() RatingScale {
+	return ratingScale
+}
+
+// Validate checks that a score falls inside the scale
+func (s RatingScale) Validate(score int) error {
+	if score < s.Min || score > s.Max {
+		return fmt.Errorf("rating must be between %d and %d", s.Min, s.Max)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package services
+
+import "testing"
+
+func TestRatingScaleValidate(t *testing.T) {
+	scale := RatingScale{Min: 1, Max: 5}
+	for _, score := range []int{1, 3, 5} {
+		if err := scale.Validate(score); err != nil {
+			t.Errorf("Validate(%d): %v", score, err)
+		}
+	}
+	for _, score := range []int{-1, 0, 6, 10} {
+		if err := scale.Validate(score); err == nil {
+			t.Errorf("Validate(%d) accepted a score outside 1-5", score)
+		}
+	}
+}
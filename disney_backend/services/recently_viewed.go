@@ -2,112 +2,183 @@ package services
 
 import (
 	"context"
+	"disney/cache"
+	"disney/config"
+	"disney/database"
+	"disney/models"
+	"errors"
 	"fmt"
-	"log"
-	"strconv"
+	"slices"
 	"time"
 
-	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm/clause"
 )
 
-const (
-	// RedisKeyPrefix is the prefix for recently viewed keys
-	RedisKeyPrefix = "recently_viewed:user:"
-	// MaxRecentlyViewedCount is the maximum number of items to store
-	MaxRecentlyViewedCount = 5
-	// TTL is the time-to-live for the Redis key (24 hours)
-	TTL = 24 * time.Hour
-)
+// RedisKeyPrefix is the prefix for cached recently viewed lists
+// (lists were stored as Redis lists under "recently_viewed:user:" before the cache abstraction)
+const RedisKeyPrefix = "recently_viewed:ids:user:"
+
+// ErrHistoryPaused is returned by AddRecentlyViewed while the user's history is paused
+var ErrHistoryPaused = errors.New("recently viewed history is paused")
 
-var redisClient *redis.Client
+var (
+	recentlyViewedCache  cache.Cache
+	recentlyViewedConfig = config.Default().RecentlyViewed
+)
 
-// SetRedisClient sets the Redis client for the service
-func SetRedisClient(client *redis.Client) {
-	redisClient = client
+// SetRecentlyViewedCache sets the cache holding recently viewed lists and
+// how long and how far back histories go
+func SetRecentlyViewedCache(c cache.Cache, cfg config.RecentlyViewedConfig) {
+	recentlyViewedCache = c
+	recentlyViewedConfig = cfg
 }
 
 // AddRecentlyViewed adds a cartoon to the user's recently viewed list
-// Logic:
-// 1. Remove the cartoon ID if it already exists (LREM)
-// 2. Push the cartoon ID to the front of the list (LPUSH)
-// 3. Trim the list to keep only the latest 5 items (LTRIM)
-// 4. Set TTL on the key to 24 hours (EXPIRE)
+// The views table is the durable history (views recorded with RecordView land
+// there through the view worker pool); the cached list is updated right away
+// so the UI doesn't wait for the worker:
+// 1. Read the current list (rebuilt from the views table on a cache miss)
+// 2. Remove the cartoon ID if it already exists
+// 3. Put the cartoon ID at the front of the list
+// 4. Keep only the latest configured number of items and cache the list
+// It returns ErrHistoryPaused, changing nothing, while the user's history is paused
 func AddRecentlyViewed(userId int, cartoonId int) error {
-	// Check if Redis is available
-	if redisClient == nil {
-		return fmt.Errorf("redis client not initialized")
+	if recentlyViewedCache == nil {
+		return fmt.Errorf("recently viewed cache not initialized")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	key := fmt.Sprintf("%s%d", RedisKeyPrefix, userId)
-	cartoonIDStr := strconv.Itoa(cartoonId)
-
-	log.Printf("DEBUG: AddRecentlyViewed called for user %d, cartoon %d", userId, cartoonId)
-
-	// Step 1: Remove the cartoon ID if it already exists in the list
-	// This ensures we don't have duplicates and maintains the "most recent" logic
-	err := redisClient.LRem(ctx, key, 0, cartoonIDStr).Err()
-	if err != nil && err != redis.Nil {
-		return fmt.Errorf("failed to remove cartoon from recently viewed: %w", err)
-	}
-
-	// Step 2: Push the cartoon ID to the front of the list (most recent first)
-	err = redisClient.LPush(ctx, key, cartoonIDStr).Err()
+	paused, err := HistoryPaused(ctx, uint(userId))
 	if err != nil {
-		return fmt.Errorf("failed to add cartoon to recently viewed: %w", err)
+		return err
+	}
+	if paused {
+		return ErrHistoryPaused
 	}
 
-	// Step 3: Trim the list to keep only the latest 5 items
-	// LTRIM keeps elements from index 0 to 4 (5 elements total)
-	err = redisClient.LTrim(ctx, key, 0, MaxRecentlyViewedCount-1).Err()
+	cartoonIds, err := recentlyViewedIDs(ctx, userId)
 	if err != nil {
-		return fmt.Errorf("failed to trim recently viewed list: %w", err)
+		return err
 	}
 
-	// Step 4: Set expiration time (TTL) to 24 hours
-	err = redisClient.Expire(ctx, key, TTL).Err()
-	if err != nil {
-		return fmt.Errorf("failed to set TTL on recently viewed key: %w", err)
+	cartoonIds = slices.DeleteFunc(cartoonIds, func(id int) bool { return id == cartoonId })
+	cartoonIds = append([]int{cartoonId}, cartoonIds...)
+	if limit := recentlyViewedConfig.Limit; len(cartoonIds) > limit {
+		cartoonIds = cartoonIds[:limit]
 	}
 
+	if err := cache.SetJSON(ctx, recentlyViewedCache, recentlyViewedKey(userId), cartoonIds, time.Duration(recentlyViewedConfig.CacheTTL)); err != nil {
+		return fmt.Errorf("failed to store recently viewed list: %w", err)
+	}
 	return nil
 }
 
 // GetRecentlyViewed retrieves the list of recently viewed cartoon IDs for a user
 // Returns the IDs in order from most recent to oldest
 func GetRecentlyViewed(userId int) ([]int, error) {
-	// Check if Redis is available
-	if redisClient == nil {
-		return nil, fmt.Errorf("redis client not initialized")
+	if recentlyViewedCache == nil {
+		return nil, fmt.Errorf("recently viewed cache not initialized")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	key := fmt.Sprintf("%s%d", RedisKeyPrefix, userId)
+	return recentlyViewedIDs(ctx, userId)
+}
 
-	// Get all items from the list (index 0 to -1)
-	val, err := redisClient.LRange(ctx, key, 0, -1).Result()
-	if err != nil && err != redis.Nil {
-		return nil, fmt.Errorf("failed to retrieve recently viewed list: %w", err)
+// RemoveRecentlyViewed hides a cartoon from the user's history
+// Its views still count towards view counts and trending
+// The removal is recorded so views still queued for the view workers, which
+// are inserted visible, stay out of the history too
+func RemoveRecentlyViewed(ctx context.Context, userID, cartoonID uint) error {
+	removal := models.HistoryRemoval{UserID: userID, CartoonID: cartoonID, RemovedAt: time.Now()}
+	if err := database.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "cartoon_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"removed_at"}),
+	}).Create(&removal).Error; err != nil {
+		return err
 	}
+	if err := database.DB.WithContext(ctx).Model(&models.View{}).
+		Where("user_id = ? AND cartoon_id = ? AND hidden_from_history = ?", userID, cartoonID, false).
+		Update("hidden_from_history", true).Error; err != nil {
+		return err
+	}
+	return forgetRecentlyViewed(ctx, userID)
+}
 
-	// If key doesn't exist or list is empty, return empty slice
-	if len(val) == 0 {
-		return []int{}, nil
+// ClearRecentlyViewed hides every cartoon from the user's history
+// The clear time is recorded so views still queued for the view workers stay
+// out of the history too
+func ClearRecentlyViewed(ctx context.Context, userID uint) error {
+	if err := database.DB.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userID).Update("history_cleared_at", time.Now()).Error; err != nil {
+		return err
 	}
+	if err := database.DB.WithContext(ctx).Model(&models.View{}).
+		Where("user_id = ? AND hidden_from_history = ?", userID, false).
+		Update("hidden_from_history", true).Error; err != nil {
+		return err
+	}
+	return forgetRecentlyViewed(ctx, userID)
+}
 
-	// Convert string IDs to integers
-	cartoonIds := make([]int, len(val))
-	for i, idStr := range val {
-		id, err := strconv.Atoi(idStr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse cartoon ID: %w", err)
-		}
-		cartoonIds[i] = id
+// HistoryPaused reports whether the user paused their recently viewed history
+func HistoryPaused(ctx context.Context, userID uint) (bool, error) {
+	var paused []bool
+	if err := database.DB.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userID).Pluck("history_paused", &paused).Error; err != nil {
+		return false, err
+	}
+	return len(paused) > 0 && paused[0], nil
+}
+
+// SetHistoryPaused pauses or resumes the user's recently viewed history
+// Views recorded while paused are kept for view counts but never shown in the history
+func SetHistoryPaused(ctx context.Context, userID uint, paused bool) error {
+	return database.DB.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", userID).Update("history_paused", paused).Error
+}
+
+// recentlyViewedKey is the cache key of a user's list
+func recentlyViewedKey(userId int) string {
+	return fmt.Sprintf("%s%d", RedisKeyPrefix, userId)
+}
+
+// forgetRecentlyViewed drops a user's cached list so the next read rebuilds it
+func forgetRecentlyViewed(ctx context.Context, userID uint) error {
+	if recentlyViewedCache == nil {
+		return nil
+	}
+	if err := recentlyViewedCache.Delete(ctx, recentlyViewedKey(int(userID))); err != nil {
+		return fmt.Errorf("failed to drop cached recently viewed list: %w", err)
+	}
+	return nil
+}
+
+// recentlyViewedIDs reads a user's list from the cache, rebuilding it from the views table on a miss
+func recentlyViewedIDs(ctx context.Context, userId int) ([]int, error) {
+	cartoonIds := []int{}
+	if err := cache.GetJSON(ctx, recentlyViewedCache, recentlyViewedKey(userId), &cartoonIds); err == nil {
+		return cartoonIds, nil
+	}
+
+	cartoonIds = []int{}
+	if err := database.DB.WithContext(ctx).Model(&models.View{}).
+		Where("user_id = ? AND hidden_from_history = ? AND viewed_at > ?",
+			userId, false, time.Now().Add(-time.Duration(recentlyViewedConfig.Retention))).
+		// Leave out views at or before a clear or removal, including ones that were still queued
+		Where("NOT EXISTS (SELECT 1 FROM users WHERE users.id = views.user_id AND users.history_cleared_at >= views.viewed_at)").
+		Where("NOT EXISTS (SELECT 1 FROM history_removals r WHERE r.user_id = views.user_id AND r.cartoon_id = views.cartoon_id AND r.removed_at >= views.viewed_at)").
+		Group("cartoon_id").
+		Order("MAX(viewed_at) DESC").
+		Limit(recentlyViewedConfig.Limit).
+		Pluck("cartoon_id", &cartoonIds).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve recently viewed list: %w", err)
 	}
 
+	// A failed cache write only means the next read queries again
+	cache.SetJSON(ctx, recentlyViewedCache, recentlyViewedKey(userId), cartoonIds, time.Duration(recentlyViewedConfig.CacheTTL))
 	return cartoonIds, nil
 }
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"disney/cache"
+	"disney/config"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const historyPausedQuery = `SELECT "history_paused" FROM "users" WHERE id = \$1`
+
+// useRecentlyViewedCache replaces the recently viewed cache for the test, listing up to 3 cartoons
+func useRecentlyViewedCache(t *testing.T, c cache.Cache) {
+	t.Helper()
+	previous, previousConfig := recentlyViewedCache, recentlyViewedConfig
+	SetRecentlyViewedCache(c, config.RecentlyViewedConfig{
+		Limit:     3,
+		Retention: config.Duration(24 * time.Hour),
+		CacheTTL:  config.Duration(time.Hour),
+	})
+	t.Cleanup(func() { SetRecentlyViewedCache(previous, previousConfig) })
+}
+
+func TestAddRecentlyViewedMovesCartoonToFront(t *testing.T) {
+	useRecentlyViewedCache(t, cache.NewMemory(10))
+	cache.SetJSON(context.Background(), recentlyViewedCache, recentlyViewedKey(5), []int{1, 2, 3}, time.Hour)
+
+	mock, _ := mockDB(t)
+	for _, cartoonID := range []int{2, 4} {
+		mock.ExpectQuery(historyPausedQuery).WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"history_paused"}).AddRow(false))
+		if err := AddRecentlyViewed(5, cartoonID); err != nil {
+			t.Fatalf("AddRecentlyViewed(%d): %v", cartoonID, err)
+		}
+	}
+
+	// The repeat moved to the front and the oldest fell off the limit
+	ids, err := GetRecentlyViewed(5)
+	if err != nil || !reflect.DeepEqual(ids, []int{4, 2, 1}) {
+		t.Errorf("GetRecentlyViewed = %v, %v, want [4 2 1]", ids, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAddRecentlyViewedWhilePaused(t *testing.T) {
+	useRecentlyViewedCache(t, cache.NewMemory(10))
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(historyPausedQuery).WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"history_paused"}).AddRow(true))
+
+	if err := AddRecentlyViewed(5, 2); !errors.Is(err, ErrHistoryPaused) {
+		t.Errorf("AddRecentlyViewed: %v, want ErrHistoryPaused", err)
+	}
+	if _, err := recentlyViewedCache.Get(context.Background(), recentlyViewedKey(5)); !errors.Is(err, cache.ErrMiss) {
+		t.Error("paused history was cached")
+	}
+}
+
+func TestGetRecentlyViewedRebuildsFromViews(t *testing.T) {
+	// A tiered cache whose Redis is down still serves histories from memory
+	useRecentlyViewedCache(t, cache.NewTiered(cache.NewMemory(10), cache.NewRedis(nil, nil, time.Minute), time.Minute))
+	mock, _ := mockDB(t)
+	// Views inserted after a clear or removal are left out by their view time
+	mock.ExpectQuery(`SELECT "cartoon_id" FROM "views" WHERE .*users.history_cleared_at >= views.viewed_at.*r.removed_at >= views.viewed_at.* GROUP BY "cartoon_id" ORDER BY MAX\(viewed_at\) DESC LIMIT \$4`).
+		WithArgs(5, false, sqlmock.AnyArg(), 3).
+		WillReturnRows(sqlmock.NewRows([]string{"cartoon_id"}).AddRow(7).AddRow(3))
+
+	for i := 0; i < 2; i++ {
+		ids, err := GetRecentlyViewed(5)
+		if err != nil || !reflect.DeepEqual(ids, []int{7, 3}) {
+			t.Fatalf("GetRecentlyViewed = %v, %v, want [7 3]", ids, err)
+		}
+	}
+	// The second read came from the cache
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRemoveAndClearRecentlyViewedHideViews(t *testing.T) {
+	useRecentlyViewedCache(t, cache.NewMemory(10))
+	ctx := context.Background()
+	mock, _ := mockDB(t)
+	// The removal and clear times also hide views still waiting in the view queue
+	mock.ExpectQuery(`INSERT INTO "history_removals" \("user_id","cartoon_id","removed_at"\) VALUES \(\$1,\$2,\$3\) ON CONFLICT \("user_id","cartoon_id"\) DO UPDATE SET "removed_at"="excluded"."removed_at"`).
+		WithArgs(5, 2, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec(`UPDATE "views" SET "hidden_from_history"=\$1 WHERE user_id = \$2 AND cartoon_id = \$3 AND hidden_from_history = \$4`).
+		WithArgs(true, 5, 2, false).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`UPDATE "users" SET "history_cleared_at"=\$1,"updated_at"=\$2 WHERE id = \$3`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "views" SET "hidden_from_history"=\$1 WHERE user_id = \$2 AND hidden_from_history = \$3`).
+		WithArgs(true, 5, false).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	for _, hide := range []func() error{
+		func() error { return RemoveRecentlyViewed(ctx, 5, 2) },
+		func() error { return ClearRecentlyViewed(ctx, 5) },
+	} {
+		cache.SetJSON(ctx, recentlyViewedCache, recentlyViewedKey(5), []int{2, 1}, time.Hour)
+		if err := hide(); err != nil {
+			t.Fatalf("hiding views: %v", err)
+		}
+		// The cached list is dropped so the next read rebuilds it without the hidden views
+		if _, err := recentlyViewedCache.Get(ctx, recentlyViewedKey(5)); !errors.Is(err, cache.ErrMiss) {
+			t.Error("cached list kept after hiding views")
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,438 @@
+package services
+
+import (
+	"context"
+	"disney/database"
+	"disney/models"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxNeighbours is how many similar items are kept per cartoon after training
+	maxNeighbours = 30
+	// maxItemsPerUser caps how many interactions of a single user feed the co-occurrence counts
+	maxItemsPerUser = 200
+	// contentWeight scales content-based scores so collaborative signals rank first
+	contentWeight = 0.25
+
+	// Interaction weights used to build the user-item matrix
+	favouriteWeight  = 2.0
+	maxRatingWeight  = 2.0
+	viewWeightFactor = 0.5
+	maxViewWeight    = 1.5
+)
+
+// Recommendation sources reported to clients
+const (
+	SourceCollaborative = "collaborative"
+	SourceContent       = "content"
+	SourcePopular       = "popular"
+)
+
+// Recommendation is a single scored suggestion for a user
+type Recommendation struct {
+	CartoonID uint    `json:"cartoon_id"`
+	Score     float64 `json:"score"`
+	Source    string  `json:"source"`
+	// BecauseOf is the cartoon the user interacted with that contributed most to the score
+	BecauseOf uint `json:"because_of,omitempty"`
+}
+
+// catalogItem is the content profile of a cartoon used for similarity scoring
+type catalogItem struct {
+	ID          uint
+	GenreID     uint
+	AgeGroupID  uint
+	MinAge      int
+	ReleaseYear int
+	IsFeatured  bool
+	Characters  map[string]struct{}
+}
+
+// scoredItem pairs a cartoon with a similarity or relevance score
+type scoredItem struct {
+	ID    uint
+	Score float64
+}
+
+// recommendationModel is the trained item-item similarity model
+type recommendationModel struct {
+	neighbours map[uint][]scoredItem
+	popularity map[uint]float64
+	trainedAt  time.Time
+}
+
+var (
+	recModelMu sync.RWMutex
+	recModel   *recommendationModel
+)
+
+var ageDigits = regexp.MustCompile(`\d+`)
+
+// minAgeFromLabel extracts the minimum age from an age group label such as
+// "Kids (5-8 years)" or "Adults (18+ years)"; labels without digits allow all ages
+func minAgeFromLabel(label string) int {
+	match := ageDigits.FindString(label)
+	if match == "" {
+		return 0
+	}
+	var age int
+	fmt.Sscanf(match, "%d", &age)
+	return age
+}
+
+// loadCatalog loads the content profile of every cartoon keyed by ID
+func loadCatalog(ctx context.Context) (map[uint]catalogItem, error) {
+	db := database.DB.WithContext(ctx)
+
+	var cartoons []models.Cartoon
+	if err := db.Preload("AgeGroup").
+		Select("id", "genre_id", "age_group_id", "release_year", "is_featured").
+		Find(&cartoons).Error; err != nil {
+		return nil, err
+	}
+
+	var characters []models.Character
+	if err := db.Select("cartoon_id", "name").Find(&characters).Error; err != nil {
+		return nil, err
+	}
+
+	catalog := make(map[uint]catalogItem, len(cartoons))
+	for _, cartoon := range cartoons {
+		catalog[cartoon.ID] = catalogItem{
+			ID:          cartoon.ID,
+			GenreID:     cartoon.GenreID,
+			AgeGroupID:  cartoon.AgeGroupID,
+			MinAge:      minAgeFromLabel(cartoon.AgeGroup.Label),
+			ReleaseYear: cartoon.ReleaseYear,
+			IsFeatured:  cartoon.IsFeatured,
+			Characters:  map[string]struct{}{},
+		}
+	}
+
+	for _, character := range characters {
+		if item, ok := catalog[character.CartoonID]; ok {
+			item.Characters[strings.ToLower(strings.TrimSpace(character.Name))] = struct{}{}
+		}
+	}
+
+	return catalog, nil
+}
+
+// sharedCharacters counts character names two cartoons have in common
+func sharedCharacters(a, b catalogItem) int {
+	small, large := a.Characters, b.Characters
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+	shared := 0
+	for name := range small {
+		if _, ok := large[name]; ok {
+			shared++
+		}
+	}
+	return shared
+}
+
+// contentSimilarity scores how alike two cartoons are from their metadata, in [0, 1]
+func contentSimilarity(a, b catalogItem) float64 {
+	score := 0.0
+	if a.GenreID == b.GenreID {
+		score += 0.5
+	}
+	if a.AgeGroupID == b.AgeGroupID {
+		score += 0.2
+	}
+	if shared := sharedCharacters(a, b); shared > 0 {
+		union := len(a.Characters) + len(b.Characters) - shared
+		score += 0.3 * float64(shared) / float64(union)
+	}
+	return score
+}
+
+// loadInteractions builds per-user interaction weights from ratings, favourites and views
+// If userID is non-zero only that user's interactions are loaded
+func loadInteractions(ctx context.Context, userID uint) (map[uint]map[uint]float64, error) {
+	db := database.DB.WithContext(ctx)
+	interactions := map[uint]map[uint]float64{}
+	add := func(user, cartoon uint, weight float64) {
+		if interactions[user] == nil {
+			interactions[user] = map[uint]float64{}
+		}
+		interactions[user][cartoon] += weight
+	}
+
+	scale := GetRatingScale()
+	span := float64(scale.Max - scale.Min)
+
+	var ratings []models.Rating
+	ratingQuery := db.Select("user_id", "cartoon_id", "rating")
+	if userID != 0 {
+		ratingQuery = ratingQuery.Where("user_id = ?", userID)
+	}
+	if err := ratingQuery.Find(&ratings).Error; err != nil {
+		return nil, err
+	}
+	for _, rating := range ratings {
+		// Higher scores count more; the lowest score still shows the user watched it
+		normalised := math.Max(0, math.Min(1, float64(rating.Rating-scale.Min)/span))
+		add(rating.UserID, rating.CartoonID, 0.1+maxRatingWeight*normalised)
+	}
+
+	var favourites []models.Favourite
+	favouriteQuery := db.Select("user_id", "cartoon_id")
+	if userID != 0 {
+		favouriteQuery = favouriteQuery.Where("user_id = ?", userID)
+	}
+	if err := favouriteQuery.Find(&favourites).Error; err != nil {
+		return nil, err
+	}
+	for _, favourite := range favourites {
+		add(favourite.UserID, favourite.CartoonID, favouriteWeight)
+	}
+
+	var viewCounts []struct {
+		UserID    uint
+		CartoonID uint
+		Views     int64
+	}
+	viewQuery := db.Model(&models.View{}).
+		Select("user_id, cartoon_id, COUNT(*) AS views").
+		Where("user_id IS NOT NULL").
+		Group("user_id, cartoon_id")
+	if userID != 0 {
+		viewQuery = viewQuery.Where("user_id = ?", userID)
+	}
+	if err := viewQuery.Scan(&viewCounts).Error; err != nil {
+		return nil, err
+	}
+	for _, vc := range viewCounts {
+		add(vc.UserID, vc.CartoonID, math.Min(maxViewWeight, viewWeightFactor*math.Log1p(float64(vc.Views))))
+	}
+
+	return interactions, nil
+}
+
+// topItems returns the n highest weighted items of a user
+func topItems(items map[uint]float64, n int) []scoredItem {
+	list := make([]scoredItem, 0, len(items))
+	for id, weight := range items {
+		list = append(list, scoredItem{ID: id, Score: weight})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Score > list[j].Score })
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+// TrainRecommendations rebuilds the item-item collaborative filtering model
+// Similarity is the cosine between item vectors over users
+func TrainRecommendations(ctx context.Context) error {
+	start := time.Now()
+
+	interactions, err := loadInteractions(ctx, 0)
+	if err != nil {
+		return fmt.Errorf("failed to load interactions: %w", err)
+	}
+
+	norms := map[uint]float64{}
+	popularity := map[uint]float64{}
+	dots := map[uint]map[uint]float64{}
+
+	for _, items := range interactions {
+		top := topItems(items, maxItemsPerUser)
+		for i, a := range top {
+			norms[a.ID] += a.Score * a.Score
+			popularity[a.ID] += a.Score
+			for _, b := range top[i+1:] {
+				if dots[a.ID] == nil {
+					dots[a.ID] = map[uint]float64{}
+				}
+				if dots[b.ID] == nil {
+					dots[b.ID] = map[uint]float64{}
+				}
+				product := a.Score * b.Score
+				dots[a.ID][b.ID] += product
+				dots[b.ID][a.ID] += product
+			}
+		}
+	}
+
+	neighbours := make(map[uint][]scoredItem, len(dots))
+	for item, others := range dots {
+		list := make([]scoredItem, 0, len(others))
+		for other, dot := range others {
+			denominator := math.Sqrt(norms[item]) * math.Sqrt(norms[other])
+			if denominator == 0 {
+				continue
+			}
+			list = append(list, scoredItem{ID: other, Score: dot / denominator})
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Score > list[j].Score })
+		if len(list) > maxNeighbours {
+			list = list[:maxNeighbours]
+		}
+		neighbours[item] = list
+	}
+
+	recModelMu.Lock()
+	recModel = &recommendationModel{
+		neighbours: neighbours,
+		popularity: popularity,
+		trainedAt:  time.Now(),
+	}
+	recModelMu.Unlock()
+
+	log.Printf("Recommendation model trained: %d users, %d items with neighbours in %s",
+		len(interactions), len(neighbours), time.Since(start))
+	return nil
+}
+
+// currentModel returns the trained model, or an empty one before the first training run
+func currentModel() *recommendationModel {
+	recModelMu.RLock()
+	defer recModelMu.RUnlock()
+	if recModel == nil {
+		return &recommendationModel{neighbours: map[uint][]scoredItem{}, popularity: map[uint]float64{}}
+	}
+	return recModel
+}
+
+// RecommendationsTrainedAt reports when the model was last trained (zero if never)
+func RecommendationsTrainedAt() time.Time {
+	return currentModel().trainedAt
+}
+
+// RecommendForUser returns up to limit cartoons for a user
+// Collaborative scores come from the trained model, content similarity fills gaps,
+// and users with no history get popular and featured titles
+// Cartoons the user already interacted with and titles above their age are excluded
+func RecommendForUser(ctx context.Context, userID uint, limit int) ([]Recommendation, error) {
+	var user models.User
+	if err := database.DB.WithContext(ctx).Select("id", "age").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	catalog, err := loadCatalog(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load catalog: %w", err)
+	}
+
+	// Use live interactions so new ratings and views count before the next retrain
+	interactions, err := loadInteractions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load interactions: %w", err)
+	}
+	history := interactions[userID]
+
+	eligible := func(id uint) bool {
+		item, ok := catalog[id]
+		if !ok {
+			return false
+		}
+		if _, seen := history[id]; seen {
+			return false
+		}
+		return item.MinAge <= user.Age
+	}
+
+	model := currentModel()
+	candidates := map[uint]*Recommendation{}
+	contributions := map[uint]float64{}
+
+	// Item-item collaborative filtering
+	for itemID, weight := range history {
+		for _, neighbour := range model.neighbours[itemID] {
+			if !eligible(neighbour.ID) {
+				continue
+			}
+			contribution := weight * neighbour.Score
+			rec, ok := candidates[neighbour.ID]
+			if !ok {
+				rec = &Recommendation{CartoonID: neighbour.ID, Source: SourceCollaborative}
+				candidates[neighbour.ID] = rec
+			}
+			rec.Score += contribution
+			if contribution > contributions[neighbour.ID] {
+				contributions[neighbour.ID] = contribution
+				rec.BecauseOf = itemID
+			}
+		}
+	}
+
+	// Content-based fallback when collaborative signals are too thin
+	if len(candidates) < limit && len(history) > 0 {
+		for id := range catalog {
+			if !eligible(id) {
+				continue
+			}
+			best := 0.0
+			var bestSource uint
+			total := 0.0
+			for itemID, weight := range history {
+				source, ok := catalog[itemID]
+				if !ok {
+					continue
+				}
+				similarity := weight * contentSimilarity(source, catalog[id])
+				total += similarity
+				if similarity > best {
+					best = similarity
+					bestSource = itemID
+				}
+			}
+			if total == 0 {
+				continue
+			}
+			score := contentWeight * total / float64(len(history))
+			if rec, ok := candidates[id]; ok {
+				rec.Score += score
+				continue
+			}
+			candidates[id] = &Recommendation{CartoonID: id, Score: score, Source: SourceContent, BecauseOf: bestSource}
+		}
+	}
+
+	// Cold start: popular titles, featured ones first
+	// Scores stay below 0.001 so personalised results always rank above them
+	if len(candidates) < limit {
+		maxPopularity := 1.0
+		for _, popularity := range model.popularity {
+			maxPopularity = math.Max(maxPopularity, popularity)
+		}
+		for id, item := range catalog {
+			if _, ok := candidates[id]; ok || !eligible(id) {
+				continue
+			}
+			score := 0.0005 * model.popularity[id] / maxPopularity
+			if item.IsFeatured {
+				score += 0.0004
+			}
+			candidates[id] = &Recommendation{CartoonID: id, Score: score, Source: SourcePopular}
+		}
+	}
+
+	results := make([]Recommendation, 0, len(candidates))
+	for _, rec := range candidates {
+		results = append(results, *rec)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score == results[j].Score {
+			return results[i].CartoonID < results[j].CartoonID
+		}
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
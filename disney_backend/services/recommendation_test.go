@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestMinAgeFromLabel(t *testing.T) {
+	tests := map[string]int{
+		"Kids (5-8 years)":    5,
+		"Adults (18+ years)":  18,
+		"All ages":            0,
+		"":                    0,
+		"Teens (13-17 years)": 13,
+	}
+	for label, want := range tests {
+		if got := minAgeFromLabel(label); got != want {
+			t.Errorf("minAgeFromLabel(%q) = %d, want %d", label, got, want)
+		}
+	}
+}
+
+func TestContentSimilarity(t *testing.T) {
+	characters := func(names ...string) map[string]struct{} {
+		set := map[string]struct{}{}
+		for _, name := range names {
+			set[name] = struct{}{}
+		}
+		return set
+	}
+	a := catalogItem{GenreID: 1, AgeGroupID: 1, Characters: characters("mickey", "goofy")}
+
+	tests := []struct {
+		name string
+		b    catalogItem
+		want float64
+	}{
+		{"identical", catalogItem{GenreID: 1, AgeGroupID: 1, Characters: characters("mickey", "goofy")}, 1},
+		{"genre only", catalogItem{GenreID: 1, AgeGroupID: 2, Characters: characters()}, 0.5},
+		{"age group only", catalogItem{GenreID: 2, AgeGroupID: 1, Characters: characters()}, 0.2},
+		{"one of three characters", catalogItem{GenreID: 2, AgeGroupID: 2, Characters: characters("goofy", "donald")}, 0.1},
+		{"nothing shared", catalogItem{GenreID: 2, AgeGroupID: 2, Characters: characters("elsa")}, 0},
+	}
+	for _, tt := range tests {
+		got := contentSimilarity(a, tt.b)
+		if diff := got - tt.want; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("%s: contentSimilarity = %v, want %v", tt.name, got, tt.want)
+		}
+		if reverse := contentSimilarity(tt.b, a); reverse != got {
+			t.Errorf("%s: not symmetric: %v and %v", tt.name, got, reverse)
+		}
+	}
+}
+
+func TestTopItems(t *testing.T) {
+	items := map[uint]float64{1: 0.5, 2: 3, 3: 1.5, 4: 0.1}
+	top := topItems(items, 2)
+	if len(top) != 2 || top[0].ID != 2 || top[1].ID != 3 {
+		t.Errorf("topItems = %v, want cartoons 2 and 3", top)
+	}
+	if all := topItems(items, 10); len(all) != 4 {
+		t.Errorf("topItems kept %d items, want 4", len(all))
+	}
+}
+
+func TestRecommendForUserExcludesWatchedAndOverAge(t *testing.T) {
+	recModelMu.Lock()
+	previous := recModel
+	recModel = &recommendationModel{
+		neighbours: map[uint][]scoredItem{1: {{ID: 2, Score: 0.9}, {ID: 3, Score: 0.8}}},
+		popularity: map[uint]float64{},
+	}
+	recModelMu.Unlock()
+	t.Cleanup(func() {
+		recModelMu.Lock()
+		recModel = previous
+		recModelMu.Unlock()
+	})
+
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(`FROM "users"`).WillReturnRows(sqlmock.NewRows([]string{"id", "age"}).AddRow(5, 7))
+	mock.ExpectQuery(`FROM "cartoons"`).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "genre_id", "age_group_id", "release_year", "is_featured"}).
+			AddRow(1, 1, 1, 2000, false).
+			AddRow(2, 1, 1, 2001, false).
+			AddRow(3, 1, 2, 2002, false).
+			AddRow(4, 2, 1, 2003, false))
+	mock.ExpectQuery(`FROM "age_groups"`).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "label"}).AddRow(1, "Kids (5-8 years)").AddRow(2, "Teens (13+ years)"))
+	mock.ExpectQuery(`FROM "characters"`).WillReturnRows(sqlmock.NewRows([]string{"cartoon_id", "name"}))
+	mock.ExpectQuery(`FROM "ratings"`).WillReturnRows(
+		sqlmock.NewRows([]string{"user_id", "cartoon_id", "rating"}).AddRow(5, 1, 10))
+	mock.ExpectQuery(`FROM "favourites"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "cartoon_id"}))
+	mock.ExpectQuery(`FROM "views"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "cartoon_id", "views"}))
+
+	recs, err := RecommendForUser(context.Background(), 5, 10)
+	if err != nil {
+		t.Fatalf("RecommendForUser: %v", err)
+	}
+	// Cartoon 1 was rated and cartoon 3 is for 13 and over
+	if len(recs) != 2 {
+		t.Fatalf("got %+v, want cartoons 2 and 4", recs)
+	}
+	if recs[0].CartoonID != 2 || recs[0].Source != SourceCollaborative || recs[0].BecauseOf != 1 {
+		t.Errorf("first recommendation %+v, want cartoon 2 from collaborative filtering because of 1", recs[0])
+	}
+	if recs[1].CartoonID != 4 || recs[1].Source != SourceContent {
+		t.Errorf("second recommendation %+v, want cartoon 4 from content", recs[1])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTrainRecommendationsLinksCoWatchedCartoons(t *testing.T) {
+	recModelMu.Lock()
+	previous := recModel
+	recModelMu.Unlock()
+	t.Cleanup(func() {
+		recModelMu.Lock()
+		recModel = previous
+		recModelMu.Unlock()
+	})
+
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(`FROM "ratings"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "cartoon_id", "rating"}))
+	// Both users favourited cartoons 1 and 2; only user 8 favourited 3
+	mock.ExpectQuery(`FROM "favourites"`).WillReturnRows(
+		sqlmock.NewRows([]string{"user_id", "cartoon_id"}).
+			AddRow(7, 1).AddRow(7, 2).
+			AddRow(8, 1).AddRow(8, 2).AddRow(8, 3))
+	mock.ExpectQuery(`FROM "views"`).WillReturnRows(sqlmock.NewRows([]string{"user_id", "cartoon_id", "views"}))
+
+	if err := TrainRecommendations(context.Background()); err != nil {
+		t.Fatalf("TrainRecommendations: %v", err)
+	}
+	model := currentModel()
+	neighbours := model.neighbours[1]
+	if len(neighbours) != 2 || neighbours[0].ID != 2 {
+		t.Fatalf("neighbours of 1 = %v, want 2 then 3", neighbours)
+	}
+	if neighbours[0].Score <= neighbours[1].Score || neighbours[0].Score > 1+1e-9 {
+		t.Errorf("neighbour scores %v, want cosine similarities with 2 above 3", neighbours)
+	}
+	if model.popularity[1] != 2*favouriteWeight || model.trainedAt.IsZero() {
+		t.Errorf("popularity of 1 = %v, trained at %v", model.popularity[1], model.trainedAt)
+	}
+}
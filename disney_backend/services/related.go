@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"disney/cache"
+	"disney/database"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// relatedKeyPrefix is the prefix for cached related-cartoon lists
+	relatedKeyPrefix = "related:cartoon:"
+	// relatedVersionKey is bumped whenever the catalogue changes, invalidating every cached list
+	relatedVersionKey = "related:version"
+	// RelatedTTL bounds how long a cached list lives even without catalogue changes,
+	// since co-favourite and co-view counts drift over time
+	RelatedTTL = 6 * time.Hour
+	// maxRelated is how many related cartoons are computed and cached per cartoon
+	maxRelated = 20
+	// yearWindow is the release-year distance at which proximity stops counting
+	yearWindow = 10.0
+)
+
+var (
+	// relatedCache holds related-cartoon lists (by default a memory L1 in front of Redis)
+	relatedCache cache.Cache
+	// relatedRedis holds the catalogue version shared by every replica
+	relatedRedis *cache.Redis
+	// relatedLocalVersion versions lists cached while the shared version cannot be read
+	relatedLocalVersion atomic.Int64
+	// relatedBumpPending is set when an invalidation could not reach Redis, so
+	// lists Redis kept under the old version are not served once it is back
+	relatedBumpPending atomic.Bool
+)
+
+// SetRelatedCache sets the cache holding related-cartoon lists
+// remote, which may be nil, shares the catalogue version between replicas;
+// without it, or while Redis is down, each replica versions its lists itself
+func SetRelatedCache(c cache.Cache, remote *cache.Redis) {
+	relatedCache = c
+	relatedRedis = remote
+}
+
+// RelatedCartoon is a cartoon scored by similarity to another cartoon
+type RelatedCartoon struct {
+	CartoonID uint    `json:"cartoon_id"`
+	Score     float64 `json:"score"`
+}
+
+// relatedCacheKey builds the cache key for a cartoon under the current catalogue version
+func relatedCacheKey(ctx context.Context, cartoonID uint) string {
+	return fmt.Sprintf("%s%s:%d", relatedKeyPrefix, relatedVersion(ctx), cartoonID)
+}
+
+// relatedVersion returns the shared catalogue version, or this replica's own
+// while Redis is unavailable (Redis calls then fail fast, see cache.Redis)
+func relatedVersion(ctx context.Context) string {
+	version := "local" + strconv.FormatInt(relatedLocalVersion.Load(), 10)
+	if relatedRedis == nil {
+		return version
+	}
+
+	relatedRedis.Do(ctx, func(client *redis.Client) error {
+		if relatedBumpPending.Load() {
+			if err := client.Incr(ctx, relatedVersionKey).Err(); err != nil {
+				return err
+			}
+			relatedBumpPending.Store(false)
+		}
+		shared, err := client.Get(ctx, relatedVersionKey).Result()
+		switch {
+		case errors.Is(err, redis.Nil):
+			version = "0"
+		case err == nil:
+			version = shared
+		default:
+			return err
+		}
+		return nil
+	})
+	return version
+}
+
+// InvalidateRelatedCache drops every cached related list
+// Called after any catalogue change, since a new or edited cartoon can become
+// related to any other cartoon
+func InvalidateRelatedCache() {
+	relatedLocalVersion.Add(1)
+	if relatedRedis == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	err := relatedRedis.Do(ctx, func(client *redis.Client) error {
+		return client.Incr(ctx, relatedVersionKey).Err()
+	})
+	if err != nil {
+		// Bumped as soon as Redis answers again
+		relatedBumpPending.Store(true)
+		if !errors.Is(err, cache.ErrUnavailable) {
+			log.Printf("WARNING: Failed to invalidate related cartoons cache: %v", err)
+		}
+	}
+}
+
+// coEngagement counts, for every other cartoon, how many users favourited or
+// viewed both it and the given cartoon
+func coEngagement(ctx context.Context, cartoonID uint) (map[uint]float64, error) {
+	var rows []struct {
+		CartoonID uint
+		Users     int64
+	}
+
+	err := database.DB.WithContext(ctx).Raw(`
+		WITH engaged AS (
+			SELECT user_id, cartoon_id FROM favourites
+			UNION
+			SELECT user_id, cartoon_id FROM views WHERE user_id IS NOT NULL
+		)
+		SELECT other.cartoon_id, COUNT(DISTINCT other.user_id) AS users
+		FROM engaged target
+		JOIN engaged other ON other.user_id = target.user_id AND other.cartoon_id <> target.cartoon_id
+		WHERE target.cartoon_id = ?
+		GROUP BY other.cartoon_id
+	`, cartoonID).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		counts[row.CartoonID] = float64(row.Users)
+	}
+	return counts, nil
+}
+
+// computeRelated scores every other cartoon against the given one
+// Metadata similarity (genre, age group, shared characters) is combined with
+// release-year proximity and how often users engage with both cartoons
+func computeRelated(ctx context.Context, cartoonID uint) ([]RelatedCartoon, error) {
+	catalog, err := loadCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	target, ok := catalog[cartoonID]
+	if !ok {
+		return nil, fmt.Errorf("cartoon %d not found", cartoonID)
+	}
+
+	coCounts, err := coEngagement(ctx, cartoonID)
+	if err != nil {
+		return nil, err
+	}
+	maxCo := 0.0
+	for _, count := range coCounts {
+		maxCo = math.Max(maxCo, count)
+	}
+
+	related := make([]RelatedCartoon, 0, len(catalog))
+	for id, item := range catalog {
+		if id == cartoonID {
+			continue
+		}
+
+		score := contentSimilarity(target, item)
+
+		if target.ReleaseYear > 0 && item.ReleaseYear > 0 {
+			distance := math.Abs(float64(target.ReleaseYear - item.ReleaseYear))
+			score += 0.2 * math.Max(0, 1-distance/yearWindow)
+		}
+
+		if maxCo > 0 {
+			score += 0.5 * coCounts[id] / maxCo
+		}
+
+		if score > 0 {
+			related = append(related, RelatedCartoon{CartoonID: id, Score: score})
+		}
+	}
+
+	sort.Slice(related, func(i, j int) bool {
+		if related[i].Score == related[j].Score {
+			return related[i].CartoonID < related[j].CartoonID
+		}
+		return related[i].Score > related[j].Score
+	})
+	if len(related) > maxRelated {
+		related = related[:maxRelated]
+	}
+
+	return related, nil
+}
+
+// GetRelatedCartoons returns cartoons similar to the given one, best match first
+// Lists are cached per cartoon when a cache is set
+func GetRelatedCartoons(ctx context.Context, cartoonID uint, limit int) ([]RelatedCartoon, error) {
+	var related []RelatedCartoon
+	key := ""
+	cached := false
+	if relatedCache != nil {
+		key = relatedCacheKey(ctx, cartoonID)
+		cached = cache.GetJSON(ctx, relatedCache, key, &related) == nil
+	}
+
+	if !cached {
+		var err error
+		related, err = computeRelated(ctx, cartoonID)
+		if err != nil {
+			return nil, err
+		}
+
+		if relatedCache != nil {
+			// A failed cache write only means the next lookup computes the list again
+			cache.SetJSON(ctx, relatedCache, key, related, RelatedTTL)
+		}
+	}
+
+	if len(related) > limit {
+		related = related[:limit]
+	}
+	return related, nil
+}
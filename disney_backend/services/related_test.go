@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"disney/cache"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// mockRedis starts an in-memory Redis server for the test
+func mockRedis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	return miniredis.RunT(t)
+}
+
+// useRelatedCache caches related lists in a tiered cache on the given Redis server for the test
+func useRelatedCache(t *testing.T, addr string) *cache.Redis {
+	t.Helper()
+	options := &redis.Options{Addr: addr, MaxRetries: -1, DialTimeout: 50 * time.Millisecond}
+	client := redis.NewClient(options)
+	remote := cache.NewRedis(client, options, 10*time.Millisecond)
+	SetRelatedCache(cache.NewTiered(cache.NewMemory(100), remote, time.Minute), remote)
+	t.Cleanup(func() {
+		SetRelatedCache(nil, nil)
+		relatedBumpPending.Store(false)
+		remote.Close()
+		client.Close()
+	})
+	return remote
+}
+
+// expectRelatedQueries expects the catalogue and co-engagement queries behind computeRelated
+// Cartoon 1 is a 2000 comedy; 2 shares its genre and year, 3 is a 2005 drama
+// users engaged with both, and 4 is a 1950 drama nobody engaged with
+func expectRelatedQueries(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(`FROM "cartoons"`).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "genre_id", "age_group_id", "release_year", "is_featured"}).
+			AddRow(1, 1, 1, 2000, false).
+			AddRow(2, 1, 2, 2000, false).
+			AddRow(3, 2, 2, 2005, false).
+			AddRow(4, 2, 2, 1950, false))
+	mock.ExpectQuery(`FROM "age_groups"`).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "label"}).AddRow(1, "All ages").AddRow(2, "Kids (5-8 years)"))
+	mock.ExpectQuery(`FROM "characters"`).WillReturnRows(sqlmock.NewRows([]string{"cartoon_id", "name"}))
+	mock.ExpectQuery(`WITH engaged AS`).WithArgs(1).WillReturnRows(
+		sqlmock.NewRows([]string{"cartoon_id", "users"}).AddRow(3, 4))
+}
+
+func TestComputeRelatedScores(t *testing.T) {
+	mock, _ := mockDB(t)
+	expectRelatedQueries(mock)
+
+	related, err := computeRelated(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("computeRelated: %v", err)
+	}
+	// 2: genre 0.5 + same year 0.2; 3: five years apart 0.1 + co-engagement 0.5
+	// 4: nothing in common and released too far apart
+	want := []RelatedCartoon{{CartoonID: 2, Score: 0.7}, {CartoonID: 3, Score: 0.6}}
+	if len(related) != len(want) {
+		t.Fatalf("computeRelated = %+v, want %+v", related, want)
+	}
+	for i := range want {
+		if related[i].CartoonID != want[i].CartoonID || related[i].Score-want[i].Score > 1e-9 || want[i].Score-related[i].Score > 1e-9 {
+			t.Errorf("computeRelated[%d] = %+v, want %+v", i, related[i], want[i])
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestComputeRelatedUnknownCartoon(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(`FROM "cartoons"`).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "genre_id", "age_group_id", "release_year", "is_featured"}))
+	mock.ExpectQuery(`FROM "characters"`).WillReturnRows(sqlmock.NewRows([]string{"cartoon_id", "name"}))
+
+	if _, err := computeRelated(context.Background(), 9); err == nil {
+		t.Error("computeRelated accepted a cartoon missing from the catalogue")
+	}
+}
+
+func TestGetRelatedCartoonsCachesUntilInvalidated(t *testing.T) {
+	server := mockRedis(t)
+	useRelatedCache(t, server.Addr())
+	mock, queries := mockDB(t)
+	ctx := context.Background()
+
+	expectRelatedQueries(mock)
+	first, err := GetRelatedCartoons(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("GetRelatedCartoons: %v", err)
+	}
+	if len(first) != 1 || first[0].CartoonID != 2 {
+		t.Fatalf("GetRelatedCartoons(limit 1) = %+v, want cartoon 2", first)
+	}
+
+	// Served from the cache: the full list was cached, not just the first page
+	computed := *queries
+	cached, err := GetRelatedCartoons(ctx, 1, 10)
+	if err != nil {
+		t.Fatalf("GetRelatedCartoons: %v", err)
+	}
+	if *queries != computed || len(cached) != 2 {
+		t.Errorf("cached lookup ran %d queries and returned %+v", *queries-computed, cached)
+	}
+
+	// A catalogue change computes the list again
+	InvalidateRelatedCache()
+	expectRelatedQueries(mock)
+	if _, err := GetRelatedCartoons(ctx, 1, 10); err != nil {
+		t.Fatalf("GetRelatedCartoons: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetRelatedCartoonsFollowsOtherReplicas(t *testing.T) {
+	server := mockRedis(t)
+	useRelatedCache(t, server.Addr())
+	mock, _ := mockDB(t)
+	ctx := context.Background()
+
+	expectRelatedQueries(mock)
+	GetRelatedCartoons(ctx, 1, 10)
+
+	// Another replica changed the catalogue; this one's memory copy is not served
+	server.Incr(relatedVersionKey, 1)
+	expectRelatedQueries(mock)
+	GetRelatedCartoons(ctx, 1, 10)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetRelatedCartoonsWithRedisDown(t *testing.T) {
+	server := mockRedis(t)
+	remote := useRelatedCache(t, server.Addr())
+	mock, queries := mockDB(t)
+	ctx := context.Background()
+
+	expectRelatedQueries(mock)
+	GetRelatedCartoons(ctx, 1, 10)
+
+	// Lists are still cached in memory, and invalidated, while Redis is down
+	server.Close()
+	InvalidateRelatedCache()
+	expectRelatedQueries(mock)
+	for i := 0; i < 2; i++ {
+		if related, err := GetRelatedCartoons(ctx, 1, 10); err != nil || len(related) != 2 {
+			t.Fatalf("GetRelatedCartoons with Redis down = %+v, %v", related, err)
+		}
+	}
+	if *queries != 8 {
+		t.Errorf("%d queries, want the list computed twice", *queries)
+	}
+
+	// The missed invalidation reaches Redis once it is back, so the list Redis
+	// kept from before the change is not served
+	server.Restart()
+	deadline := time.Now().Add(2 * time.Second)
+	for !remote.Available() {
+		if time.Now().After(deadline) {
+			t.Fatal("Redis cache did not reconnect")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	expectRelatedQueries(mock)
+	GetRelatedCartoons(ctx, 1, 10)
+	if version, _ := server.Get(relatedVersionKey); version != "1" {
+		t.Errorf("shared version %q, want the pending invalidation applied", version)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,411 @@
+package services
+
+import (
+	"context"
+	"disney/cache"
+	"disney/config"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	// ErrCircuitOpen is returned while the circuit breaker blocks calls to a failing provider
+	ErrCircuitOpen = fmt.Errorf("%w: circuit breaker open", ErrMetadataUnavailable)
+	// ErrQuotaExceeded is returned once the provider's daily call budget is spent
+	ErrQuotaExceeded = fmt.Errorf("%w: daily quota exceeded", ErrMetadataUnavailable)
+)
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// ResilienceOptions configures a ResilientMetadataProvider
+type ResilienceOptions struct {
+	// MaxConcurrent caps simultaneous calls to the provider
+	MaxConcurrent int
+	// FailureThreshold is the number of consecutive failures that opens the breaker
+	FailureThreshold int
+	// OpenDuration is how long the breaker stays open before a trial call
+	OpenDuration time.Duration
+	// NotFoundTTL is how long "not found" answers are cached
+	NotFoundTTL time.Duration
+	// DailyQuota caps provider calls per UTC day (0 means unlimited)
+	DailyQuota int
+	// CallTimeout bounds one provider call
+	CallTimeout time.Duration
+}
+
+// NewResilienceOptions returns the resilience options configured in cfg
+// Provider calls time out after 10 seconds
+func NewResilienceOptions(cfg config.MetadataConfig) ResilienceOptions {
+	return ResilienceOptions{
+		MaxConcurrent:    cfg.MaxConcurrent,
+		FailureThreshold: cfg.BreakerThreshold,
+		OpenDuration:     time.Duration(cfg.BreakerCooldown),
+		NotFoundTTL:      time.Duration(cfg.NotFoundTTL),
+		DailyQuota:       cfg.DailyQuota,
+		CallTimeout:      10 * time.Second,
+	}
+}
+
+// MetadataProviderStatus describes a provider's resilience state for admins
+type MetadataProviderStatus struct {
+	Provider            string     `json:"provider"`
+	BreakerState        string     `json:"breaker_state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	BreakerOpenUntil    *time.Time `json:"breaker_open_until,omitempty"`
+	InFlight            int64      `json:"in_flight"`
+	MaxConcurrent       int        `json:"max_concurrent"`
+	QuotaUsed           int64      `json:"quota_used"`
+	QuotaLimit          int        `json:"quota_limit"`
+	QuotaResetsAt       time.Time  `json:"quota_resets_at"`
+	Calls               int64      `json:"calls"`
+	Failures            int64      `json:"failures"`
+	Coalesced           int64      `json:"coalesced"`
+	NotFoundCacheHits   int64      `json:"not_found_cache_hits"`
+	Rejected            int64      `json:"rejected"`
+}
+
+// MetadataStatusReporter is implemented by providers that can report their state
+type MetadataStatusReporter interface {
+	Status(ctx context.Context) MetadataProviderStatus
+}
+
+// ResilientMetadataProvider protects a remote provider and its quota:
+// concurrent lookups of the same title share one call, calls are capped in
+// number and in concurrency, "not found" answers are cached for a while, and a
+// circuit breaker stops calls while the provider keeps failing
+// "Not found" answers are kept in the given cache; the quota counter lives in
+// Redis when a client is given, so replicas share it, and in memory otherwise
+type ResilientMetadataProvider struct {
+	provider MetadataProvider
+	cache    cache.Cache
+	client   *redis.Client
+	opts     ResilienceOptions
+
+	group   singleflight.Group
+	slots   chan struct{}
+	breaker circuitBreaker
+
+	// memory fallback for the quota when Redis is unavailable
+	mu        sync.Mutex
+	quotaDay  string
+	quotaUsed int64
+
+	inFlight, calls, failures, notFoundHits, rejected atomic.Int64
+	// lookups - shared calls = lookups that joined a call already in flight
+	lookups, sharedCalls atomic.Int64
+}
+
+// NewResilientMetadataProvider wraps provider; client may be nil
+func NewResilientMetadataProvider(provider MetadataProvider, c cache.Cache, client *redis.Client, opts ResilienceOptions) *ResilientMetadataProvider {
+	if opts.MaxConcurrent <= 0 {
+		opts.MaxConcurrent = 1
+	}
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = 5
+	}
+	if opts.OpenDuration <= 0 {
+		opts.OpenDuration = time.Minute
+	}
+	if opts.CallTimeout <= 0 {
+		opts.CallTimeout = 10 * time.Second
+	}
+	return &ResilientMetadataProvider{
+		provider: provider,
+		cache:    c,
+		client:   client,
+		opts:     opts,
+		slots:    make(chan struct{}, opts.MaxConcurrent),
+		breaker:  circuitBreaker{threshold: opts.FailureThreshold, openFor: opts.OpenDuration},
+	}
+}
+
+// Name returns the wrapped provider's name
+func (p *ResilientMetadataProvider) Name() string {
+	return p.provider.Name()
+}
+
+// Lookup returns the provider's answer, sharing one call among concurrent identical queries
+func (p *ResilientMetadataProvider) Lookup(ctx context.Context, query MetadataQuery) (*Metadata, error) {
+	key := query.cacheKey()
+	if p.isNotFound(ctx, key) {
+		p.notFoundHits.Add(1)
+		return nil, ErrMetadataNotFound
+	}
+
+	p.lookups.Add(1)
+	result := p.group.DoChan(key, func() (interface{}, error) {
+		p.sharedCalls.Add(1)
+		// The call is shared, so it must not end when the first caller goes away
+		callCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), p.opts.CallTimeout)
+		defer cancel()
+		return p.call(callCtx, key, query)
+	})
+
+	select {
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*Metadata), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// call makes one guarded provider call
+func (p *ResilientMetadataProvider) call(ctx context.Context, key string, query MetadataQuery) (*Metadata, error) {
+	if !p.breaker.allow() {
+		p.rejected.Add(1)
+		return nil, ErrCircuitOpen
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+		defer func() { <-p.slots }()
+	case <-ctx.Done():
+		p.breaker.release()
+		return nil, ctx.Err()
+	}
+
+	if !p.takeQuota(ctx) {
+		p.breaker.release()
+		p.rejected.Add(1)
+		return nil, ErrQuotaExceeded
+	}
+
+	p.inFlight.Add(1)
+	p.calls.Add(1)
+	metadata, err := p.provider.Lookup(ctx, query)
+	p.inFlight.Add(-1)
+
+	switch {
+	case err == nil:
+		p.breaker.success()
+	case errors.Is(err, ErrMetadataNotFound):
+		// The provider answered, so it is healthy
+		p.breaker.success()
+		p.rememberNotFound(ctx, key)
+	case errors.Is(err, ErrMetadataUnavailable):
+		// Misconfiguration (e.g. no API key) is not an outage
+		p.breaker.release()
+	default:
+		p.failures.Add(1)
+		if p.breaker.failure() {
+			log.Printf("WARNING: %s metadata provider failing, circuit breaker open for %s: %v",
+				p.provider.Name(), p.opts.OpenDuration, err)
+		}
+	}
+	return metadata, err
+}
+
+// notFoundKey is the cache key remembering that a query found nothing
+func (p *ResilientMetadataProvider) notFoundKey(key string) string {
+	return "metadata:notfound:" + p.provider.Name() + ":" + key
+}
+
+// isNotFound reports whether the query recently found nothing
+func (p *ResilientMetadataProvider) isNotFound(ctx context.Context, key string) bool {
+	if p.opts.NotFoundTTL <= 0 {
+		return false
+	}
+	_, err := p.cache.Get(ctx, p.notFoundKey(key))
+	return err == nil
+}
+
+// rememberNotFound caches a "not found" answer for NotFoundTTL
+func (p *ResilientMetadataProvider) rememberNotFound(ctx context.Context, key string) {
+	if p.opts.NotFoundTTL <= 0 {
+		return
+	}
+	p.cache.Set(ctx, p.notFoundKey(key), []byte("1"), p.opts.NotFoundTTL)
+}
+
+// quotaKey is the Redis key counting today's calls
+func (p *ResilientMetadataProvider) quotaKey(day string) string {
+	return "metadata:quota:" + p.provider.Name() + ":" + day
+}
+
+// takeQuota counts a call against today's budget; false if the budget is spent
+func (p *ResilientMetadataProvider) takeQuota(ctx context.Context) bool {
+	if p.opts.DailyQuota <= 0 {
+		return true
+	}
+	day := time.Now().UTC().Format("2006-01-02")
+
+	if p.client != nil {
+		key := p.quotaKey(day)
+		used, err := p.client.Incr(ctx, key).Result()
+		if err == nil {
+			if used == 1 {
+				p.client.Expire(ctx, key, 48*time.Hour)
+			}
+			return used <= int64(p.opts.DailyQuota)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.quotaDay != day {
+		p.quotaDay, p.quotaUsed = day, 0
+	}
+	if p.quotaUsed >= int64(p.opts.DailyQuota) {
+		return false
+	}
+	p.quotaUsed++
+	return true
+}
+
+// quotaUsedToday returns today's call count
+func (p *ResilientMetadataProvider) quotaUsedToday(ctx context.Context) int64 {
+	day := time.Now().UTC().Format("2006-01-02")
+	if p.client != nil {
+		if used, err := p.client.Get(ctx, p.quotaKey(day)).Int64(); err == nil || errors.Is(err, redis.Nil) {
+			return min(used, int64(p.opts.DailyQuota))
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.quotaDay != day {
+		return 0
+	}
+	return p.quotaUsed
+}
+
+// Status reports the breaker, concurrency, quota and counters
+func (p *ResilientMetadataProvider) Status(ctx context.Context) MetadataProviderStatus {
+	state, failures, openUntil := p.breaker.state()
+	now := time.Now().UTC()
+
+	status := MetadataProviderStatus{
+		Provider:            p.provider.Name(),
+		BreakerState:        state,
+		ConsecutiveFailures: failures,
+		InFlight:            p.inFlight.Load(),
+		MaxConcurrent:       p.opts.MaxConcurrent,
+		QuotaUsed:           p.quotaUsedToday(ctx),
+		QuotaLimit:          p.opts.DailyQuota,
+		QuotaResetsAt:       time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC),
+		Calls:               p.calls.Load(),
+		Failures:            p.failures.Load(),
+		Coalesced:           p.lookups.Load() - p.sharedCalls.Load(),
+		NotFoundCacheHits:   p.notFoundHits.Load(),
+		Rejected:            p.rejected.Load(),
+	}
+	if state != BreakerClosed {
+		status.BreakerOpenUntil = &openUntil
+	}
+	return status
+}
+
+// MetadataResult is one answer of LookupMany
+type MetadataResult struct {
+	Metadata *Metadata
+	Err      error
+}
+
+// LookupMany looks up several titles with at most concurrency lookups at a time
+// Results are in the order of queries
+func LookupMany(ctx context.Context, provider MetadataProvider, queries []MetadataQuery, concurrency int) []MetadataResult {
+	results := make([]MetadataResult, len(queries))
+
+	var group errgroup.Group
+	group.SetLimit(max(concurrency, 1))
+	for i, query := range queries {
+		group.Go(func() error {
+			metadata, err := provider.Lookup(ctx, query)
+			results[i] = MetadataResult{Metadata: metadata, Err: err}
+			return nil
+		})
+	}
+	group.Wait()
+	return results
+}
+
+// circuitBreaker opens after threshold consecutive failures and lets a single
+// trial call through once openFor has passed (half-open)
+type circuitBreaker struct {
+	threshold int
+	openFor   time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+// allow reports whether a call may go ahead; in half-open state only one trial runs at a time
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if time.Now().Before(b.openUntil) || b.trial {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// success closes the breaker
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.trial = false
+}
+
+// release ends a call that says nothing about the provider's health
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+// failure records a failed call and reports whether it opened the breaker
+// Late failures from calls started before the breaker opened leave the open
+// window as it is
+func (b *circuitBreaker) failure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	open := b.failures >= b.threshold && now.Before(b.openUntil)
+	b.failures++
+	b.trial = false
+	if open || b.failures < b.threshold {
+		return false
+	}
+	b.openUntil = now.Add(b.openFor)
+	return true
+}
+
+// state returns the breaker state, the consecutive failure count and when it may close
+func (b *circuitBreaker) state() (string, int, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case b.failures < b.threshold:
+		return BreakerClosed, b.failures, time.Time{}
+	case time.Now().Before(b.openUntil):
+		return BreakerOpen, b.failures, b.openUntil
+	default:
+		return BreakerHalfOpen, b.failures, b.openUntil
+	}
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"disney/cache"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// gatedProvider holds every lookup until release is closed and tracks concurrency
+type gatedProvider struct {
+	release  chan struct{}
+	calls    atomic.Int32
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (p *gatedProvider) Name() string { return "gated" }
+
+func (p *gatedProvider) Lookup(ctx context.Context, query MetadataQuery) (*Metadata, error) {
+	p.calls.Add(1)
+	current := p.inFlight.Add(1)
+	defer p.inFlight.Add(-1)
+	for {
+		peak := p.peak.Load()
+		if current <= peak || p.peak.CompareAndSwap(peak, current) {
+			break
+		}
+	}
+	<-p.release
+	return &Metadata{Title: query.Title}, nil
+}
+
+// resilient wraps provider with test-friendly defaults
+func resilient(provider MetadataProvider, opts ResilienceOptions) *ResilientMetadataProvider {
+	if opts.MaxConcurrent == 0 {
+		opts.MaxConcurrent = 4
+	}
+	return NewResilientMetadataProvider(provider, cache.NewMemory(100), nil, opts)
+}
+
+func TestCircuitBreakerStates(t *testing.T) {
+	b := circuitBreaker{threshold: 2, openFor: 20 * time.Millisecond}
+
+	b.failure()
+	if state, failures, _ := b.state(); state != BreakerClosed || failures != 1 || !b.allow() {
+		t.Fatalf("after one failure: %s with %d failures", state, failures)
+	}
+	if !b.failure() {
+		t.Error("threshold failure did not open the breaker")
+	}
+	if state, _, _ := b.state(); state != BreakerOpen || b.allow() {
+		t.Fatalf("breaker %s allowed a call while open", state)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if state, _, _ := b.state(); state != BreakerHalfOpen {
+		t.Fatalf("state %s after the cooldown, want half open", state)
+	}
+	if !b.allow() || b.allow() {
+		t.Fatal("half open breaker must allow exactly one trial")
+	}
+	// A trial that says nothing about health lets another trial through
+	b.release()
+	if !b.allow() {
+		t.Fatal("released trial blocked the next one")
+	}
+	// A failed trial reopens the breaker
+	b.failure()
+	if state, _, _ := b.state(); state != BreakerOpen {
+		t.Fatalf("state %s after a failed trial, want open", state)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	b.allow()
+	b.success()
+	if state, failures, _ := b.state(); state != BreakerClosed || failures != 0 {
+		t.Errorf("state %s with %d failures after a successful trial, want closed", state, failures)
+	}
+}
+
+func TestCircuitBreakerOpensOnce(t *testing.T) {
+	b := circuitBreaker{threshold: 2, openFor: 20 * time.Millisecond}
+	b.failure()
+	if !b.failure() {
+		t.Fatal("threshold failure did not open the breaker")
+	}
+	_, _, openUntil := b.state()
+
+	// Calls that were in flight when the breaker opened fail late
+	time.Sleep(5 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if b.failure() {
+			t.Error("late failure reported the open breaker opening again")
+		}
+	}
+	if _, _, until := b.state(); !until.Equal(openUntil) {
+		t.Errorf("late failures moved the open window from %s to %s", openUntil, until)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	b.allow()
+	if !b.failure() {
+		t.Error("failed half open trial did not report the breaker reopening")
+	}
+	if state, _, until := b.state(); state != BreakerOpen || !until.After(openUntil) {
+		t.Errorf("state %s until %s after a failed trial, want open past %s", state, until, openUntil)
+	}
+}
+
+func TestResilientProviderOpensBreakerOnFailures(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubProvider{err: errors.New("connection reset")}
+	provider := resilient(stub, ResilienceOptions{FailureThreshold: 2, OpenDuration: time.Hour})
+
+	for i := 0; i < 2; i++ {
+		provider.Lookup(ctx, MetadataQuery{Title: "DuckTales"})
+	}
+	_, err := provider.Lookup(ctx, MetadataQuery{Title: "DuckTales"})
+	if !errors.Is(err, ErrCircuitOpen) || !errors.Is(err, ErrMetadataUnavailable) {
+		t.Errorf("lookup with open breaker: %v, want ErrCircuitOpen", err)
+	}
+	status := provider.Status(ctx)
+	if stub.calls != 2 || status.BreakerState != BreakerOpen || status.Failures != 2 || status.Rejected != 1 || status.BreakerOpenUntil == nil {
+		t.Errorf("%d calls, status %+v", stub.calls, status)
+	}
+}
+
+func TestResilientProviderIgnoresUnavailableForBreaker(t *testing.T) {
+	stub := &stubProvider{err: ErrMetadataUnavailable}
+	provider := resilient(stub, ResilienceOptions{FailureThreshold: 1})
+	for i := 0; i < 3; i++ {
+		provider.Lookup(context.Background(), MetadataQuery{Title: "DuckTales"})
+	}
+	if state := provider.Status(context.Background()).BreakerState; stub.calls != 3 || state != BreakerClosed {
+		t.Errorf("%d calls with breaker %s, want every call made and the breaker closed", stub.calls, state)
+	}
+}
+
+func TestResilientProviderCachesNotFound(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubProvider{err: ErrMetadataNotFound}
+	provider := resilient(stub, ResilienceOptions{NotFoundTTL: time.Hour})
+
+	for i := 0; i < 3; i++ {
+		if _, err := provider.Lookup(ctx, MetadataQuery{Title: "Recess"}); !errors.Is(err, ErrMetadataNotFound) {
+			t.Fatalf("Lookup: %v, want ErrMetadataNotFound", err)
+		}
+	}
+	provider.Lookup(ctx, MetadataQuery{Title: "Recess", Year: 1997})
+	if hits := provider.Status(ctx).NotFoundCacheHits; stub.calls != 2 || hits != 2 {
+		t.Errorf("%d calls and %d cache hits, want 2 and 2", stub.calls, hits)
+	}
+}
+
+func TestResilientProviderEnforcesDailyQuota(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubProvider{metadata: &Metadata{Title: "DuckTales"}}
+	provider := resilient(stub, ResilienceOptions{DailyQuota: 2})
+
+	for _, title := range []string{"a", "b"} {
+		if _, err := provider.Lookup(ctx, MetadataQuery{Title: title}); err != nil {
+			t.Fatalf("Lookup(%s): %v", title, err)
+		}
+	}
+	if _, err := provider.Lookup(ctx, MetadataQuery{Title: "c"}); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("lookup over quota: %v, want ErrQuotaExceeded", err)
+	}
+	if status := provider.Status(ctx); stub.calls != 2 || status.QuotaUsed != 2 || status.BreakerState != BreakerClosed {
+		t.Errorf("%d calls, status %+v", stub.calls, status)
+	}
+}
+
+func TestResilientProviderSharesQuotaThroughRedis(t *testing.T) {
+	server := mockRedis(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	ctx := context.Background()
+
+	opts := ResilienceOptions{MaxConcurrent: 1, DailyQuota: 3}
+	first := NewResilientMetadataProvider(&stubProvider{metadata: &Metadata{}}, cache.NewMemory(10), client, opts)
+	second := NewResilientMetadataProvider(&stubProvider{metadata: &Metadata{}}, cache.NewMemory(10), client, opts)
+
+	first.Lookup(ctx, MetadataQuery{Title: "a"})
+	first.Lookup(ctx, MetadataQuery{Title: "b"})
+	second.Lookup(ctx, MetadataQuery{Title: "c"})
+	if _, err := second.Lookup(ctx, MetadataQuery{Title: "d"}); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("fourth lookup across replicas: %v, want ErrQuotaExceeded", err)
+	}
+	if used := first.Status(ctx).QuotaUsed; used != 3 {
+		t.Errorf("quota used %d, want 3", used)
+	}
+	key := first.quotaKey(time.Now().UTC().Format("2006-01-02"))
+	if ttl := server.TTL(key); ttl != 48*time.Hour {
+		t.Errorf("quota key TTL %s, want 48h", ttl)
+	}
+}
+
+func TestResilientProviderCoalescesConcurrentLookups(t *testing.T) {
+	gated := &gatedProvider{release: make(chan struct{})}
+	provider := resilient(gated, ResilienceOptions{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if metadata, err := provider.Lookup(context.Background(), MetadataQuery{Title: "DuckTales"}); err != nil || metadata.Title != "DuckTales" {
+				t.Errorf("Lookup = %+v, %v", metadata, err)
+			}
+		}()
+	}
+	// Let every caller join the call before it finishes
+	for provider.lookups.Load() < 5 {
+		time.Sleep(time.Millisecond)
+	}
+	close(gated.release)
+	wg.Wait()
+
+	if coalesced := provider.Status(context.Background()).Coalesced; gated.calls.Load() != 1 || coalesced != 4 {
+		t.Errorf("%d calls with %d coalesced, want 1 and 4", gated.calls.Load(), coalesced)
+	}
+}
+
+func TestLookupManyLimitsConcurrency(t *testing.T) {
+	gated := &gatedProvider{release: make(chan struct{})}
+	provider := resilient(gated, ResilienceOptions{MaxConcurrent: 2})
+	queries := []MetadataQuery{{Title: "a"}, {Title: "b"}, {Title: "c"}, {Title: "d"}, {Title: "e"}}
+
+	go func() {
+		for gated.calls.Load() < 2 {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(20 * time.Millisecond)
+		close(gated.release)
+	}()
+	results := LookupMany(context.Background(), provider, queries, 5)
+
+	for i, result := range results {
+		if result.Err != nil || result.Metadata.Title != queries[i].Title {
+			t.Errorf("result %d = %+v, want %s", i, result, queries[i].Title)
+		}
+	}
+	if peak := gated.peak.Load(); peak != 2 {
+		t.Errorf("peak of %d concurrent calls, want 2", peak)
+	}
+}
@@ -0,0 +1,370 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"disney/cache"
+	"disney/models"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// responseKeyPrefix is the prefix for cached responses in Redis
+	responseKeyPrefix = "response:"
+	// responseTagPrefix is the prefix for the Redis sets listing the responses cached under a tag
+	responseTagPrefix = "response:tag:"
+	// responseInvalidateChannel carries invalidated tags to every replica
+	responseInvalidateChannel = "response:invalidate"
+
+	// TagCartoonNames tags the list of all cartoon names
+	TagCartoonNames = "list:names"
+	// TagCharacterLists tags cartoon lists filtered by character name
+	TagCharacterLists = "list:by-character"
+	// TagGenreLists tags cartoon lists filtered by genre name, whose matching genres change when genres are added
+	TagGenreLists = "list:by-genre"
+)
+
+// CartoonTag tags responses containing a cartoon
+func CartoonTag(id uint) string {
+	return fmt.Sprintf("cartoon:%d", id)
+}
+
+// GenreTag tags cartoon lists of a genre
+func GenreTag(id uint) string {
+	return fmt.Sprintf("genre:%d", id)
+}
+
+// AgeGroupTag tags cartoon lists of an age group
+func AgeGroupTag(id uint) string {
+	return fmt.Sprintf("age-group:%d", id)
+}
+
+// YearTag tags cartoon lists of a release year
+func YearTag(year int) string {
+	return fmt.Sprintf("year:%d", year)
+}
+
+// CartoonResponseTags returns the tags of every cached response a change to
+// the cartoon can affect: its own, the names list and the lists it belongs to
+func CartoonResponseTags(cartoon models.Cartoon) []string {
+	return []string{
+		CartoonTag(cartoon.ID),
+		TagCartoonNames,
+		GenreTag(cartoon.GenreID),
+		AgeGroupTag(cartoon.AgeGroupID),
+		YearTag(cartoon.ReleaseYear),
+	}
+}
+
+// ResponseCache caches serialised API responses under tags
+// Each replica keeps recent responses in memory in front of Redis; Redis also
+// keeps a set of response keys per tag. Invalidating a tag deletes its Redis
+// entries and publishes the tag so every replica drops its local copies
+// Without Redis, or while it is down, responses are cached in memory only
+// (Redis calls then fail fast, see cache.Redis)
+// A response computed while its data changes may be cached stale; the TTL bounds that
+type ResponseCache struct {
+	local  *cache.Memory
+	remote *cache.Redis
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	stats map[string]*responseCounters
+
+	invalidations atomic.Int64
+}
+
+// responseCounters counts lookups of one kind of response
+type responseCounters struct {
+	hits, misses int64
+}
+
+// ResponseEntry is a serialised API response
+type ResponseEntry struct {
+	Body []byte
+	// LastModified is when the response's data last changed, zero if unknown
+	LastModified time.Time
+}
+
+// ResponseCacheStats describes the response cache for admins
+type ResponseCacheStats struct {
+	Backend       string                      `json:"backend"`
+	TTLSeconds    int64                       `json:"ttl_seconds"`
+	LocalEntries  int                         `json:"local_entries"`
+	Invalidations int64                       `json:"invalidations"`
+	Hits          int64                       `json:"hits"`
+	Misses        int64                       `json:"misses"`
+	HitRate       float64                     `json:"hit_rate"`
+	Responses     map[string]ResponseHitStats `json:"responses"`
+}
+
+// ResponseHitStats counts the lookups of one kind of response on this replica
+type ResponseHitStats struct {
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// NewResponseCache creates a response cache; remote may be nil
+// With remote it subscribes to invalidations from other replicas until Close
+func NewResponseCache(remote *cache.Redis, ttl time.Duration, localEntries int) *ResponseCache {
+	rc := &ResponseCache{
+		local:  cache.NewMemory(localEntries),
+		remote: remote,
+		ttl:    ttl,
+		stats:  map[string]*responseCounters{},
+	}
+	if remote != nil {
+		// Drop local copies of tags invalidated on any replica
+		remote.Subscribe(responseInvalidateChannel, func(payload string) {
+			rc.dropLocal(strings.Split(payload, ","))
+		})
+	}
+	return rc
+}
+
+// Close stops listening for invalidations and closes the Redis cache
+func (rc *ResponseCache) Close() error {
+	if rc.remote != nil {
+		return rc.remote.Close()
+	}
+	return nil
+}
+
+// encodeResponse stores a response with its tags:
+// "tag1,tag2\n<last modified, Unix seconds or empty>\n<body>"
+func encodeResponse(tags []string, entry ResponseEntry) []byte {
+	value := make([]byte, 0, len(entry.Body)+64)
+	value = append(value, strings.Join(tags, ",")...)
+	value = append(value, '\n')
+	if !entry.LastModified.IsZero() {
+		value = strconv.AppendInt(value, entry.LastModified.Unix(), 10)
+	}
+	value = append(value, '\n')
+	return append(value, entry.Body...)
+}
+
+// decodeResponse splits a stored response into its tags and entry
+func decodeResponse(value []byte) ([]string, ResponseEntry, bool) {
+	header, rest, ok := bytes.Cut(value, []byte{'\n'})
+	if !ok {
+		return nil, ResponseEntry{}, false
+	}
+	modified, body, ok := bytes.Cut(rest, []byte{'\n'})
+	if !ok {
+		return nil, ResponseEntry{}, false
+	}
+
+	entry := ResponseEntry{Body: body}
+	if len(modified) > 0 {
+		seconds, err := strconv.ParseInt(string(modified), 10, 64)
+		if err != nil {
+			return nil, ResponseEntry{}, false
+		}
+		entry.LastModified = time.Unix(seconds, 0).UTC()
+	}
+	return strings.Split(string(header), ","), entry, true
+}
+
+// Get returns the cached response of key, counting a hit or miss under name
+func (rc *ResponseCache) Get(ctx context.Context, name, key string) (ResponseEntry, bool) {
+	value, err := rc.local.Get(ctx, key)
+	if err != nil && rc.remote != nil {
+		if value, err = rc.remote.Get(ctx, responseKeyPrefix+key); err == nil {
+			rc.local.Set(ctx, key, value, rc.ttl)
+		}
+	}
+
+	var entry ResponseEntry
+	ok := false
+	if err == nil {
+		_, entry, ok = decodeResponse(value)
+	}
+	rc.count(name, ok)
+	return entry, ok
+}
+
+// Set caches a response under key and tags
+// Redis errors are ignored; the response is then cached on this replica only
+func (rc *ResponseCache) Set(ctx context.Context, key string, tags []string, entry ResponseEntry) {
+	value := encodeResponse(tags, entry)
+	rc.local.Set(ctx, key, value, rc.ttl)
+	if rc.remote == nil {
+		return
+	}
+
+	err := rc.remote.Do(ctx, func(client *redis.Client) error {
+		_, err := client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, responseKeyPrefix+key, value, rc.ttl)
+			for _, tag := range tags {
+				pipe.SAdd(ctx, responseTagPrefix+tag, key)
+				// The tag set outlives the responses it lists, so invalidation still finds them
+				pipe.Expire(ctx, responseTagPrefix+tag, rc.ttl+time.Minute)
+			}
+			return nil
+		})
+		return err
+	})
+	// Redis being down was already reported by the Redis cache
+	if err != nil && !errors.Is(err, cache.ErrUnavailable) {
+		log.Printf("WARNING: Failed to cache response %s in Redis: %v", key, err)
+	}
+}
+
+// Invalidate drops every response cached under any of tags, on every replica
+func (rc *ResponseCache) Invalidate(ctx context.Context, tags ...string) {
+	if len(tags) == 0 {
+		return
+	}
+	rc.invalidations.Add(1)
+	rc.dropLocal(tags)
+	if rc.remote == nil {
+		return
+	}
+
+	err := rc.remote.Do(ctx, func(client *redis.Client) error {
+		for _, tag := range tags {
+			keys, err := client.SMembers(ctx, responseTagPrefix+tag).Result()
+			if err != nil {
+				return err
+			}
+			toDelete := []string{responseTagPrefix + tag}
+			for _, key := range keys {
+				toDelete = append(toDelete, responseKeyPrefix+key)
+			}
+			if err := client.Del(ctx, toDelete...).Err(); err != nil {
+				return err
+			}
+		}
+		return client.Publish(ctx, responseInvalidateChannel, strings.Join(tags, ",")).Err()
+	})
+	if err != nil {
+		// While Redis is down other replicas keep their copies until the TTL
+		log.Printf("WARNING: Failed to invalidate cached responses tagged %s: %v", strings.Join(tags, ","), err)
+	}
+}
+
+// dropLocal removes this replica's copies of responses tagged with any of tags
+func (rc *ResponseCache) dropLocal(tags []string) {
+	rc.local.DeleteFunc(func(key string, value []byte) bool {
+		entryTags, _, ok := decodeResponse(value)
+		return !ok || slices.ContainsFunc(entryTags, func(tag string) bool {
+			return slices.Contains(tags, tag)
+		})
+	})
+}
+
+// count records a lookup of a kind of response
+func (rc *ResponseCache) count(name string, hit bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	counters, ok := rc.stats[name]
+	if !ok {
+		counters = &responseCounters{}
+		rc.stats[name] = counters
+	}
+	if hit {
+		counters.hits++
+	} else {
+		counters.misses++
+	}
+}
+
+// Stats returns this replica's hit rates per kind of response and overall
+func (rc *ResponseCache) Stats() ResponseCacheStats {
+	stats := ResponseCacheStats{
+		Backend:       "memory",
+		TTLSeconds:    int64(rc.ttl.Seconds()),
+		LocalEntries:  rc.local.Len(),
+		Invalidations: rc.invalidations.Load(),
+		Responses:     map[string]ResponseHitStats{},
+	}
+	if rc.remote != nil {
+		stats.Backend = "redis"
+		if !rc.remote.Available() {
+			stats.Backend = "memory (redis unavailable)"
+		}
+	}
+
+	rc.mu.Lock()
+	names := make([]string, 0, len(rc.stats))
+	for name := range rc.stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		counters := rc.stats[name]
+		stats.Responses[name] = ResponseHitStats{
+			Hits:    counters.hits,
+			Misses:  counters.misses,
+			HitRate: hitRate(counters.hits, counters.misses),
+		}
+		stats.Hits += counters.hits
+		stats.Misses += counters.misses
+	}
+	rc.mu.Unlock()
+
+	stats.HitRate = hitRate(stats.Hits, stats.Misses)
+	return stats
+}
+
+// hitRate returns hits / lookups, rounded to 3 decimals
+func hitRate(hits, misses int64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits*1000/(hits+misses)) / 1000
+}
+
+var responseCache *ResponseCache
+
+// SetResponseCache sets the response cache for the service
+func SetResponseCache(rc *ResponseCache) {
+	responseCache = rc
+}
+
+// CachedResponse returns a cached response, if response caching is enabled
+func CachedResponse(ctx context.Context, name, key string) (ResponseEntry, bool) {
+	if responseCache == nil {
+		return ResponseEntry{}, false
+	}
+	return responseCache.Get(ctx, name, key)
+}
+
+// CacheResponse caches a response under tags, if response caching is enabled
+func CacheResponse(ctx context.Context, key string, tags []string, entry ResponseEntry) {
+	if responseCache != nil {
+		responseCache.Set(ctx, key, tags, entry)
+	}
+}
+
+// InvalidateResponses drops every cached response tagged with any of tags
+// Called after catalogue changes
+func InvalidateResponses(tags ...string) {
+	if responseCache == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	responseCache.Invalidate(ctx, tags...)
+}
+
+// GetResponseCacheStats returns the response cache's hit rates; ok is false if caching is disabled
+func GetResponseCacheStats() (ResponseCacheStats, bool) {
+	if responseCache == nil {
+		return ResponseCacheStats{}, false
+	}
+	return responseCache.Stats(), true
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"disney/cache"
+	"disney/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisResponseCache returns a response cache on the given Redis server, as one replica
+func redisResponseCache(t *testing.T, addr string) *ResponseCache {
+	t.Helper()
+	options := &redis.Options{Addr: addr, MaxRetries: -1, DialTimeout: 50 * time.Millisecond}
+	client := redis.NewClient(options)
+	rc := NewResponseCache(cache.NewRedis(client, options, 10*time.Millisecond), time.Minute, 100)
+	t.Cleanup(func() {
+		rc.Close()
+		client.Close()
+	})
+	return rc
+}
+
+func TestEncodeDecodeResponse(t *testing.T) {
+	modified := time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)
+	for _, entry := range []ResponseEntry{
+		{Body: []byte("{\"data\":\"a\\nb\"}\n"), LastModified: modified},
+		{Body: []byte("{}")},
+	} {
+		tags, decoded, ok := decodeResponse(encodeResponse([]string{"cartoon:1", "list:names"}, entry))
+		if !ok || !reflect.DeepEqual(tags, []string{"cartoon:1", "list:names"}) ||
+			string(decoded.Body) != string(entry.Body) || !decoded.LastModified.Equal(entry.LastModified) {
+			t.Errorf("round trip of %+v gave %v %+v %v", entry, tags, decoded, ok)
+		}
+	}
+
+	for _, value := range []string{"no newline", "tags only\n", "cartoon:1\nyesterday\n{}"} {
+		if _, _, ok := decodeResponse([]byte(value)); ok {
+			t.Errorf("decodeResponse(%q) accepted a malformed value", value)
+		}
+	}
+}
+
+func TestCartoonResponseTags(t *testing.T) {
+	tags := CartoonResponseTags(models.Cartoon{ID: 3, GenreID: 2, AgeGroupID: 4, ReleaseYear: 1987})
+	want := []string{"cartoon:3", TagCartoonNames, "genre:2", "age-group:4", "year:1987"}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("tags %v, want %v", tags, want)
+	}
+}
+
+func TestResponseCacheInvalidatesByTag(t *testing.T) {
+	ctx := context.Background()
+	rc := NewResponseCache(nil, time.Minute, 100)
+	rc.Set(ctx, "cartoon:3", []string{"cartoon:3"}, ResponseEntry{Body: []byte("three")})
+	rc.Set(ctx, "names", []string{TagCartoonNames}, ResponseEntry{Body: []byte("names")})
+
+	if entry, ok := rc.Get(ctx, "detail", "cartoon:3"); !ok || string(entry.Body) != "three" {
+		t.Fatalf("Get = %q, %v", entry.Body, ok)
+	}
+	rc.Invalidate(ctx, "cartoon:3", "genre:2")
+	if _, ok := rc.Get(ctx, "detail", "cartoon:3"); ok {
+		t.Error("invalidated response still cached")
+	}
+	if _, ok := rc.Get(ctx, "names", "names"); !ok {
+		t.Error("response with other tags was dropped")
+	}
+
+	stats := rc.Stats()
+	if stats.Backend != "memory" || stats.Hits != 2 || stats.Misses != 1 || stats.HitRate != 0.666 || stats.Invalidations != 1 {
+		t.Errorf("stats %+v", stats)
+	}
+	if detail := stats.Responses["detail"]; detail.Hits != 1 || detail.Misses != 1 || detail.HitRate != 0.5 {
+		t.Errorf("detail stats %+v", detail)
+	}
+}
+
+func TestResponseCacheSharesAndInvalidatesAcrossReplicas(t *testing.T) {
+	server := mockRedis(t)
+	ctx := context.Background()
+	first, second := redisResponseCache(t, server.Addr()), redisResponseCache(t, server.Addr())
+	// Both replicas must be listening before anything is invalidated
+	for server.PubSubNumSub(responseInvalidateChannel)[responseInvalidateChannel] < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	first.Set(ctx, "cartoon:3", []string{"cartoon:3"}, ResponseEntry{Body: []byte("three")})
+	if entry, ok := second.Get(ctx, "detail", "cartoon:3"); !ok || string(entry.Body) != "three" {
+		t.Fatalf("second replica Get = %q, %v, want the shared response", entry.Body, ok)
+	}
+	if members, _ := server.SMembers(responseTagPrefix + "cartoon:3"); !reflect.DeepEqual(members, []string{"cartoon:3"}) {
+		t.Errorf("tag set %v", members)
+	}
+
+	first.Invalidate(ctx, "cartoon:3")
+	if server.Exists(responseKeyPrefix+"cartoon:3") || server.Exists(responseTagPrefix+"cartoon:3") {
+		t.Error("invalidation left Redis keys")
+	}
+	// The second replica drops its local copy when the invalidation arrives
+	deadline := time.Now().Add(2 * time.Second)
+	for second.local.Len() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("second replica kept its local copy")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestResponseCacheWithRedisDown(t *testing.T) {
+	server := mockRedis(t)
+	ctx := context.Background()
+	rc := redisResponseCache(t, server.Addr())
+	server.Close()
+
+	rc.Set(ctx, "names", []string{TagCartoonNames}, ResponseEntry{Body: []byte("names")})
+	if _, ok := rc.Get(ctx, "names", "names"); !ok {
+		t.Error("response not cached locally while Redis is down")
+	}
+	rc.Invalidate(ctx, TagCartoonNames)
+	if _, ok := rc.Get(ctx, "names", "names"); ok {
+		t.Error("local copy survived invalidation while Redis is down")
+	}
+	if backend := rc.Stats().Backend; backend != "memory (redis unavailable)" {
+		t.Errorf("backend %q", backend)
+	}
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"disney/config"
+	"disney/database"
+	"disney/models"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultTrendingWindow is used when the client does not ask for a window
+	DefaultTrendingWindow = "7d"
+
+	// Engagement weights; a rating adds up to maxRatingBoost more for the top score
+	trendingViewWeight      = 1.0
+	trendingFavouriteWeight = 3.0
+	trendingRatingWeight    = 1.0
+	maxRatingBoost          = 2.0
+
+	// maxTieCandidates caps how many equally scored cartoons are considered for tie-breaking
+	maxTieCandidates = 20
+)
+
+// TrendingWindow is a time window trending scores are computed over
+// Events lose half their weight every HalfLife, so recent activity counts most
+type TrendingWindow struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+	HalfLife time.Duration `json:"half_life"`
+}
+
+// TrendingEntry is a cartoon's materialised trending score
+type TrendingEntry struct {
+	CartoonID uint    `json:"cartoon_id"`
+	Score     float64 `json:"score"`
+}
+
+var trendingWindows, _ = newTrendingWindows([]string{"24h", "7d", "30d"})
+
+// newTrendingWindows builds windows from names such as "24h" or "7d"
+// Each window uses a quarter of its length as the decay half-life
+func newTrendingWindows(names []string) ([]TrendingWindow, error) {
+	windows := make([]TrendingWindow, 0, len(names))
+	for _, name := range names {
+		d, err := config.ParseDuration(name)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid trending window %q", name)
+		}
+		windows = append(windows, TrendingWindow{Name: name, Duration: d, HalfLife: d / 4})
+	}
+	return windows, nil
+}
+
+// SetTrendingWindows sets the windows trending scores are computed over
+func SetTrendingWindows(names []string) error {
+	windows, err := newTrendingWindows(names)
+	if err != nil {
+		return err
+	}
+	trendingWindows = windows
+	return nil
+}
+
+// GetTrendingWindows returns the configured trending windows
+func GetTrendingWindows() []TrendingWindow {
+	return trendingWindows
+}
+
+// FindTrendingWindow looks up a configured window by name
+func FindTrendingWindow(name string) (TrendingWindow, bool) {
+	for _, window := range trendingWindows {
+		if window.Name == name {
+			return window, true
+		}
+	}
+	return TrendingWindow{}, false
+}
+
+// RefreshTrendingScores recomputes and stores the trending scores for every window
+func RefreshTrendingScores(ctx context.Context) error {
+	for _, window := range trendingWindows {
+		if err := refreshTrendingWindow(ctx, window); err != nil {
+			return fmt.Errorf("window %s: %w", window.Name, err)
+		}
+	}
+	return nil
+}
+
+// refreshTrendingWindow replaces the stored scores of one window
+// Each view, favourite and rating inside the window contributes its weight,
+// decayed exponentially by its age
+func refreshTrendingWindow(ctx context.Context, window TrendingWindow) error {
+	now := time.Now()
+	since := now.Add(-window.Duration)
+	scale := GetRatingScale()
+
+	var scores []models.TrendingScore
+	err := database.DB.WithContext(ctx).Raw(`
+		SELECT cartoon_id,
+			ROUND(SUM(weight * EXP(-LN(2) * EXTRACT(EPOCH FROM (@now - at)) / @half_life))::numeric, 6)::float8 AS score,
+			COUNT(*) FILTER (WHERE kind = 'view') AS views,
+			COUNT(*) FILTER (WHERE kind = 'favourite') AS favourites,
+			COUNT(*) FILTER (WHERE kind = 'rating') AS ratings
+		FROM (
+			SELECT cartoon_id, viewed_at AS at, @view_weight AS weight, 'view' AS kind
+			FROM views WHERE viewed_at >= @since
+			UNION ALL
+			SELECT cartoon_id, created_at, @favourite_weight, 'favourite'
+			FROM favourites WHERE created_at >= @since
+			UNION ALL
+			SELECT cartoon_id, COALESCE(updated_at, created_at),
+				@rating_weight + @rating_boost * GREATEST(0, LEAST(1, (rating - @scale_min)::float / @scale_span)),
+				'rating'
+			FROM ratings WHERE COALESCE(updated_at, created_at) >= @since
+		) events
+		GROUP BY cartoon_id
+	`, map[string]interface{}{
+		"now":              now,
+		"since":            since,
+		"half_life":        window.HalfLife.Seconds(),
+		"view_weight":      trendingViewWeight,
+		"favourite_weight": trendingFavouriteWeight,
+		"rating_weight":    trendingRatingWeight,
+		"rating_boost":     maxRatingBoost,
+		"scale_min":        scale.Min,
+		"scale_span":       scale.Max - scale.Min,
+	}).Scan(&scores).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range scores {
+		scores[i].Window = window.Name
+		scores[i].ComputedAt = now
+	}
+
+	// Swap the window's rows atomically so readers never see a half-written ranking
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("time_window = ?", window.Name).Delete(&models.TrendingScore{}).Error; err != nil {
+			return err
+		}
+		if len(scores) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&scores, 500).Error
+	})
+}
+
+// RankTrending returns the top cartoons of a window by materialised score
+// Every cartoon takes part; cartoons without activity score zero
+// Up to maxTieCandidates more cartoons tied with the last entry are appended
+// so callers can break ties before truncating to limit
+func RankTrending(ctx context.Context, window string, limit int) ([]TrendingEntry, error) {
+	base := func() *gorm.DB {
+		return database.DB.WithContext(ctx).Table("cartoons").
+			Select("cartoons.id AS cartoon_id, COALESCE(ts.score, 0) AS score").
+			Joins("LEFT JOIN trending_scores ts ON ts.cartoon_id = cartoons.id AND ts.time_window = ?", window)
+	}
+
+	var entries []TrendingEntry
+	if err := base().Order("score DESC").Order("cartoons.id ASC").
+		Limit(limit).Scan(&entries).Error; err != nil {
+		return nil, err
+	}
+	if len(entries) < limit || limit == 0 {
+		return entries, nil
+	}
+
+	// Extend with further cartoons sharing the boundary score, after the last one
+	// already listed (entries are ordered by ID within a score)
+	last := entries[len(entries)-1]
+	var ties []TrendingEntry
+	if err := base().Where("COALESCE(ts.score, 0) = ? AND cartoons.id > ?", last.Score, last.CartoonID).
+		Order("cartoons.id ASC").Limit(maxTieCandidates).Scan(&ties).Error; err != nil {
+		return nil, err
+	}
+	return append(entries, ties...), nil
+}
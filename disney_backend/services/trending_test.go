@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const rankTrendingQuery = `SELECT cartoons.id AS cartoon_id, COALESCE\(ts.score, 0\) AS score FROM "cartoons" LEFT JOIN trending_scores ts`
+
+func TestNewTrendingWindows(t *testing.T) {
+	windows, err := newTrendingWindows([]string{"24h", "7d"})
+	if err != nil {
+		t.Fatalf("newTrendingWindows: %v", err)
+	}
+	want := []TrendingWindow{
+		{Name: "24h", Duration: 24 * time.Hour, HalfLife: 6 * time.Hour},
+		{Name: "7d", Duration: 7 * 24 * time.Hour, HalfLife: 42 * time.Hour},
+	}
+	if len(windows) != len(want) || windows[0] != want[0] || windows[1] != want[1] {
+		t.Errorf("newTrendingWindows = %+v, want %+v", windows, want)
+	}
+
+	for _, names := range [][]string{{"week"}, {"0h"}, {"-7d"}, {"24h", ""}} {
+		if _, err := newTrendingWindows(names); err == nil {
+			t.Errorf("newTrendingWindows(%q) accepted an invalid window", names)
+		}
+	}
+}
+
+func TestSetTrendingWindows(t *testing.T) {
+	previous := trendingWindows
+	t.Cleanup(func() { trendingWindows = previous })
+
+	if err := SetTrendingWindows([]string{"1h", "90d"}); err != nil {
+		t.Fatalf("SetTrendingWindows: %v", err)
+	}
+	if window, ok := FindTrendingWindow("90d"); !ok || window.Duration != 90*24*time.Hour {
+		t.Errorf("FindTrendingWindow(90d) = %+v, %v", window, ok)
+	}
+	if _, ok := FindTrendingWindow("7d"); ok {
+		t.Error("the replaced 7d window is still configured")
+	}
+
+	// An invalid list leaves the configured windows alone
+	if err := SetTrendingWindows([]string{"1h", "soon"}); err == nil {
+		t.Error("SetTrendingWindows accepted an invalid window")
+	}
+	if len(GetTrendingWindows()) != 2 {
+		t.Errorf("windows changed to %+v", GetTrendingWindows())
+	}
+}
+
+func TestRankTrendingAppendsBoundaryTies(t *testing.T) {
+	mock, _ := mockDB(t)
+	mock.ExpectQuery(rankTrendingQuery+`.* ORDER BY score DESC,cartoons.id ASC LIMIT \$2`).
+		WithArgs("7d", 3).
+		WillReturnRows(sqlmock.NewRows([]string{"cartoon_id", "score"}).
+			AddRow(4, 9.5).AddRow(2, 1.5).AddRow(6, 1.5))
+	// Only cartoons after the last listed one, so 2 and 6 are not listed twice
+	mock.ExpectQuery(rankTrendingQuery+`.* WHERE COALESCE\(ts.score, 0\) = \$2 AND cartoons.id > \$3 ORDER BY cartoons.id ASC LIMIT \$4`).
+		WithArgs("7d", 1.5, 6, maxTieCandidates).
+		WillReturnRows(sqlmock.NewRows([]string{"cartoon_id", "score"}).AddRow(7, 1.5).AddRow(9, 1.5))
+
+	entries, err := RankTrending(context.Background(), "7d", 3)
+	if err != nil {
+		t.Fatalf("RankTrending: %v", err)
+	}
+	want := []uint{4, 2, 6, 7, 9}
+	if len(entries) != len(want) {
+		t.Fatalf("RankTrending = %+v, want cartoons %v", entries, want)
+	}
+	for i, id := range want {
+		if entries[i].CartoonID != id {
+			t.Fatalf("RankTrending = %+v, want cartoons %v", entries, want)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRankTrendingShortListLooksForNoTies(t *testing.T) {
+	mock, queries := mockDB(t)
+	mock.ExpectQuery(rankTrendingQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"cartoon_id", "score"}).AddRow(4, 9.5).AddRow(2, 0))
+
+	entries, err := RankTrending(context.Background(), "24h", 10)
+	if err != nil {
+		t.Fatalf("RankTrending: %v", err)
+	}
+	if len(entries) != 2 || *queries != 1 {
+		t.Errorf("got %d entries from %d queries, want 2 from 1", len(entries), *queries)
+	}
+}
+
+func TestRefreshTrendingWindowReplacesScores(t *testing.T) {
+	mock, _ := mockDB(t)
+	window := TrendingWindow{Name: "24h", Duration: 24 * time.Hour, HalfLife: 6 * time.Hour}
+	mock.ExpectQuery(`FROM views WHERE viewed_at >= \$\d+`).
+		WillReturnRows(sqlmock.NewRows([]string{"cartoon_id", "score", "views", "favourites", "ratings"}).
+			AddRow(3, 4.25, 2, 1, 0))
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "trending_scores" WHERE time_window = \$1`).WithArgs("24h").
+		WillReturnResult(sqlmock.NewResult(0, 5))
+	mock.ExpectQuery(`INSERT INTO "trending_scores"`).
+		WithArgs("24h", 3, 4.25, 2, 1, 0, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	if err := refreshTrendingWindow(context.Background(), window); err != nil {
+		t.Fatalf("refreshTrendingWindow: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -5,11 +5,8 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"os"
 )
 
-var JwtSecretKey = []byte(os.Getenv("JWT_SECRET_KEY"))
-
 // Claims defines the JWT claims structure
 type Claims struct {
 	ID    uint   `json:"id"`
@@ -18,8 +15,18 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
-// GenerateToken generates a JWT token for a user (24-hour expiration)
-func GenerateToken(userID uint, email, role string) (string, error) {
+// TokenIssuer signs and verifies tokens with the configured secret
+type TokenIssuer struct {
+	secret []byte
+}
+
+// NewTokenIssuer creates a TokenIssuer signing with secret
+func NewTokenIssuer(secret string) *TokenIssuer {
+	return &TokenIssuer{secret: []byte(secret)}
+}
+
+// Generate generates a JWT token for a user (24-hour expiration)
+func (t *TokenIssuer) Generate(userID uint, email, role string) (string, error) {
 	expirationTime := time.Now().Add(24 * time.Hour)
 
 	claims := &Claims{
@@ -32,7 +39,7 @@ func GenerateToken(userID uint, email, role string) (string, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(JwtSecretKey)
+	tokenString, err := token.SignedString(t.secret)
 	if err != nil {
 		return "", err
 	}
@@ -40,15 +47,15 @@ func GenerateToken(userID uint, email, role string) (string, error) {
 	return tokenString, nil
 }
 
-// VerifyToken verifies a JWT token and returns the claims
-func VerifyToken(tokenString string) (*Claims, error) {
+// Verify verifies a JWT token and returns the claims
+func (t *TokenIssuer) Verify(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return JwtSecretKey, nil
+		return t.secret, nil
 	})
 
 	if err != nil {
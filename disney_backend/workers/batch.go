@@ -0,0 +1,182 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// BatchHandler processes several jobs at once and returns one result per job, in order
+// If it fails the pool falls back to processing the jobs one by one
+type BatchHandler[T Job] func(ctx context.Context, batch []T) ([]interface{}, error)
+
+// BatchStats are counters describing a batching pool's flushes
+type BatchStats struct {
+	Batches        int64   `json:"batches"`
+	JobsFlushed    int64   `json:"jobs_flushed"`
+	LastBatchSize  int     `json:"last_batch_size"`
+	MaxBatchSize   int     `json:"max_batch_size"`
+	AvgBatchSize   float64 `json:"avg_batch_size"`
+	LastFlushMs    float64 `json:"last_flush_ms"`
+	MaxFlushMs     float64 `json:"max_flush_ms"`
+	AvgFlushMs     float64 `json:"avg_flush_ms"`
+	FlushFailures  int64   `json:"flush_failures"`
+	FallbackJobs   int64   `json:"fallback_jobs"`
+	totalFlushTime time.Duration
+}
+
+// batchRecorder accumulates BatchStats safely across workers
+type batchRecorder struct {
+	mu    sync.Mutex
+	stats BatchStats
+}
+
+// record adds a flush of size jobs that took duration; failed flushes count their jobs as fallbacks
+func (r *batchRecorder) record(size int, duration time.Duration, failed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := &r.stats
+	s.Batches++
+	s.JobsFlushed += int64(size)
+	s.LastBatchSize = size
+	s.MaxBatchSize = max(s.MaxBatchSize, size)
+	s.AvgBatchSize = float64(s.JobsFlushed) / float64(s.Batches)
+
+	ms := float64(duration.Microseconds()) / 1000
+	s.totalFlushTime += duration
+	s.LastFlushMs = ms
+	s.MaxFlushMs = max(s.MaxFlushMs, ms)
+	s.AvgFlushMs = float64(s.totalFlushTime.Microseconds()) / 1000 / float64(s.Batches)
+
+	if failed {
+		s.FlushFailures++
+		s.FallbackJobs += int64(size)
+	}
+}
+
+// snapshot returns a copy of the counters
+func (r *batchRecorder) snapshot() BatchStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
+
+// pendingJob is a received job waiting in a worker's batch
+type pendingJob[T Job] struct {
+	job      T
+	delivery Delivery
+}
+
+// batchWorker accumulates jobs and flushes them when the batch is full or the
+// batch window since its first job has passed; the open batch is flushed on shutdown
+func (p *Pool[T]) batchWorker(workerID int) {
+	defer p.wg.Done()
+	log.Printf("%s worker %d started (batches of up to %d, window %s)\n",
+		p.opts.Name, workerID, p.opts.BatchSize, p.opts.BatchWindow)
+
+	ctx := context.WithValue(p.jobCtx, workerIDKey{}, workerID)
+	var batch []pendingJob[T]
+	var windowEnd time.Time
+
+	for {
+		receiveCtx, cancel := p.receiveCtx, context.CancelFunc(func() {})
+		if len(batch) > 0 {
+			receiveCtx, cancel = context.WithDeadline(p.receiveCtx, windowEnd)
+		}
+		delivery, err := p.queue.Receive(receiveCtx)
+		cancel()
+
+		if err != nil {
+			if p.receiveCtx.Err() != nil {
+				// Final flush so accepted views are not left waiting for redelivery
+				if len(batch) > 0 {
+					log.Printf("%s worker %d flushing %d jobs before shutdown\n", p.opts.Name, workerID, len(batch))
+					p.flush(ctx, batch)
+				}
+				log.Printf("%s worker %d shutting down\n", p.opts.Name, workerID)
+				return
+			}
+			// Batch window closed
+			p.flush(ctx, batch)
+			batch = nil
+			continue
+		}
+
+		p.inFlight.Add(1)
+
+		var job T
+		if err := json.Unmarshal(delivery.Payload, &job); err != nil {
+			log.Printf("%s worker %d: Malformed job %s: %v\n", p.opts.Name, workerID, delivery.ID, err)
+			deadLetter(p.opts.Name, delivery.ID, delivery.Payload, err, 1)
+			p.complete(workerID, delivery)
+			continue
+		}
+
+		if len(batch) == 0 {
+			windowEnd = time.Now().Add(p.opts.BatchWindow)
+		}
+		batch = append(batch, pendingJob[T]{job: job, delivery: delivery})
+		if len(batch) >= p.opts.BatchSize {
+			p.flush(ctx, batch)
+			batch = nil
+		}
+	}
+}
+
+// flush processes a batch with the batch handler, retrying transient errors
+// If the batch still fails, each job is processed on its own so one bad job
+// cannot sink the others; jobs are acknowledged once processed
+func (p *Pool[T]) flush(ctx context.Context, batch []pendingJob[T]) {
+	if len(batch) == 0 {
+		return
+	}
+
+	jobsInBatch := make([]T, len(batch))
+	for i, pending := range batch {
+		jobsInBatch[i] = pending.job
+		p.tracker.Processing(pending.job.JobID())
+	}
+
+	started := time.Now()
+	var results []interface{}
+	var err error
+	for attempt := 1; ; attempt++ {
+		results, err = p.batchHandler(ctx, jobsInBatch)
+		if err == nil && len(results) != len(batch) {
+			err = fmt.Errorf("batch handler returned %d results for %d jobs", len(results), len(batch))
+		}
+		if err == nil || !IsRetryable(err) || attempt >= p.policy.MaxAttempts || ctx.Err() != nil {
+			break
+		}
+		time.Sleep(p.policy.Backoff(attempt))
+	}
+	p.batchStats.record(len(batch), time.Since(started), err != nil)
+
+	if err != nil {
+		log.Printf("%s batch of %d jobs failed, processing them one by one: %v\n", p.opts.Name, len(batch), err)
+	}
+
+	for i, pending := range batch {
+		id := pending.job.JobID()
+		if err == nil {
+			p.tracker.Succeeded(id, results[i])
+			if p.opts.Hooks.OnSuccess != nil {
+				p.opts.Hooks.OnSuccess(p.opts.Name, id, time.Since(started))
+			}
+		} else if !p.run(ctx, pending.job, pending.delivery.Payload) {
+			// Shutdown interrupted the retries; leave the job for redelivery
+			continue
+		}
+
+		p.complete(WorkerID(ctx), pending.delivery)
+	}
+}
+
+// BatchStats returns the pool's flush counters (zero for pools without batching)
+func (p *Pool[T]) BatchStats() BatchStats {
+	return p.batchStats.snapshot()
+}
@@ -0,0 +1,156 @@
+package workers
+
+import (
+	"context"
+	"disney/jobs"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// batchRecorderPool starts a batching pool over testJob that records the size of every batch
+// failBatches makes the batch handler fail so jobs fall back to the single-job handler
+func batchRecorderPool(t *testing.T, opts PoolOptions, failBatches bool) (*Pool[testJob], *JobTracker, func() []int) {
+	t.Helper()
+	var mu sync.Mutex
+	var sizes []int
+	batchHandler := func(ctx context.Context, batch []testJob) ([]interface{}, error) {
+		mu.Lock()
+		sizes = append(sizes, len(batch))
+		mu.Unlock()
+		if failBatches {
+			return nil, errors.New("batch insert failed")
+		}
+		return make([]interface{}, len(batch)), nil
+	}
+	handler := func(context.Context, testJob) (interface{}, error) { return "single", nil }
+
+	opts.Name = "test"
+	tracker := NewJobTracker(nil, time.Minute)
+	pool := NewBatchPool(NewMemoryQueue(100), tracker, handler, batchHandler, opts)
+	pool.Start()
+	return pool, tracker, func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]int(nil), sizes...)
+	}
+}
+
+// enqueueAll queues jobs with the given IDs
+func enqueueAll(t *testing.T, pool *Pool[testJob], ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		if _, err := pool.Enqueue(context.Background(), testJob{ID: id}); err != nil {
+			t.Fatalf("Enqueue(%s): %v", id, err)
+		}
+	}
+}
+
+func TestBatchPoolFlushesFullBatches(t *testing.T) {
+	pool, tracker, sizes := batchRecorderPool(t, PoolOptions{BatchSize: 3, BatchWindow: time.Hour}, false)
+	defer shutdown(t, pool)
+
+	enqueueAll(t, pool, "1", "2", "3")
+	waitForStatus(t, tracker, "3", jobs.StatusSucceeded)
+	if got := sizes(); len(got) != 1 || got[0] != 3 {
+		t.Errorf("batches %v, want one of 3", got)
+	}
+}
+
+func TestBatchPoolFlushesWhenWindowCloses(t *testing.T) {
+	pool, tracker, sizes := batchRecorderPool(t, PoolOptions{BatchSize: 10, BatchWindow: 30 * time.Millisecond}, false)
+	defer shutdown(t, pool)
+
+	enqueueAll(t, pool, "1", "2")
+	waitForStatus(t, tracker, "2", jobs.StatusSucceeded)
+	if got := sizes(); len(got) != 1 || got[0] != 2 {
+		t.Errorf("batches %v, want one of 2", got)
+	}
+	stats := pool.BatchStats()
+	if stats.Batches != 1 || stats.JobsFlushed != 2 || stats.LastBatchSize != 2 || stats.FlushFailures != 0 {
+		t.Errorf("stats %+v", stats)
+	}
+}
+
+func TestBatchPoolFallsBackToSingleJobs(t *testing.T) {
+	retry := RetryPolicy{MaxAttempts: 1}
+	pool, tracker, _ := batchRecorderPool(t, PoolOptions{BatchSize: 2, BatchWindow: time.Hour, Retry: &retry}, true)
+	defer shutdown(t, pool)
+
+	enqueueAll(t, pool, "1", "2")
+	for _, id := range []string{"1", "2"} {
+		if status := waitForStatus(t, tracker, id, jobs.StatusSucceeded); status.Result != "single" {
+			t.Errorf("job %s result %v, want the single-job handler's", id, status.Result)
+		}
+	}
+	if stats := pool.BatchStats(); stats.FlushFailures != 1 || stats.FallbackJobs != 2 {
+		t.Errorf("stats %+v, want 1 failed flush and 2 fallback jobs", stats)
+	}
+}
+
+func TestBatchPoolFlushesOnShutdown(t *testing.T) {
+	pool, _, sizes := batchRecorderPool(t, PoolOptions{BatchSize: 10, BatchWindow: time.Hour}, false)
+
+	enqueueAll(t, pool, "1", "2")
+	// Wait until the worker holds both jobs in its open batch
+	deadline := time.Now().Add(time.Second)
+	for pool.GetQueueLength() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	stats := shutdown(t, pool)
+	if got := sizes(); len(got) != 1 || got[0] != 2 {
+		t.Errorf("batches %v, want the open batch of 2 flushed", got)
+	}
+	if stats.Flushed != 2 || stats.Abandoned != 0 {
+		t.Errorf("shutdown stats %+v", stats)
+	}
+}
+
+func TestBatchRecorder(t *testing.T) {
+	var r batchRecorder
+	r.record(4, 10*time.Millisecond, false)
+	r.record(2, 30*time.Millisecond, true)
+
+	stats := r.snapshot()
+	want := BatchStats{
+		Batches: 2, JobsFlushed: 6, LastBatchSize: 2, MaxBatchSize: 4, AvgBatchSize: 3,
+		LastFlushMs: 30, MaxFlushMs: 30, AvgFlushMs: 20, FlushFailures: 1, FallbackJobs: 2,
+		totalFlushTime: 40 * time.Millisecond,
+	}
+	if stats != want {
+		t.Errorf("stats %+v, want %+v", stats, want)
+	}
+}
+
+func TestProcessViewBatch(t *testing.T) {
+	mock := mockDB(t)
+	mock.ExpectQuery(`INSERT INTO "views" .* VALUES \(.*\),\(.*\),\(.*\) ON CONFLICT \("job_id"\) DO NOTHING RETURNING "id"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+	mock.ExpectQuery(`SELECT cartoon_id, COUNT\(\*\) AS count FROM "views" WHERE cartoon_id IN \(\$1,\$2\) GROUP BY "cartoon_id"`).
+		WithArgs(3, 8).
+		WillReturnRows(sqlmock.NewRows([]string{"cartoon_id", "count"}).AddRow(3, 12).AddRow(8, 1))
+
+	now := time.Now()
+	batch := []jobs.ViewJob{
+		{ID: "a", UserID: 1, CartoonID: 3, Timestamp: now},
+		{ID: "b", UserID: 2, CartoonID: 8, Timestamp: now},
+		{ID: "c", UserID: 2, CartoonID: 3, Timestamp: now},
+	}
+	results, err := processViewBatch(context.Background(), batch)
+	if err != nil {
+		t.Fatalf("processViewBatch: %v", err)
+	}
+	wantCounts := []int64{12, 1, 12}
+	for i, want := range wantCounts {
+		if response := results[i].(jobs.ViewJobResponse); !response.Success || response.ViewCount != want {
+			t.Errorf("result %d = %+v, want view count %d", i, response, want)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
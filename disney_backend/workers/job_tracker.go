@@ -0,0 +1,176 @@
+package workers
+
+import (
+	"context"
+	"disney/cache"
+	"disney/jobs"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull is returned by Enqueue methods when a job was dropped because the queue is full
+var ErrQueueFull = errors.New("job queue is full")
+
+// JobTracker keeps the status of async jobs for a limited time
+// Statuses are stored in Redis so they survive restarts and are shared between
+// instances; when Redis is unavailable they are kept in memory instead, and
+// Redis calls fail fast until it is back (see cache.Redis)
+type JobTracker struct {
+	redis *cache.Redis
+	ttl   time.Duration
+
+	mu        sync.Mutex
+	memory    map[string]trackedJob
+	lastSweep time.Time
+}
+
+// trackedJob is an in-memory job status with its expiry time
+type trackedJob struct {
+	status    jobs.JobStatus
+	expiresAt time.Time
+}
+
+// NewJobTracker creates a job tracker; remote may be nil to keep statuses in memory only
+func NewJobTracker(remote *cache.Redis, ttl time.Duration) *JobTracker {
+	return &JobTracker{
+		redis:  remote,
+		ttl:    ttl,
+		memory: make(map[string]trackedJob),
+	}
+}
+
+// jobKey returns the Redis key of a job status
+func jobKey(id string) string {
+	return "job:" + id
+}
+
+// Queued records a new job
+func (jt *JobTracker) Queued(id, jobType string, userID uint) {
+	now := time.Now()
+	jt.save(jobs.JobStatus{
+		ID:        id,
+		Type:      jobType,
+		UserID:    userID,
+		Status:    jobs.StatusQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+// Processing marks a job as picked up by a worker
+func (jt *JobTracker) Processing(id string) {
+	jt.update(id, func(status *jobs.JobStatus) {
+		status.Status = jobs.StatusProcessing
+	})
+}
+
+// Retrying records a failed attempt of a job that will be tried again
+func (jt *JobTracker) Retrying(id string, attempt int, err error) {
+	jt.update(id, func(status *jobs.JobStatus) {
+		status.Status = jobs.StatusProcessing
+		status.Attempts = attempt
+		status.Error = err.Error()
+	})
+}
+
+// Succeeded marks a job as done and stores its result
+func (jt *JobTracker) Succeeded(id string, result interface{}) {
+	jt.update(id, func(status *jobs.JobStatus) {
+		status.Status = jobs.StatusSucceeded
+		status.Error = ""
+		status.Result = result
+	})
+}
+
+// Failed marks a job as failed with the given error and optional result
+func (jt *JobTracker) Failed(id string, err error, result interface{}) {
+	jt.update(id, func(status *jobs.JobStatus) {
+		status.Status = jobs.StatusFailed
+		status.Error = err.Error()
+		status.Result = result
+	})
+}
+
+// Dropped marks a job that never entered the queue
+func (jt *JobTracker) Dropped(id string) {
+	jt.update(id, func(status *jobs.JobStatus) {
+		status.Status = jobs.StatusDropped
+		status.Error = ErrQueueFull.Error()
+	})
+}
+
+// Get returns a job status; the boolean is false if the job is unknown or expired
+func (jt *JobTracker) Get(id string) (jobs.JobStatus, bool) {
+	if jt.redis != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		var status jobs.JobStatus
+		err := cache.GetJSON(ctx, jt.redis, jobKey(id), &status)
+		cancel()
+		if err == nil {
+			return status, true
+		}
+		// Redis being down was already reported by the Redis cache
+		if !errors.Is(err, cache.ErrMiss) && !errors.Is(err, cache.ErrUnavailable) {
+			log.Printf("WARNING: Failed to read job %s from Redis: %v", id, err)
+		}
+	}
+
+	// Statuses written while Redis was unreachable live in memory
+	jt.mu.Lock()
+	defer jt.mu.Unlock()
+	tracked, ok := jt.memory[id]
+	if !ok || time.Now().After(tracked.expiresAt) {
+		return jobs.JobStatus{}, false
+	}
+	return tracked.status, true
+}
+
+// update applies a change to a tracked job; unknown jobs are ignored
+func (jt *JobTracker) update(id string, apply func(status *jobs.JobStatus)) {
+	status, ok := jt.Get(id)
+	if !ok {
+		return
+	}
+	apply(&status)
+	status.UpdatedAt = time.Now()
+	jt.save(status)
+}
+
+// save stores a job status in Redis, falling back to memory
+func (jt *JobTracker) save(status jobs.JobStatus) {
+	if jt.redis != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		err := cache.SetJSON(ctx, jt.redis, jobKey(status.ID), status, jt.ttl)
+		cancel()
+		if err == nil {
+			jt.mu.Lock()
+			delete(jt.memory, status.ID)
+			jt.mu.Unlock()
+			return
+		}
+		if !errors.Is(err, cache.ErrUnavailable) {
+			log.Printf("WARNING: Failed to store job %s in Redis, keeping it in memory: %v", status.ID, err)
+		}
+	}
+
+	jt.mu.Lock()
+	defer jt.mu.Unlock()
+	jt.memory[status.ID] = trackedJob{status: status, expiresAt: time.Now().Add(jt.ttl)}
+	jt.sweepLocked()
+}
+
+// sweepLocked removes expired in-memory statuses at most once a minute; the caller must hold jt.mu
+func (jt *JobTracker) sweepLocked() {
+	now := time.Now()
+	if now.Sub(jt.lastSweep) < time.Minute {
+		return
+	}
+	jt.lastSweep = now
+	for id, tracked := range jt.memory {
+		if now.After(tracked.expiresAt) {
+			delete(jt.memory, id)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package workers
+
+import (
+	"disney/cache"
+	"disney/jobs"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestJobTrackerLifecycle(t *testing.T) {
+	tracker := NewJobTracker(nil, time.Minute)
+	tracker.Queued("job-1", jobs.TypeFavourite, 5)
+
+	status, ok := tracker.Get("job-1")
+	if !ok || status.Status != jobs.StatusQueued || status.UserID != 5 || status.Type != jobs.TypeFavourite {
+		t.Fatalf("queued job %+v, %v", status, ok)
+	}
+
+	tracker.Processing("job-1")
+	tracker.Retrying("job-1", 2, errors.New("deadlock detected"))
+	if status, _ := tracker.Get("job-1"); status.Status != jobs.StatusProcessing || status.Attempts != 2 || status.Error == "" {
+		t.Errorf("retrying job %+v", status)
+	}
+
+	result := jobs.FavouriteJobResponse{Success: true}
+	tracker.Succeeded("job-1", result)
+	status, _ = tracker.Get("job-1")
+	if status.Status != jobs.StatusSucceeded || status.Error != "" || status.Result != result {
+		t.Errorf("succeeded job %+v", status)
+	}
+	if !status.UpdatedAt.After(status.CreatedAt) && !status.UpdatedAt.Equal(status.CreatedAt) {
+		t.Errorf("updated at %v before created at %v", status.UpdatedAt, status.CreatedAt)
+	}
+}
+
+func TestJobTrackerFailedAndDropped(t *testing.T) {
+	tracker := NewJobTracker(nil, time.Minute)
+	tracker.Queued("failed", jobs.TypeView, 1)
+	tracker.Queued("dropped", jobs.TypeView, 1)
+
+	tracker.Failed("failed", errors.New("cartoon not found"), nil)
+	tracker.Dropped("dropped")
+
+	if status, _ := tracker.Get("failed"); status.Status != jobs.StatusFailed || status.Error != "cartoon not found" {
+		t.Errorf("failed job %+v", status)
+	}
+	if status, _ := tracker.Get("dropped"); status.Status != jobs.StatusDropped || status.Error != ErrQueueFull.Error() {
+		t.Errorf("dropped job %+v", status)
+	}
+}
+
+func TestJobTrackerIgnoresUnknownJobs(t *testing.T) {
+	tracker := NewJobTracker(nil, time.Minute)
+	tracker.Succeeded("missing", nil)
+	if _, ok := tracker.Get("missing"); ok {
+		t.Error("updating an unknown job created it")
+	}
+}
+
+func TestJobTrackerExpiresStatuses(t *testing.T) {
+	tracker := NewJobTracker(nil, 10*time.Millisecond)
+	tracker.Queued("job-1", jobs.TypeView, 1)
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := tracker.Get("job-1"); ok {
+		t.Error("status still returned after its TTL")
+	}
+}
+
+// redisTracker returns a job tracker on the given Redis server, as one instance
+func redisTracker(t *testing.T, addr string) (*JobTracker, *cache.Redis) {
+	t.Helper()
+	options := &redis.Options{Addr: addr, MaxRetries: -1, DialTimeout: 50 * time.Millisecond}
+	client := redis.NewClient(options)
+	remote := cache.NewRedis(client, options, 10*time.Millisecond)
+	t.Cleanup(func() {
+		remote.Close()
+		client.Close()
+	})
+	return NewJobTracker(remote, time.Hour), remote
+}
+
+func TestJobTrackerSharesStatusesThroughRedis(t *testing.T) {
+	server := miniredis.RunT(t)
+	api, _ := redisTracker(t, server.Addr())
+	worker, _ := redisTracker(t, server.Addr())
+	api.Queued("job-1", jobs.TypeWatchlist, 3)
+	worker.Succeeded("job-1", jobs.WatchlistJobResponse{Success: true})
+
+	status, ok := api.Get("job-1")
+	if !ok || status.Status != jobs.StatusSucceeded {
+		t.Fatalf("status seen by another tracker %+v, %v", status, ok)
+	}
+	if ttl := server.TTL(jobKey("job-1")); ttl != time.Hour {
+		t.Errorf("TTL %s, want 1h", ttl)
+	}
+}
+
+func TestJobTrackerFallsBackToMemory(t *testing.T) {
+	server := miniredis.RunT(t)
+	tracker, remote := redisTracker(t, server.Addr())
+	server.Close()
+
+	tracker.Queued("job-1", jobs.TypeView, 1)
+	// Once Redis is known to be down, calls fail fast instead of waiting out a timeout
+	started := time.Now()
+	for i := 0; i < 100; i++ {
+		tracker.Processing("job-1")
+	}
+	if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+		t.Errorf("100 updates with Redis down took %s", elapsed)
+	}
+	if status, ok := tracker.Get("job-1"); !ok || status.Status != jobs.StatusProcessing {
+		t.Errorf("status with Redis down %+v, %v", status, ok)
+	}
+
+	// Statuses go back to Redis once it answers again
+	server.Restart()
+	deadline := time.Now().Add(2 * time.Second)
+	for !remote.Available() {
+		if time.Now().After(deadline) {
+			t.Fatal("Redis cache did not reconnect")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	tracker.Succeeded("job-1", nil)
+	if !server.Exists(jobKey("job-1")) {
+		t.Error("status not stored in Redis after it came back")
+	}
+	if status, ok := tracker.Get("job-1"); !ok || status.Status != jobs.StatusSucceeded {
+		t.Errorf("status after reconnecting %+v, %v", status, ok)
+	}
+}
@@ -0,0 +1,479 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrJobDropped is returned by Enqueue when the queue stayed full for the pool's drop timeout
+var ErrJobDropped = errors.New("job dropped, queue is full")
+
+// ErrPoolSaturated is returned by Enqueue when the queue depth is at the pool's saturation threshold
+var ErrPoolSaturated = errors.New("worker pool is saturated")
+
+// Job is implemented by the job types processed by a Pool
+type Job interface {
+	// JobID returns the job's unique ID (see jobs.NewJobID)
+	JobID() string
+	// OwnerID returns the user the job status belongs to
+	OwnerID() uint
+}
+
+// Handler processes a job; the result is stored as the job's result
+// Returned errors are retried when IsRetryable reports them as transient
+type Handler[T Job] func(ctx context.Context, job T) (interface{}, error)
+
+// QueueFullPolicy decides what Enqueue does when the queue is at capacity
+type QueueFullPolicy int
+
+const (
+	// QueueFullBlock waits for room until the caller's context ends
+	QueueFullBlock QueueFullPolicy = iota
+	// QueueFullDrop waits up to DropTimeout, then drops the job with ErrJobDropped
+	QueueFullDrop
+	// QueueFullReject fails immediately with ErrQueueFull
+	QueueFullReject
+)
+
+// Hooks are optional callbacks for metrics; they must not block
+type Hooks struct {
+	OnEnqueue func(jobType, jobID string)
+	OnDrop    func(jobType, jobID string, err error)
+	OnSuccess func(jobType, jobID string, duration time.Duration)
+	OnRetry   func(jobType, jobID string, attempt int, err error)
+	OnFailure func(jobType, jobID string, attempts int, err error)
+}
+
+// PoolOptions configures a Pool
+type PoolOptions struct {
+	// Name is the job type; it labels job statuses, logs and dead-letter entries
+	Name string
+	// Concurrency is the number of workers
+	Concurrency int
+	// QueueFull is the policy applied when the queue is at capacity
+	QueueFull QueueFullPolicy
+	// DropTimeout is how long QueueFullDrop waits for room (default 100ms)
+	DropTimeout time.Duration
+	// SaturationThreshold is the queue depth at which Enqueue refuses new jobs
+	// with ErrPoolSaturated instead of waiting for room (0 disables it)
+	SaturationThreshold int
+	// RetryAfter is how long clients whose job was refused should wait (default 5s)
+	RetryAfter time.Duration
+	// Retry overrides the job type's entry in RetryPolicies
+	Retry *RetryPolicy
+	// Hooks receive job lifecycle events
+	Hooks Hooks
+	// BatchSize is the largest batch a batching pool flushes at once (default 100)
+	BatchSize int
+	// BatchWindow is how long a batching pool waits to fill a batch (default 500ms)
+	BatchWindow time.Duration
+}
+
+// workerIDKey is the context key holding the ID of the worker running a job
+type workerIDKey struct{}
+
+// WorkerID returns the ID of the worker processing the job, for log messages
+func WorkerID(ctx context.Context) int {
+	id, _ := ctx.Value(workerIDKey{}).(int)
+	return id
+}
+
+// Pool runs a typed handler over jobs from a Queue with a fixed number of workers
+// Jobs are tracked by ID, retried per the retry policy, dead-lettered when they
+// fail for good and acknowledged only after processing
+type Pool[T Job] struct {
+	opts    PoolOptions
+	policy  RetryPolicy
+	queue   Queue
+	tracker *JobTracker
+	handler Handler[T]
+	// batchHandler is set for batching pools (see NewBatchPool)
+	batchHandler BatchHandler[T]
+	batchStats   batchRecorder
+
+	// receiveCtx stops workers from taking new jobs
+	receiveCtx    context.Context
+	stopReceiving context.CancelFunc
+	// jobCtx is passed to handlers; it is only cancelled when Shutdown gives up waiting
+	jobCtx     context.Context
+	cancelJobs context.CancelFunc
+	// wg tracks running workers so Shutdown can wait for in-flight jobs
+	wg sync.WaitGroup
+	// inFlight counts received jobs not yet acknowledged; processed counts acknowledged ones
+	inFlight  atomic.Int64
+	processed atomic.Int64
+}
+
+// ShutdownStats describes what happened to a pool's jobs during Shutdown
+type ShutdownStats struct {
+	// Flushed is the number of jobs finished while shutting down
+	Flushed int64
+	// Abandoned is the number of received jobs left unacknowledged
+	Abandoned int64
+	// Queued is the number of jobs still in the queue; durable queues keep
+	// them (abandoned ones included) for the next start, in-memory queues lose them
+	Queued int
+}
+
+// NewPool creates a pool; call Start to launch its workers
+// A nil tracker keeps job statuses in memory
+func NewPool[T Job](queue Queue, tracker *JobTracker, handler Handler[T], opts PoolOptions) *Pool[T] {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.DropTimeout <= 0 {
+		opts.DropTimeout = 100 * time.Millisecond
+	}
+	if opts.RetryAfter <= 0 {
+		opts.RetryAfter = 5 * time.Second
+	}
+	if tracker == nil {
+		tracker = NewJobTracker(nil, time.Hour)
+	}
+	policy := retryPolicyFor(opts.Name)
+	if opts.Retry != nil {
+		policy = *opts.Retry
+	}
+
+	receiveCtx, stopReceiving := context.WithCancel(context.Background())
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	return &Pool[T]{
+		opts:          opts,
+		policy:        policy,
+		queue:         queue,
+		tracker:       tracker,
+		handler:       handler,
+		receiveCtx:    receiveCtx,
+		stopReceiving: stopReceiving,
+		jobCtx:        jobCtx,
+		cancelJobs:    cancelJobs,
+	}
+}
+
+// NewBatchPool creates a pool whose workers flush jobs in batches through batchHandler
+// handler is used to process jobs one by one when a batch fails
+func NewBatchPool[T Job](queue Queue, tracker *JobTracker, handler Handler[T], batchHandler BatchHandler[T], opts PoolOptions) *Pool[T] {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.BatchWindow <= 0 {
+		opts.BatchWindow = 500 * time.Millisecond
+	}
+	p := NewPool(queue, tracker, handler, opts)
+	p.batchHandler = batchHandler
+	return p
+}
+
+// Name returns the pool's job type
+func (p *Pool[T]) Name() string {
+	return p.opts.Name
+}
+
+// Concurrency returns the number of workers
+func (p *Pool[T]) Concurrency() int {
+	return p.opts.Concurrency
+}
+
+// Start spawns the workers
+func (p *Pool[T]) Start() {
+	log.Printf("Starting %s worker pool with %d workers\n", p.opts.Name, p.opts.Concurrency)
+
+	for i := 0; i < p.opts.Concurrency; i++ {
+		p.wg.Add(1)
+		if p.batchHandler != nil {
+			go p.batchWorker(i)
+		} else {
+			go p.worker(i)
+		}
+	}
+}
+
+// SaturationThreshold returns the queue depth at which the pool refuses new jobs (0 if disabled)
+func (p *Pool[T]) SaturationThreshold() int {
+	return p.opts.SaturationThreshold
+}
+
+// RetryAfter returns how long clients whose job was refused should wait
+func (p *Pool[T]) RetryAfter() time.Duration {
+	return p.opts.RetryAfter
+}
+
+// Saturated reports whether the queue depth has reached the saturation threshold
+func (p *Pool[T]) Saturated() bool {
+	return p.opts.SaturationThreshold > 0 && p.queue.Len() >= p.opts.SaturationThreshold
+}
+
+// Enqueue queues a job and returns its ID
+// A saturated pool refuses the job at once with ErrPoolSaturated so callers can
+// shed load or process it synchronously (see Process). When the queue is full the
+// pool's QueueFullPolicy applies; any failure is returned and the job is recorded as dropped
+func (p *Pool[T]) Enqueue(ctx context.Context, job T) (string, error) {
+	id := job.JobID()
+
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return id, err
+	}
+
+	p.tracker.Queued(id, p.opts.Name, job.OwnerID())
+	err = ErrPoolSaturated
+	if !p.Saturated() {
+		err = p.push(ctx, payload)
+	}
+	if err != nil {
+		log.Printf("%s worker pool could not queue job %s: %v\n", p.opts.Name, id, err)
+		p.tracker.Dropped(id)
+		if p.opts.Hooks.OnDrop != nil {
+			p.opts.Hooks.OnDrop(p.opts.Name, id, err)
+		}
+		return id, err
+	}
+
+	if p.opts.Hooks.OnEnqueue != nil {
+		p.opts.Hooks.OnEnqueue(p.opts.Name, id)
+	}
+	return id, nil
+}
+
+// push adds a payload to the queue, applying the queue-full policy
+func (p *Pool[T]) push(ctx context.Context, payload []byte) error {
+	var deadline <-chan time.Time
+	switch p.opts.QueueFull {
+	case QueueFullReject:
+		return p.enqueue(ctx, payload)
+	case QueueFullDrop:
+		timer := time.NewTimer(p.opts.DropTimeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	wait := 5 * time.Millisecond
+	for {
+		err := p.enqueue(ctx, payload)
+		if !errors.Is(err, ErrQueueFull) {
+			return err
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-deadline:
+			return ErrJobDropped
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if wait < 100*time.Millisecond {
+			wait *= 2
+		}
+	}
+}
+
+// enqueue adds a payload to the queue
+// Queues shared between instances check the saturation threshold again as
+// they add it; Saturated alone can be passed by several instances at once
+func (p *Pool[T]) enqueue(ctx context.Context, payload []byte) error {
+	if queue, ok := p.queue.(saturationQueue); ok && p.opts.SaturationThreshold > 0 {
+		return queue.EnqueueUnlessSaturated(ctx, payload, p.opts.SaturationThreshold)
+	}
+	return p.queue.Enqueue(ctx, payload)
+}
+
+// Process runs a job synchronously in the caller's goroutine, bypassing the queue
+// The job is tracked like a queued one but not retried or dead-lettered; the
+// caller gets the error instead
+func (p *Pool[T]) Process(ctx context.Context, job T) (interface{}, error) {
+	id := job.JobID()
+	p.tracker.Queued(id, p.opts.Name, job.OwnerID())
+	p.tracker.Processing(id)
+
+	started := time.Now()
+	result, err := p.handler(ctx, job)
+	if err != nil {
+		log.Printf("Job %s (%s) failed when processed synchronously: %v\n", id, p.opts.Name, err)
+		p.tracker.Failed(id, err, nil)
+		if p.opts.Hooks.OnFailure != nil {
+			p.opts.Hooks.OnFailure(p.opts.Name, id, 1, err)
+		}
+		return nil, err
+	}
+
+	p.tracker.Succeeded(id, result)
+	if p.opts.Hooks.OnSuccess != nil {
+		p.opts.Hooks.OnSuccess(p.opts.Name, id, time.Since(started))
+	}
+	return result, nil
+}
+
+// Replay re-enqueues a dead-lettered job payload under its original job ID
+func (p *Pool[T]) Replay(payload []byte) error {
+	var job T
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	p.tracker.Queued(job.JobID(), p.opts.Name, job.OwnerID())
+	if err := p.queue.Enqueue(ctx, payload); err != nil {
+		p.tracker.Dropped(job.JobID())
+		return err
+	}
+	return nil
+}
+
+// worker receives jobs until shutdown and acknowledges them once processed
+func (p *Pool[T]) worker(workerID int) {
+	defer p.wg.Done()
+	log.Printf("%s worker %d started\n", p.opts.Name, workerID)
+
+	ctx := context.WithValue(p.jobCtx, workerIDKey{}, workerID)
+	for {
+		delivery, err := p.queue.Receive(p.receiveCtx)
+		if err != nil {
+			log.Printf("%s worker %d shutting down\n", p.opts.Name, workerID)
+			return
+		}
+		p.inFlight.Add(1)
+
+		var job T
+		if err := json.Unmarshal(delivery.Payload, &job); err != nil {
+			// A payload that cannot be decoded will never succeed; dead-letter it
+			log.Printf("%s worker %d: Malformed job %s: %v\n", p.opts.Name, workerID, delivery.ID, err)
+			deadLetter(p.opts.Name, delivery.ID, delivery.Payload, err, 1)
+		} else if !p.run(ctx, job, delivery.Payload) {
+			// Shutdown interrupted the retries; leave the job for redelivery
+			continue
+		}
+
+		// Acknowledge only after processing so a crash mid-job leads to redelivery
+		p.complete(workerID, delivery)
+	}
+}
+
+// complete acknowledges a processed job
+func (p *Pool[T]) complete(workerID int, delivery Delivery) {
+	if err := delivery.Ack(); err != nil {
+		log.Printf("%s worker %d: Failed to acknowledge job %s: %v\n", p.opts.Name, workerID, delivery.ID, err)
+	}
+	p.inFlight.Add(-1)
+	p.processed.Add(1)
+}
+
+// run processes a job with retries and records the outcome with the tracker
+// Jobs that fail permanently or exhaust their attempts are dead-lettered.
+// Returns false if shutdown interrupted the retries; the job must then stay
+// unacknowledged so it is redelivered
+func (p *Pool[T]) run(ctx context.Context, job T, payload []byte) bool {
+	id := job.JobID()
+	started := time.Now()
+	p.tracker.Processing(id)
+
+	var err error
+	attempt := 1
+	for ; ; attempt++ {
+		var result interface{}
+		result, err = p.handler(ctx, job)
+		if err == nil {
+			p.tracker.Succeeded(id, result)
+			if p.opts.Hooks.OnSuccess != nil {
+				p.opts.Hooks.OnSuccess(p.opts.Name, id, time.Since(started))
+			}
+			return true
+		}
+		if ctx.Err() != nil {
+			// The handler was cancelled by a forced shutdown
+			return false
+		}
+		if !IsRetryable(err) || attempt >= p.policy.MaxAttempts {
+			break
+		}
+
+		p.tracker.Retrying(id, attempt, err)
+		if p.opts.Hooks.OnRetry != nil {
+			p.opts.Hooks.OnRetry(p.opts.Name, id, attempt, err)
+		}
+		select {
+		case <-time.After(p.policy.Backoff(attempt)):
+		case <-p.receiveCtx.Done():
+			log.Printf("Job %s (%s) interrupted by shutdown after %d attempts, leaving it queued\n",
+				id, p.opts.Name, attempt)
+			return false
+		}
+	}
+
+	log.Printf("Job %s (%s) failed after %d attempts: %v\n", id, p.opts.Name, attempt, err)
+	p.tracker.Failed(id, err, nil)
+	deadLetter(p.opts.Name, id, payload, err, attempt)
+	if p.opts.Hooks.OnFailure != nil {
+		p.opts.Hooks.OnFailure(p.opts.Name, id, attempt, err)
+	}
+	return true
+}
+
+// Shutdown stops taking new jobs and waits for in-flight jobs until ctx ends
+// In-memory queues are drained first since their jobs would be lost; durable
+// queues keep waiting jobs for the next start. Jobs still running when ctx ends
+// are cancelled and, being unacknowledged, are redelivered by durable queues
+// Enqueue must no longer be called (stop the HTTP server first)
+func (p *Pool[T]) Shutdown(ctx context.Context) (ShutdownStats, error) {
+	log.Printf("Shutting down %s worker pool...\n", p.opts.Name)
+	processedBefore := p.processed.Load()
+
+	if _, inMemory := p.queue.(*MemoryQueue); inMemory {
+		p.drain(ctx)
+	}
+	p.stopReceiving()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		p.cancelJobs()
+		<-done
+		err = ctx.Err()
+	}
+	p.cancelJobs()
+
+	stats := ShutdownStats{
+		Flushed:   p.processed.Load() - processedBefore,
+		Abandoned: p.inFlight.Load(),
+		Queued:    p.queue.Len(),
+	}
+	log.Printf("%s worker pool stopped: %d jobs flushed, %d abandoned, %d left in queue\n",
+		p.opts.Name, stats.Flushed, stats.Abandoned, stats.Queued)
+
+	if closeErr := p.queue.Close(); closeErr != nil {
+		log.Printf("Error closing %s queue: %v\n", p.opts.Name, closeErr)
+	}
+	return stats, err
+}
+
+// drain waits until the workers have taken every queued job or ctx ends
+func (p *Pool[T]) drain(ctx context.Context) {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for p.queue.Len() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// GetQueueLength returns the current number of jobs waiting in the queue
+func (p *Pool[T]) GetQueueLength() int {
+	return p.queue.Len()
+}
@@ -0,0 +1,421 @@
+package workers
+
+import (
+	"context"
+	"disney/jobs"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// testJob is a minimal job for exercising the pool
+type testJob struct {
+	ID     string
+	UserID uint
+}
+
+func (j testJob) JobID() string { return j.ID }
+func (j testJob) OwnerID() uint { return j.UserID }
+
+// fastRetry retries quickly so tests don't wait on backoff
+var fastRetry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+// waitForStatus waits until the tracker reports the job in the given status
+func waitForStatus(t *testing.T, tracker *JobTracker, id, want string) jobs.JobStatus {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		status, _ := tracker.Get(id)
+		if status.Status == want {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is %q, want %q", id, status.Status, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// shutdown stops the pool, failing the test if it does not stop in time
+func shutdown[T Job](t *testing.T, p *Pool[T]) ShutdownStats {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	stats, err := p.Shutdown(ctx)
+	if err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	return stats
+}
+
+func TestPoolRetriesTransientErrors(t *testing.T) {
+	tracker := NewJobTracker(nil, time.Minute)
+	var calls atomic.Int32
+	handler := func(ctx context.Context, job testJob) (interface{}, error) {
+		if calls.Add(1) < 3 {
+			return nil, &pgconn.PgError{Code: "40P01"}
+		}
+		return "done", nil
+	}
+	pool := NewPool(NewMemoryQueue(10), tracker, handler, PoolOptions{Name: "test", Retry: &fastRetry})
+	pool.Start()
+	defer shutdown(t, pool)
+
+	if _, err := pool.Enqueue(context.Background(), testJob{ID: "job-1", UserID: 1}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	status := waitForStatus(t, tracker, "job-1", jobs.StatusSucceeded)
+	if calls.Load() != 3 || status.Attempts != 2 || status.Result != "done" {
+		t.Errorf("handler called %d times, status %+v", calls.Load(), status)
+	}
+}
+
+func TestPoolDeadLettersFailedJobs(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		attempts int
+	}{
+		{"permanent error", &pgconn.PgError{Code: "23503"}, 1},
+		{"retries exhausted", &pgconn.PgError{Code: "40001"}, fastRetry.MaxAttempts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockDB(t)
+			mock.ExpectQuery(`INSERT INTO "dead_letter_jobs"`).
+				WithArgs("test", "job-1", `{"ID":"job-1","UserID":1}`, tt.err.Error(), tt.attempts, sqlmock.AnyArg(), nil).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+			tracker := NewJobTracker(nil, time.Minute)
+			var calls atomic.Int32
+			handler := func(ctx context.Context, job testJob) (interface{}, error) {
+				calls.Add(1)
+				return nil, tt.err
+			}
+			pool := NewPool(NewMemoryQueue(10), tracker, handler, PoolOptions{Name: "test", Retry: &fastRetry})
+			pool.Start()
+
+			if _, err := pool.Enqueue(context.Background(), testJob{ID: "job-1", UserID: 1}); err != nil {
+				t.Fatalf("Enqueue: %v", err)
+			}
+			waitForStatus(t, tracker, "job-1", jobs.StatusFailed)
+			shutdown(t, pool)
+
+			if int(calls.Load()) != tt.attempts {
+				t.Errorf("handler called %d times, want %d", calls.Load(), tt.attempts)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestPoolReplay(t *testing.T) {
+	queue := NewMemoryQueue(10)
+	tracker := NewJobTracker(nil, time.Minute)
+	pool := NewPool(queue, tracker, func(context.Context, testJob) (interface{}, error) { return nil, nil },
+		PoolOptions{Name: "test"})
+
+	if err := pool.Replay([]byte(`{"ID":"job-1","UserID":4}`)); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if status, ok := tracker.Get("job-1"); !ok || status.Status != jobs.StatusQueued || status.UserID != 4 {
+		t.Errorf("replayed job status %+v, %v", status, ok)
+	}
+	if queue.Len() != 1 {
+		t.Errorf("queue length %d, want 1", queue.Len())
+	}
+	if err := pool.Replay([]byte(`not json`)); err == nil {
+		t.Error("Replay accepted a malformed payload")
+	}
+}
+
+func TestPoolQueueFullPolicies(t *testing.T) {
+	noop := func(context.Context, testJob) (interface{}, error) { return nil, nil }
+	tests := []struct {
+		name    string
+		policy  QueueFullPolicy
+		want    error
+		minWait time.Duration
+	}{
+		{"reject", QueueFullReject, ErrQueueFull, 0},
+		{"drop", QueueFullDrop, ErrJobDropped, 30 * time.Millisecond},
+		{"block", QueueFullBlock, context.DeadlineExceeded, 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewJobTracker(nil, time.Minute)
+			var dropped atomic.Value
+			// Not started, so the single queue slot stays taken
+			pool := NewPool(NewMemoryQueue(1), tracker, noop, PoolOptions{
+				Name:        "test",
+				QueueFull:   tt.policy,
+				DropTimeout: 30 * time.Millisecond,
+				Hooks: Hooks{OnDrop: func(jobType, jobID string, err error) {
+					dropped.Store(jobID)
+				}},
+			})
+			if _, err := pool.Enqueue(context.Background(), testJob{ID: "first"}); err != nil {
+				t.Fatalf("Enqueue: %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			id, err := pool.Enqueue(ctx, testJob{ID: "second", UserID: 2})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Enqueue on a full queue: %v, want %v", err, tt.want)
+			}
+			if waited := time.Since(start); waited < tt.minWait {
+				t.Errorf("gave up after %s, want at least %s", waited, tt.minWait)
+			}
+			if status, _ := tracker.Get(id); status.Status != jobs.StatusDropped {
+				t.Errorf("job status %+v, want dropped", status)
+			}
+			if dropped.Load() != "second" {
+				t.Errorf("OnDrop saw %v, want second", dropped.Load())
+			}
+		})
+	}
+}
+
+func TestPoolBlockWaitsForRoom(t *testing.T) {
+	release := make(chan struct{})
+	pool := NewPool(NewMemoryQueue(1), nil, func(context.Context, testJob) (interface{}, error) {
+		<-release
+		return nil, nil
+	}, PoolOptions{Name: "test", QueueFull: QueueFullBlock})
+	pool.Start()
+	defer shutdown(t, pool)
+	defer close(release)
+
+	ctx := context.Background()
+	// The worker holds the first job, the second fills the queue
+	pool.Enqueue(ctx, testJob{ID: "1"})
+	deadline := time.Now().Add(time.Second)
+	for pool.GetQueueLength() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	pool.Enqueue(ctx, testJob{ID: "2"})
+
+	enqueued := make(chan error, 1)
+	go func() {
+		_, err := pool.Enqueue(ctx, testJob{ID: "3"})
+		enqueued <- err
+	}()
+	select {
+	case err := <-enqueued:
+		t.Fatalf("Enqueue returned %v while the queue was full", err)
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	release <- struct{}{}
+	select {
+	case err := <-enqueued:
+		if err != nil {
+			t.Errorf("Enqueue: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Enqueue still blocked after room was made")
+	}
+}
+
+func TestPoolHooksAndConcurrency(t *testing.T) {
+	const workers = 3
+	var running, peak, successes, failures atomic.Int32
+	arrived := make(chan struct{}, workers)
+	release := make(chan struct{})
+	handler := func(ctx context.Context, job testJob) (interface{}, error) {
+		now := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := peak.Load()
+			if now <= old || peak.CompareAndSwap(old, now) {
+				break
+			}
+		}
+		if WorkerID(ctx) < 0 || WorkerID(ctx) >= workers {
+			t.Errorf("worker ID %d out of range", WorkerID(ctx))
+		}
+		arrived <- struct{}{}
+		<-release
+		if job.ID == "fail" {
+			return nil, errors.New("permanent")
+		}
+		return nil, nil
+	}
+
+	mock := mockDB(t)
+	mock.ExpectQuery(`INSERT INTO "dead_letter_jobs"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	var enqueued atomic.Int32
+	pool := NewPool(NewMemoryQueue(10), nil, handler, PoolOptions{
+		Name:        "test",
+		Concurrency: workers,
+		Hooks: Hooks{
+			OnEnqueue: func(string, string) { enqueued.Add(1) },
+			OnSuccess: func(string, string, time.Duration) { successes.Add(1) },
+			OnFailure: func(_ string, _ string, attempts int, _ error) {
+				if attempts != 1 {
+					t.Errorf("failure after %d attempts, want 1", attempts)
+				}
+				failures.Add(1)
+			},
+		},
+	})
+	pool.Start()
+
+	for _, id := range []string{"a", "b", "fail"} {
+		if _, err := pool.Enqueue(context.Background(), testJob{ID: id}); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+	// All three jobs run at the same time, one per worker
+	for i := 0; i < workers; i++ {
+		select {
+		case <-arrived:
+		case <-time.After(time.Second):
+			t.Fatalf("only %d jobs started, want %d", i, workers)
+		}
+	}
+	close(release)
+	shutdown(t, pool)
+
+	if peak.Load() != workers {
+		t.Errorf("peak concurrency %d, want %d", peak.Load(), workers)
+	}
+	if enqueued.Load() != 3 || successes.Load() != 2 || failures.Load() != 1 {
+		t.Errorf("hooks: %d enqueued, %d succeeded, %d failed", enqueued.Load(), successes.Load(), failures.Load())
+	}
+}
+
+func TestPoolRefusesJobsWhenSaturated(t *testing.T) {
+	tracker := NewJobTracker(nil, time.Minute)
+	var dropped atomic.Value
+	// Not started, so queued jobs stay in the queue
+	pool := NewPool(NewMemoryQueue(10), tracker, func(context.Context, testJob) (interface{}, error) { return nil, nil },
+		PoolOptions{Name: "test", SaturationThreshold: 2, Hooks: Hooks{OnDrop: func(_, _ string, err error) {
+			dropped.Store(err)
+		}}})
+
+	enqueueAll(t, pool, "1", "2")
+	if !pool.Saturated() || pool.SaturationThreshold() != 2 {
+		t.Fatalf("pool with 2 queued jobs not saturated at threshold %d", pool.SaturationThreshold())
+	}
+
+	id, err := pool.Enqueue(context.Background(), testJob{ID: "3"})
+	if !errors.Is(err, ErrPoolSaturated) {
+		t.Fatalf("Enqueue: %v, want ErrPoolSaturated", err)
+	}
+	if status, _ := tracker.Get(id); status.Status != jobs.StatusDropped {
+		t.Errorf("refused job status %+v, want dropped", status)
+	}
+	if dropped.Load() != ErrPoolSaturated {
+		t.Errorf("OnDrop saw %v", dropped.Load())
+	}
+	if pool.GetQueueLength() != 2 {
+		t.Errorf("queue length %d, want the refused job left out", pool.GetQueueLength())
+	}
+}
+
+func TestPoolWithoutThresholdIsNeverSaturated(t *testing.T) {
+	pool := NewPool(NewMemoryQueue(2), nil, func(context.Context, testJob) (interface{}, error) { return nil, nil },
+		PoolOptions{Name: "test", QueueFull: QueueFullReject})
+	enqueueAll(t, pool, "1", "2")
+	if pool.Saturated() {
+		t.Error("pool without a threshold reported saturated")
+	}
+	if _, err := pool.Enqueue(context.Background(), testJob{ID: "3"}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Enqueue on a full queue: %v, want ErrQueueFull", err)
+	}
+	if pool.RetryAfter() != 5*time.Second {
+		t.Errorf("default RetryAfter %s, want 5s", pool.RetryAfter())
+	}
+}
+
+func TestPoolProcessRunsSynchronously(t *testing.T) {
+	tracker := NewJobTracker(nil, time.Minute)
+	var calls atomic.Int32
+	failure := errors.New("database is down")
+	pool := NewPool(NewMemoryQueue(10), tracker, func(_ context.Context, job testJob) (interface{}, error) {
+		calls.Add(1)
+		if job.ID == "fail" {
+			return nil, failure
+		}
+		return "done", nil
+	}, PoolOptions{Name: "test", Retry: &fastRetry})
+
+	result, err := pool.Process(context.Background(), testJob{ID: "ok", UserID: 1})
+	if err != nil || result != "done" {
+		t.Fatalf("Process = %v, %v", result, err)
+	}
+	if status, _ := tracker.Get("ok"); status.Status != jobs.StatusSucceeded || status.UserID != 1 {
+		t.Errorf("status %+v, want succeeded", status)
+	}
+
+	// Failures go back to the caller: no retries and no dead letter
+	if _, err := pool.Process(context.Background(), testJob{ID: "fail", UserID: 1}); !errors.Is(err, failure) {
+		t.Errorf("Process: %v, want the handler error", err)
+	}
+	if status, _ := tracker.Get("fail"); calls.Load() != 2 || status.Status != jobs.StatusFailed {
+		t.Errorf("handler called %d times, status %+v", calls.Load(), status)
+	}
+}
+
+func TestPoolShutdownDrainsMemoryQueue(t *testing.T) {
+	var calls atomic.Int32
+	pool := NewPool(NewMemoryQueue(10), nil, func(context.Context, testJob) (interface{}, error) {
+		calls.Add(1)
+		time.Sleep(5 * time.Millisecond)
+		return nil, nil
+	}, PoolOptions{Name: "test", Concurrency: 1})
+	enqueueAll(t, pool, "1", "2", "3", "4")
+	pool.Start()
+
+	stats := shutdown(t, pool)
+	if stats != (ShutdownStats{Flushed: 4}) || calls.Load() != 4 {
+		t.Errorf("stats %+v after %d calls, want all 4 jobs flushed", stats, calls.Load())
+	}
+}
+
+func TestPoolShutdownTimeoutLeavesJobsForRedelivery(t *testing.T) {
+	_, client := newTestRedis(t)
+	queue, err := NewRedisQueue(client, "test", "api-1", 10, time.Hour)
+	if err != nil {
+		t.Fatalf("NewRedisQueue: %v", err)
+	}
+	started := make(chan struct{}, 2)
+	pool := NewPool(queue, nil, func(ctx context.Context, _ testJob) (interface{}, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, PoolOptions{Name: "test", Concurrency: 1, Retry: &fastRetry})
+	enqueueAll(t, pool, "1", "2")
+	pool.Start()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	stats, err := pool.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown: %v, want the deadline error", err)
+	}
+	// Durable queues are not drained: the running job is cancelled and both stay in the stream
+	if stats != (ShutdownStats{Abandoned: 1, Queued: 2}) {
+		t.Errorf("stats %+v, want 1 abandoned and 2 queued", stats)
+	}
+
+	restarted, err := NewRedisQueue(client, "test", "api-1", 10, time.Hour)
+	if err != nil {
+		t.Fatalf("NewRedisQueue: %v", err)
+	}
+	for _, want := range []string{`{"ID":"1","UserID":0}`, `{"ID":"2","UserID":0}`} {
+		if delivery := receive(t, restarted); string(delivery.Payload) != want {
+			t.Errorf("redelivered %s, want %s", delivery.Payload, want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package workers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Queue backends
+const (
+	QueueBackendRedis  = "redis"
+	QueueBackendMemory = "memory"
+)
+
+// Queue is a job queue with at-least-once delivery
+// A received job stays owned by the worker until it is acknowledged; durable
+// backends redeliver unacknowledged jobs, so job handlers must be idempotent
+type Queue interface {
+	// Enqueue adds a job payload; it returns ErrQueueFull at once when the queue is at capacity
+	Enqueue(ctx context.Context, payload []byte) error
+	// Receive blocks until a job is available or ctx is done
+	Receive(ctx context.Context) (Delivery, error)
+	// Len returns the number of jobs waiting or in flight
+	Len() int
+	// Close releases the queue's resources
+	Close() error
+}
+
+// saturationQueue is a queue that checks the pool's saturation threshold and
+// adds the job in one step, so instances sharing it cannot overshoot the threshold
+type saturationQueue interface {
+	// EnqueueUnlessSaturated adds a job payload unless the queue holds threshold
+	// jobs (ErrPoolSaturated) or is at capacity (ErrQueueFull)
+	EnqueueUnlessSaturated(ctx context.Context, payload []byte, threshold int) error
+}
+
+// Delivery is a job handed to a worker
+type Delivery struct {
+	ID      string
+	Payload []byte
+	ack     func() error
+}
+
+// Ack marks the job as processed so it is not delivered again
+func (d Delivery) Ack() error {
+	if d.ack == nil {
+		return nil
+	}
+	return d.ack()
+}
+
+// QueueOptions configures the queue created by NewQueue
+type QueueOptions struct {
+	// Backend is QueueBackendRedis or QueueBackendMemory
+	Backend string
+	// Capacity is the maximum number of queued jobs before Enqueue fails
+	Capacity int
+	// VisibilityTimeout is how long a received job may stay unacknowledged
+	// before another worker takes it over (Redis only)
+	VisibilityTimeout time.Duration
+	// Consumer names this instance in the queue's consumer group (Redis only);
+	// it must be stable so a restarted instance finds the entries it had not acknowledged
+	Consumer string
+}
+
+// NewQueue creates a named queue with the requested backend
+// The Redis backend falls back to the in-memory queue when Redis is unavailable
+func NewQueue(client *redis.Client, name string, opts QueueOptions) Queue {
+	if opts.Backend == QueueBackendRedis {
+		if client != nil {
+			queue, err := NewRedisQueue(client, name, opts.Consumer, opts.Capacity, opts.VisibilityTimeout)
+			if err == nil {
+				return queue
+			}
+			log.Printf("WARNING: Failed to create Redis queue %s, using in-memory queue: %v", name, err)
+		} else {
+			log.Printf("WARNING: Redis unavailable, queue %s is in memory and will not survive restarts", name)
+		}
+	}
+	return NewMemoryQueue(opts.Capacity)
+}
+
+// MemoryQueue is a queue backed by a buffered channel
+// Jobs are lost when the process exits; useful for tests and local development
+type MemoryQueue struct {
+	jobs chan Delivery
+}
+
+// NewMemoryQueue creates an in-memory queue holding up to capacity jobs
+func NewMemoryQueue(capacity int) *MemoryQueue {
+	return &MemoryQueue{jobs: make(chan Delivery, capacity)}
+}
+
+// Enqueue adds a job without waiting; the pool's queue-full policy decides whether to retry
+func (q *MemoryQueue) Enqueue(ctx context.Context, payload []byte) error {
+	select {
+	case q.jobs <- Delivery{Payload: payload}:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Receive waits for the next job
+func (q *MemoryQueue) Receive(ctx context.Context) (Delivery, error) {
+	select {
+	case delivery := <-q.jobs:
+		return delivery, nil
+	case <-ctx.Done():
+		return Delivery{}, ctx.Err()
+	}
+}
+
+// Len returns the number of buffered jobs
+func (q *MemoryQueue) Len() int {
+	return len(q.jobs)
+}
+
+// Close is a no-op; the channel is left open so a late Enqueue cannot panic
+func (q *MemoryQueue) Close() error {
+	return nil
+}
@@ -0,0 +1,236 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedis starts an in-memory Redis server for the test
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+// receive reads one job, failing the test if none arrives in time
+func receive(t *testing.T, q Queue) Delivery {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	delivery, err := q.Receive(ctx)
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	return delivery
+}
+
+// assertEmpty checks that no job is delivered
+func assertEmpty(t *testing.T, q Queue) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if delivery, err := q.Receive(ctx); err == nil {
+		t.Fatalf("received %q, want no job", delivery.Payload)
+	}
+}
+
+func TestMemoryQueue(t *testing.T) {
+	q := NewMemoryQueue(2)
+	ctx := context.Background()
+	for _, payload := range []string{"a", "b"} {
+		if err := q.Enqueue(ctx, []byte(payload)); err != nil {
+			t.Fatalf("Enqueue(%s): %v", payload, err)
+		}
+	}
+	if err := q.Enqueue(ctx, []byte("c")); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Enqueue past capacity: %v, want ErrQueueFull", err)
+	}
+	if q.Len() != 2 {
+		t.Errorf("Len = %d, want 2", q.Len())
+	}
+
+	if got := receive(t, q); string(got.Payload) != "a" || got.Ack() != nil {
+		t.Errorf("first job %q", got.Payload)
+	}
+	receive(t, q)
+	assertEmpty(t, q)
+}
+
+func TestNewQueueFallsBackToMemory(t *testing.T) {
+	q := NewQueue(nil, "views", QueueOptions{Backend: QueueBackendRedis, Capacity: 5})
+	if _, ok := q.(*MemoryQueue); !ok {
+		t.Errorf("NewQueue without Redis returned %T, want *MemoryQueue", q)
+	}
+}
+
+func TestRedisQueueAckDeletesEntry(t *testing.T) {
+	server, client := newTestRedis(t)
+	q, err := NewRedisQueue(client, "views", "api-1", 10, time.Minute)
+	if err != nil {
+		t.Fatalf("NewRedisQueue: %v", err)
+	}
+
+	if err := q.Enqueue(context.Background(), []byte(`{"id":"job-1"}`)); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	delivery := receive(t, q)
+	if string(delivery.Payload) != `{"id":"job-1"}` || delivery.ID == "" {
+		t.Fatalf("delivery %+v", delivery)
+	}
+	// Unacknowledged entries still count towards the capacity
+	if q.Len() != 1 {
+		t.Errorf("Len before ack = %d, want 1", q.Len())
+	}
+	if err := delivery.Ack(); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	if q.Len() != 0 {
+		t.Errorf("Len after ack = %d, want 0", q.Len())
+	}
+	if entries, _ := server.Stream("queue:views"); len(entries) != 0 {
+		t.Errorf("stream still holds %d entries", len(entries))
+	}
+}
+
+func TestRedisQueueCapacity(t *testing.T) {
+	_, client := newTestRedis(t)
+	q, err := NewRedisQueue(client, "favourites", "api-1", 1, time.Minute)
+	if err != nil {
+		t.Fatalf("NewRedisQueue: %v", err)
+	}
+	ctx := context.Background()
+	if err := q.Enqueue(ctx, []byte("a")); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if err := q.Enqueue(ctx, []byte("b")); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Enqueue past capacity: %v, want ErrQueueFull", err)
+	}
+}
+
+func TestRedisQueueLimitsHoldAcrossReplicas(t *testing.T) {
+	server, client := newTestRedis(t)
+	replicas := make([]*RedisQueue, 2)
+	for i := range replicas {
+		client := client
+		if i > 0 {
+			client = redis.NewClient(&redis.Options{Addr: server.Addr()})
+			t.Cleanup(func() { client.Close() })
+		}
+		q, err := NewRedisQueue(client, "views", fmt.Sprintf("api-%d", i), 8, time.Minute)
+		if err != nil {
+			t.Fatalf("NewRedisQueue: %v", err)
+		}
+		replicas[i] = q
+	}
+
+	// Replicas enqueuing at the same time stop exactly at the threshold, then at capacity
+	ctx := context.Background()
+	for _, tt := range []struct {
+		threshold int
+		want      int
+		refusal   error
+	}{
+		{5, 5, ErrPoolSaturated},
+		{0, 8, ErrQueueFull},
+	} {
+		before := replicas[0].Len()
+		var wg sync.WaitGroup
+		var refused atomic.Int64
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(q *RedisQueue) {
+				defer wg.Done()
+				err := q.EnqueueUnlessSaturated(ctx, []byte("job"), tt.threshold)
+				if errors.Is(err, tt.refusal) {
+					refused.Add(1)
+				} else if err != nil {
+					t.Errorf("Enqueue: %v", err)
+				}
+			}(replicas[i%2])
+		}
+		wg.Wait()
+		if length := replicas[0].Len(); length != tt.want {
+			t.Errorf("threshold %d: stream holds %d entries, want %d", tt.threshold, length, tt.want)
+		}
+		if added := tt.want - before; refused.Load() != int64(20-added) {
+			t.Errorf("threshold %d: %d refused, want %d with %v", tt.threshold, refused.Load(), 20-added, tt.refusal)
+		}
+	}
+}
+
+func TestRedisQueueRecoversPendingAfterRestart(t *testing.T) {
+	_, client := newTestRedis(t)
+	before, err := NewRedisQueue(client, "views", "api-1", 10, time.Hour)
+	if err != nil {
+		t.Fatalf("NewRedisQueue: %v", err)
+	}
+	ctx := context.Background()
+	for _, payload := range []string{"a", "b"} {
+		if err := before.Enqueue(ctx, []byte(payload)); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+	// The instance stops after receiving "a" without acknowledging it
+	if got := receive(t, before); string(got.Payload) != "a" {
+		t.Fatalf("received %q, want a", got.Payload)
+	}
+
+	// The restarted instance uses the same consumer name and gets "a" again, then "b"
+	after, err := NewRedisQueue(client, "views", "api-1", 10, time.Hour)
+	if err != nil {
+		t.Fatalf("NewRedisQueue after restart: %v", err)
+	}
+	for _, want := range []string{"a", "b"} {
+		got := receive(t, after)
+		if string(got.Payload) != want {
+			t.Fatalf("received %q, want %q", got.Payload, want)
+		}
+		if err := got.Ack(); err != nil {
+			t.Fatalf("Ack: %v", err)
+		}
+	}
+	assertEmpty(t, after)
+}
+
+func TestRedisQueueClaimsStaleEntries(t *testing.T) {
+	_, client := newTestRedis(t)
+	visibility := 200 * time.Millisecond
+	crashed, err := NewRedisQueue(client, "views", "api-1", 10, visibility)
+	if err != nil {
+		t.Fatalf("NewRedisQueue: %v", err)
+	}
+	other, err := NewRedisQueue(client, "views", "api-2", 10, visibility)
+	if err != nil {
+		t.Fatalf("NewRedisQueue: %v", err)
+	}
+
+	if err := crashed.Enqueue(context.Background(), []byte("a")); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	receive(t, crashed)
+
+	// Within the visibility timeout the entry belongs to api-1
+	assertEmpty(t, other)
+
+	time.Sleep(2 * visibility)
+	got := receive(t, other)
+	if string(got.Payload) != "a" {
+		t.Fatalf("api-2 received %q, want the stale entry", got.Payload)
+	}
+	if err := got.Ack(); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	if other.Len() != 0 {
+		t.Errorf("Len = %d, want 0", other.Len())
+	}
+}
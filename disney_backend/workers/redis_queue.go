@@ -0,0 +1,263 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// redisQueueGroup is the consumer group shared by all API instances
+	redisQueueGroup = "workers"
+	// redisQueueBlock is how long a worker waits for new entries per read
+	redisQueueBlock = 2 * time.Second
+	// redisQueueBatch is how many pending entries are recovered or claimed at once
+	redisQueueBatch = 50
+)
+
+// RedisQueue is a durable queue on a Redis stream with a consumer group
+// Entries are acknowledged and deleted once processed. Entries left pending by
+// a crashed or restarted instance are recovered on startup, and entries
+// pending longer than the visibility timeout are claimed by another worker
+type RedisQueue struct {
+	client     *redis.Client
+	stream     string
+	consumer   string
+	capacity   int64
+	visibility time.Duration
+
+	mu          sync.Mutex
+	buffer      []redis.XMessage
+	recovered   bool
+	recoverFrom string
+	lastClaim   time.Time
+}
+
+// NewRedisQueue creates the stream and consumer group if needed
+// The consumer name should be stable per instance (such as the hostname) so a
+// restarted instance finds the entries it had not acknowledged
+func NewRedisQueue(client *redis.Client, name, consumer string, capacity int, visibility time.Duration) (*RedisQueue, error) {
+	if consumer == "" {
+		consumer = "default"
+	}
+	q := &RedisQueue{
+		client:      client,
+		stream:      "queue:" + name,
+		consumer:    consumer,
+		capacity:    int64(capacity),
+		visibility:  visibility,
+		recoverFrom: "0",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// "0" makes the group see entries added before it existed
+	err := client.XGroupCreateMkStream(ctx, q.stream, redisQueueGroup, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil, fmt.Errorf("create consumer group: %w", err)
+	}
+	return q, nil
+}
+
+// redisEnqueueScript appends an entry unless the stream already holds
+// ARGV[2] entries (saturated, returns -1) or ARGV[3] entries (full, returns -2);
+// a limit of 0 is disabled. Checking and adding in one script keeps replicas
+// enqueuing at the same time from overshooting either limit
+var redisEnqueueScript = redis.NewScript(`
+local length = redis.call("XLEN", KEYS[1])
+local threshold, capacity = tonumber(ARGV[2]), tonumber(ARGV[3])
+if threshold > 0 and length >= threshold then
+	return -1
+end
+if capacity > 0 and length >= capacity then
+	return -2
+end
+redis.call("XADD", KEYS[1], "*", "payload", ARGV[1])
+return length + 1`)
+
+// Enqueue appends a job to the stream unless it already holds capacity entries
+func (q *RedisQueue) Enqueue(ctx context.Context, payload []byte) error {
+	return q.EnqueueUnlessSaturated(ctx, payload, 0)
+}
+
+// EnqueueUnlessSaturated appends a job unless the stream holds threshold
+// entries (ErrPoolSaturated) or capacity entries (ErrQueueFull)
+func (q *RedisQueue) EnqueueUnlessSaturated(ctx context.Context, payload []byte, threshold int) error {
+	result, err := redisEnqueueScript.Run(ctx, q.client, []string{q.stream}, payload, threshold, q.capacity).Int64()
+	if err != nil {
+		return err
+	}
+	switch result {
+	case -1:
+		return ErrPoolSaturated
+	case -2:
+		return ErrQueueFull
+	}
+	return nil
+}
+
+// Receive returns the next job: recovered and reclaimed entries first, then new ones
+func (q *RedisQueue) Receive(ctx context.Context) (Delivery, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return Delivery{}, err
+		}
+
+		if message, ok := q.nextBuffered(ctx); ok {
+			return q.delivery(message), nil
+		}
+
+		// Don't block past the caller's deadline (used by batching pools to close a batch window)
+		block := redisQueueBlock
+		if deadline, ok := ctx.Deadline(); ok {
+			if remaining := time.Until(deadline); remaining < block {
+				block = max(remaining, time.Millisecond)
+			}
+		}
+
+		streams, err := q.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    redisQueueGroup,
+			Consumer: q.consumer,
+			Streams:  []string{q.stream, ">"},
+			Count:    1,
+			Block:    block,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return Delivery{}, ctx.Err()
+			}
+			log.Printf("WARNING: Failed to read from queue %s: %v", q.stream, err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		if len(streams) > 0 && len(streams[0].Messages) > 0 {
+			return q.delivery(streams[0].Messages[0]), nil
+		}
+	}
+}
+
+// nextBuffered pops a recovered or reclaimed entry, refilling the buffer when due
+func (q *RedisQueue) nextBuffered(ctx context.Context) (redis.XMessage, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.buffer) == 0 {
+		if !q.recovered {
+			q.recoverOwnPending(ctx)
+		} else if time.Since(q.lastClaim) >= q.visibility/2 {
+			q.claimStale(ctx)
+		}
+	}
+
+	if len(q.buffer) == 0 {
+		return redis.XMessage{}, false
+	}
+	message := q.buffer[0]
+	q.buffer = q.buffer[1:]
+	return message, true
+}
+
+// recoverOwnPending loads entries this consumer received but never acknowledged
+// before the last restart; the caller must hold q.mu
+func (q *RedisQueue) recoverOwnPending(ctx context.Context) {
+	streams, err := q.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    redisQueueGroup,
+		Consumer: q.consumer,
+		Streams:  []string{q.stream, q.recoverFrom},
+		Count:    redisQueueBatch,
+	}).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.Printf("WARNING: Failed to recover pending jobs from %s: %v", q.stream, err)
+		return
+	}
+
+	for _, stream := range streams {
+		q.buffer = append(q.buffer, stream.Messages...)
+	}
+	if len(q.buffer) > 0 {
+		// Continue after the last recovered entry so in-flight entries are not read twice
+		q.recoverFrom = q.buffer[len(q.buffer)-1].ID
+		log.Printf("Recovered %d pending jobs from %s", len(q.buffer), q.stream)
+		return
+	}
+	// Nothing left from the previous run; from now on only stale entries are claimed
+	q.recovered = true
+}
+
+// claimStale takes over entries left unacknowledged past the visibility timeout
+// by any consumer; the caller must hold q.mu
+func (q *RedisQueue) claimStale(ctx context.Context) {
+	q.lastClaim = time.Now()
+
+	messages, _, err := q.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   q.stream,
+		Group:    redisQueueGroup,
+		Consumer: q.consumer,
+		MinIdle:  q.visibility,
+		Start:    "0",
+		Count:    redisQueueBatch,
+	}).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.Printf("WARNING: Failed to claim stale jobs from %s: %v", q.stream, err)
+		return
+	}
+
+	if len(messages) > 0 {
+		log.Printf("Claimed %d stale jobs from %s", len(messages), q.stream)
+		q.buffer = append(q.buffer, messages...)
+	}
+}
+
+// delivery wraps a stream entry; acknowledging it also deletes it from the stream
+func (q *RedisQueue) delivery(message redis.XMessage) Delivery {
+	var payload []byte
+	switch value := message.Values["payload"].(type) {
+	case string:
+		payload = []byte(value)
+	case []byte:
+		payload = value
+	}
+
+	return Delivery{
+		ID:      message.ID,
+		Payload: payload,
+		ack: func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			pipe := q.client.TxPipeline()
+			pipe.XAck(ctx, q.stream, redisQueueGroup, message.ID)
+			pipe.XDel(ctx, q.stream, message.ID)
+			_, err := pipe.Exec(ctx)
+			return err
+		},
+	}
+}
+
+// Len returns the number of entries in the stream, including unacknowledged ones
+func (q *RedisQueue) Len() int {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	length, err := q.client.XLen(ctx, q.stream).Result()
+	if err != nil {
+		return 0
+	}
+	return int(length)
+}
+
+// Close is a no-op; the Redis client is owned and closed by the config package
+func (q *RedisQueue) Close() error {
+	return nil
+}
@@ -0,0 +1,126 @@
+package workers
+
+import (
+	"context"
+	"database/sql/driver"
+	"disney/database"
+	"disney/jobs"
+	"disney/models"
+	"errors"
+	"log"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// RetryPolicy controls how often and how quickly a failed job is retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of tries, including the first one
+	MaxAttempts int
+	// BaseDelay is the backoff before the second attempt; it doubles per attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff between two attempts
+	MaxDelay time.Duration
+}
+
+// RetryPolicies holds the retry policy of each job type
+// Views are cheap and numerous, so they give up sooner than user-visible list changes
+var RetryPolicies = map[string]RetryPolicy{
+	jobs.TypeView:      {MaxAttempts: 3, BaseDelay: 200 * time.Millisecond, MaxDelay: 2 * time.Second},
+	jobs.TypeFavourite: {MaxAttempts: 5, BaseDelay: 200 * time.Millisecond, MaxDelay: 5 * time.Second},
+	jobs.TypeWatchlist: {MaxAttempts: 5, BaseDelay: 200 * time.Millisecond, MaxDelay: 5 * time.Second},
+}
+
+// defaultRetryPolicy is used for job types without an entry in RetryPolicies
+var defaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: 200 * time.Millisecond, MaxDelay: 5 * time.Second}
+
+// retryPolicyFor returns the retry policy of a job type
+func retryPolicyFor(jobType string) RetryPolicy {
+	if policy, ok := RetryPolicies[jobType]; ok {
+		return policy
+	}
+	return defaultRetryPolicy
+}
+
+// Backoff returns the wait before the given retry (1 = first retry)
+// Uses exponential backoff with full jitter so retries of many jobs spread out
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < retry && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay)) + 1)
+}
+
+// IsUniqueViolation reports whether err is a Postgres unique constraint violation
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.Is(err, gorm.ErrDuplicatedKey) || (errors.As(err, &pgErr) && pgErr.Code == "23505")
+}
+
+// IsRetryable reports whether a job that failed with err may succeed if tried again
+// Postgres errors are classified by SQLSTATE: connection problems, serialization
+// failures, deadlocks, lock timeouts, resource exhaustion and shutdowns are
+// transient; constraint violations and invalid data are permanent
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case strings.HasPrefix(pgErr.Code, "08"): // connection exception
+			return true
+		case pgErr.Code == "40001", pgErr.Code == "40P01": // serialization failure, deadlock
+			return true
+		case pgErr.Code == "55P03", pgErr.Code == "57014": // lock not available, query cancelled
+			return true
+		case strings.HasPrefix(pgErr.Code, "53"): // insufficient resources
+			return true
+		case strings.HasPrefix(pgErr.Code, "57P"): // server shutting down
+			return true
+		default: // integrity violations (23), data exceptions (22), syntax errors (42) ...
+			return false
+		}
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var connectErr *pgconn.ConnectError
+	return errors.As(err, &connectErr)
+}
+
+// deadLetter stores a failed job so admins can inspect, replay or purge it
+func deadLetter(jobType, jobID string, payload []byte, jobErr error, attempts int) {
+	entry := models.DeadLetterJob{
+		JobType:  jobType,
+		JobID:    jobID,
+		Payload:  string(payload),
+		Error:    jobErr.Error(),
+		Attempts: attempts,
+		FailedAt: time.Now(),
+	}
+	if err := database.DB.Create(&entry).Error; err != nil {
+		// Last resort: keep the payload in the logs so the job can be recovered by hand
+		log.Printf("ERROR: Failed to dead-letter job %s (%s): %v; payload: %s\n", jobID, jobType, err, payload)
+	}
+}
@@ -0,0 +1,88 @@
+package workers
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	// Full jitter: each retry waits up to the doubled delay, capped at MaxDelay
+	limits := map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		4: 800 * time.Millisecond,
+		5: time.Second,
+		9: time.Second,
+	}
+	for retry, limit := range limits {
+		for i := 0; i < 50; i++ {
+			if delay := policy.Backoff(retry); delay <= 0 || delay > limit {
+				t.Fatalf("Backoff(%d) = %s, want in (0, %s]", retry, delay, limit)
+			}
+		}
+	}
+
+	if delay := (RetryPolicy{MaxAttempts: 3}).Backoff(1); delay != 0 {
+		t.Errorf("Backoff without a base delay = %s, want 0", delay)
+	}
+}
+
+func TestRetryPolicyFor(t *testing.T) {
+	if policy := retryPolicyFor("view"); policy.MaxAttempts != 3 {
+		t.Errorf("view policy %+v", policy)
+	}
+	if policy := retryPolicyFor("unknown"); policy != defaultRetryPolicy {
+		t.Errorf("unknown job type got %+v, want the default policy", policy)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	pgError := func(code string) error {
+		return fmt.Errorf("insert favourite: %w", &pgconn.PgError{Code: code})
+	}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"record not found", gorm.ErrRecordNotFound, false},
+		{"connection failure", pgError("08006"), true},
+		{"serialization failure", pgError("40001"), true},
+		{"deadlock", pgError("40P01"), true},
+		{"lock not available", pgError("55P03"), true},
+		{"query cancelled", pgError("57014"), true},
+		{"too many connections", pgError("53300"), true},
+		{"admin shutdown", pgError("57P01"), true},
+		{"unique violation", pgError("23505"), false},
+		{"foreign key violation", pgError("23503"), false},
+		{"invalid text", pgError("22P02"), false},
+		{"undefined column", pgError("42703"), false},
+		{"bad connection", driver.ErrBadConn, true},
+		{"deadline", context.DeadlineExceeded, true},
+		{"unknown", errors.New("something else"), false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: IsRetryable = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	if !IsUniqueViolation(&pgconn.PgError{Code: "23505"}) || !IsUniqueViolation(gorm.ErrDuplicatedKey) {
+		t.Error("unique violations not recognised")
+	}
+	if IsUniqueViolation(&pgconn.PgError{Code: "23503"}) || IsUniqueViolation(errors.New("duplicate key value")) {
+		t.Error("other errors reported as unique violations")
+	}
+}
@@ -1,121 +1,124 @@
 package workers
 
 import (
+	"context"
 	"disney/database"
 	"disney/jobs"
 	"disney/models"
 	"log"
-	"time"
+
+	"gorm.io/gorm/clause"
 )
 
 // ViewWorkerPool manages a pool of workers that process view recording jobs
-type ViewWorkerPool struct {
-	// JobQueue is a buffered channel that receives view jobs
-	JobQueue chan jobs.ViewJob
-	// NumWorkers specifies the number of concurrent workers
-	NumWorkers int
-	// done channel to signal graceful shutdown
-	done chan struct{}
-}
+type ViewWorkerPool = Pool[jobs.ViewJob]
 
 // NewViewWorkerPool creates a new worker pool for processing view jobs
-// numWorkers: number of concurrent workers to spawn
-// bufferSize: size of the job queue buffer
-func NewViewWorkerPool(numWorkers, bufferSize int) *ViewWorkerPool {
-	return &ViewWorkerPool{
-		JobQueue:   make(chan jobs.ViewJob, bufferSize),
-		NumWorkers: numWorkers,
-		done:       make(chan struct{}),
-	}
+// queue: durable or in-memory queue the jobs are stored in (see NewQueue)
+// tracker: records job statuses for the job status endpoint
+// opts: concurrency, saturation threshold, Retry-After and batching; views are
+// inserted in batches of up to opts.BatchSize, flushed at the latest
+// opts.BatchWindow after the first view of the batch arrived
+// A view is not worth making the client wait for, so a full queue rejects it at once
+func NewViewWorkerPool(queue Queue, tracker *JobTracker, opts PoolOptions) *ViewWorkerPool {
+	opts.Name = jobs.TypeView
+	opts.QueueFull = QueueFullReject
+	return NewBatchPool(queue, tracker, processViewJob, processViewBatch, opts)
 }
 
-// Start initializes and starts the worker pool
-// Spawns numWorkers goroutines that listen for jobs on the JobQueue
-func (vwp *ViewWorkerPool) Start() {
-	log.Printf("Starting view worker pool with %d workers\n", vwp.NumWorkers)
+// processViewBatch records a batch of views with one multi-row INSERT
+// and counts the views of the affected cartoons with one grouped query
+// Redelivered jobs hit the job_id unique index and insert nothing
+func processViewBatch(ctx context.Context, batch []jobs.ViewJob) ([]interface{}, error) {
+	workerID := WorkerID(ctx)
 
-	for i := 0; i < vwp.NumWorkers; i++ {
-		// Each worker runs independently and continuously processes jobs
-		go vwp.worker(i)
+	views := make([]models.View, len(batch))
+	cartoonIDs := make([]uint, 0, len(batch))
+	seen := make(map[uint]bool)
+	for i := range batch {
+		job := &batch[i]
+		views[i] = models.View{
+			CartoonID:         job.CartoonID,
+			UserID:            &job.UserID,
+			ViewedAt:          job.Timestamp,
+			JobID:             &job.ID,
+			HiddenFromHistory: job.HiddenFromHistory,
+		}
+		if !seen[job.CartoonID] {
+			seen[job.CartoonID] = true
+			cartoonIDs = append(cartoonIDs, job.CartoonID)
+		}
 	}
-}
 
-// worker is a goroutine that continuously processes view jobs
-// Each worker listens for jobs on the shared JobQueue and processes them
-func (vwp *ViewWorkerPool) worker(workerID int) {
-	log.Printf("View worker %d started\n", workerID)
-
-	for {
-		select {
-		// Received a view job from the queue
-		case job := <-vwp.JobQueue:
-			vwp.processViewJob(job, workerID)
-
-		// Shutdown signal received
-		case <-vwp.done:
-			log.Printf("View worker %d shutting down\n", workerID)
-			return
-		}
+	if err := database.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "job_id"}},
+		DoNothing: true,
+	}).Create(&views).Error; err != nil {
+		log.Printf("View worker %d: Error recording batch of %d views: %v\n", workerID, len(batch), err)
+		return nil, err
+	}
+
+	log.Printf("View worker %d: Successfully recorded batch of %d views for %d cartoons\n",
+		workerID, len(batch), len(cartoonIDs))
+
+	// The views are stored; a failed count only leaves view_count empty
+	var counts []struct {
+		CartoonID uint
+		Count     int64
+	}
+	database.DB.WithContext(ctx).Model(&models.View{}).
+		Select("cartoon_id, COUNT(*) AS count").
+		Where("cartoon_id IN ?", cartoonIDs).
+		Group("cartoon_id").
+		Scan(&counts)
+
+	viewCounts := make(map[uint]int64, len(counts))
+	for _, row := range counts {
+		viewCounts[row.CartoonID] = row.Count
 	}
+
+	results := make([]interface{}, len(batch))
+	for i, job := range batch {
+		results[i] = jobs.ViewJobResponse{Success: true, ViewCount: viewCounts[job.CartoonID]}
+	}
+	return results, nil
 }
 
 // processViewJob handles the actual database operation for recording a view
+// Used for views of a batch that failed as a whole
 // It creates a View record with user and cartoon IDs
 // Uses GORM to safely insert the view record
-func (vwp *ViewWorkerPool) processViewJob(job jobs.ViewJob, workerID int) {
+// Returns the cartoon's new view count
+func processViewJob(ctx context.Context, job jobs.ViewJob) (interface{}, error) {
+	workerID := WorkerID(ctx)
+
 	// Create new view record
 	newView := models.View{
-		CartoonID: job.CartoonID,
-		UserID:    &job.UserID,
-		ViewedAt:  job.Timestamp,
+		CartoonID:         job.CartoonID,
+		UserID:            &job.UserID,
+		ViewedAt:          job.Timestamp,
+		JobID:             &job.ID,
+		HiddenFromHistory: job.HiddenFromHistory,
 	}
 
 	// Insert view into database
 	// GORM handles this atomically, so multiple workers writing
 	// different views won't cause race conditions
-	if err := database.DB.Create(&newView).Error; err != nil {
+	// A redelivered job hits the job_id unique index and inserts nothing
+	if err := database.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "job_id"}},
+		DoNothing: true,
+	}).Create(&newView).Error; err != nil {
 		log.Printf("View worker %d: Error recording view for user %d, cartoon %d: %v\n",
 			workerID, job.UserID, job.CartoonID, err)
-		return
+		return nil, err
 	}
 
 	log.Printf("View worker %d: Successfully recorded view for user %d, cartoon %d\n",
 		workerID, job.UserID, job.CartoonID)
-}
-
-// EnqueueViewJob adds a view job to the processing queue
-// This is called by HTTP handlers to queue jobs for async processing
-// The method returns immediately without waiting for job completion
-func (vwp *ViewWorkerPool) EnqueueViewJob(userID, cartoonID uint) {
-	job := jobs.ViewJob{
-		UserID:    userID,
-		CartoonID: cartoonID,
-		Timestamp: time.Now(),
-	}
-
-	// Send job to queue (non-blocking send, channel is buffered)
-	select {
-	case vwp.JobQueue <- job:
-		// Job enqueued successfully
-	case <-time.After(100 * time.Millisecond):
-		// Queue is full - log warning but don't block the HTTP request
-		log.Printf("View worker pool queue is full, dropping job for user %d, cartoon %d\n",
-			userID, cartoonID)
-	}
-}
-
-// Shutdown gracefully stops the worker pool
-// Closes the done channel to signal all workers to stop
-func (vwp *ViewWorkerPool) Shutdown() {
-	log.Println("Shutting down view worker pool...")
-	close(vwp.done)
-	// Give workers time to finish current jobs
-	time.Sleep(1 * time.Second)
-	close(vwp.JobQueue)
-}
 
-// GetQueueLength returns the current number of jobs waiting in the queue
-// Useful for monitoring worker pool health
-func (vwp *ViewWorkerPool) GetQueueLength() int {
-	return len(vwp.JobQueue)
+	// The view is stored; a failed count only leaves view_count empty
+	var viewCount int64
+	database.DB.WithContext(ctx).Model(&models.View{}).Where("cartoon_id = ?", job.CartoonID).Count(&viewCount)
+	return jobs.ViewJobResponse{Success: true, ViewCount: viewCount}, nil
 }
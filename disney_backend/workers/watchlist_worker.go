@@ -0,0 +1,106 @@
+package workers
+
+import (
+	"context"
+	"disney/database"
+	"disney/jobs"
+	"disney/models"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WatchlistWorkerPool manages a pool of workers that process watchlist add/remove jobs
+type WatchlistWorkerPool = Pool[jobs.WatchlistJob]
+
+// NewWatchlistWorkerPool creates a new worker pool for processing watchlist jobs
+// queue: durable or in-memory queue the jobs are stored in (see NewQueue)
+// tracker: records job statuses for the job status endpoint
+// opts: concurrency, saturation threshold and Retry-After (see ErrPoolSaturated)
+// A full queue is given a short grace period before the job is dropped
+func NewWatchlistWorkerPool(queue Queue, tracker *JobTracker, opts PoolOptions) *WatchlistWorkerPool {
+	opts.Name = jobs.TypeWatchlist
+	opts.QueueFull = QueueFullDrop
+	return NewPool(queue, tracker, processWatchlistJob, opts)
+}
+
+// processWatchlistJob dispatches a job to the add or remove handler
+// Returned errors are retried by the pool when they are transient
+func processWatchlistJob(ctx context.Context, job jobs.WatchlistJob) (interface{}, error) {
+	workerID := WorkerID(ctx)
+
+	switch job.Action {
+	case "add":
+		return processAddItem(ctx, job, workerID)
+	case "remove":
+		return processRemoveItem(ctx, job, workerID)
+	default:
+		log.Printf("Watchlist worker %d: Unknown action '%s' for watchlist %d, cartoon %d\n",
+			workerID, job.Action, job.WatchlistID, job.CartoonID)
+		return jobs.WatchlistJobResponse{}, fmt.Errorf("unknown action %q", job.Action)
+	}
+}
+
+// processAddItem appends a cartoon to the end of a watchlist
+// The watchlist row is locked so concurrent adds get distinct positions;
+// adding a cartoon that is already in the list is a no-op
+func processAddItem(ctx context.Context, job jobs.WatchlistJob, workerID int) (jobs.WatchlistJobResponse, error) {
+	added := false
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var watchlist models.Watchlist
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").First(&watchlist, job.WatchlistID).Error; err != nil {
+			return err
+		}
+
+		var maxPosition int
+		if err := tx.Model(&models.WatchlistItem{}).
+			Where("watchlist_id = ?", job.WatchlistID).
+			Select("COALESCE(MAX(position), 0)").Scan(&maxPosition).Error; err != nil {
+			return err
+		}
+
+		item := models.WatchlistItem{
+			WatchlistID: job.WatchlistID,
+			CartoonID:   job.CartoonID,
+			Position:    maxPosition + 1,
+			Note:        job.Note,
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&item)
+		added = result.RowsAffected > 0
+		return result.Error
+	})
+	if err != nil {
+		log.Printf("Watchlist worker %d: Error adding cartoon %d to watchlist %d: %v\n",
+			workerID, job.CartoonID, job.WatchlistID, err)
+		return jobs.WatchlistJobResponse{}, err
+	}
+
+	log.Printf("Watchlist worker %d: Added cartoon %d to watchlist %d for user %d\n",
+		workerID, job.CartoonID, job.WatchlistID, job.UserID)
+	if !added {
+		return jobs.WatchlistJobResponse{Success: true, Message: "Cartoon already in watchlist"}, nil
+	}
+	return jobs.WatchlistJobResponse{Success: true, Message: "Cartoon added to watchlist"}, nil
+}
+
+// processRemoveItem removes a cartoon from a watchlist
+// Removing a cartoon that is not in the list is treated as success
+func processRemoveItem(ctx context.Context, job jobs.WatchlistJob, workerID int) (jobs.WatchlistJobResponse, error) {
+	result := database.DB.WithContext(ctx).Where("watchlist_id = ? AND cartoon_id = ?", job.WatchlistID, job.CartoonID).
+		Delete(&models.WatchlistItem{})
+	if result.Error != nil {
+		log.Printf("Watchlist worker %d: Error removing cartoon %d from watchlist %d: %v\n",
+			workerID, job.CartoonID, job.WatchlistID, result.Error)
+		return jobs.WatchlistJobResponse{}, result.Error
+	}
+
+	log.Printf("Watchlist worker %d: Removed cartoon %d from watchlist %d for user %d (rows: %d)\n",
+		workerID, job.CartoonID, job.WatchlistID, job.UserID, result.RowsAffected)
+	if result.RowsAffected == 0 {
+		return jobs.WatchlistJobResponse{Success: true, Message: "Cartoon was not in watchlist"}, nil
+	}
+	return jobs.WatchlistJobResponse{Success: true, Message: "Cartoon removed from watchlist"}, nil
+}
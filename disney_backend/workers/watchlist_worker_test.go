@@ -0,0 +1,83 @@
+package workers
+
+import (
+	"context"
+	"disney/jobs"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectAddItem expects the queries appending a cartoon after position maxPosition
+func expectAddItem(mock sqlmock.Sqlmock, maxPosition int, inserted bool) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id" FROM "watchlists" WHERE "watchlists"."id" = \$1 .* FOR UPDATE`).WithArgs(4, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectQuery(`SELECT COALESCE\(MAX\(position\), 0\) FROM "watchlist_items" WHERE watchlist_id = \$1`).WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(maxPosition))
+	returned := sqlmock.NewRows([]string{"id"})
+	if inserted {
+		returned.AddRow(12)
+	}
+	mock.ExpectQuery(`INSERT INTO "watchlist_items" .* ON CONFLICT DO NOTHING RETURNING "id"`).
+		WithArgs(4, 10, maxPosition+1, "For the car", sqlmock.AnyArg()).
+		WillReturnRows(returned)
+	mock.ExpectCommit()
+}
+
+func TestProcessWatchlistAddAppendsItem(t *testing.T) {
+	mock := mockDB(t)
+	expectAddItem(mock, 3, true)
+
+	result, err := processWatchlistJob(context.Background(), jobs.NewWatchlistJob(1, 4, 10, "add", "For the car"))
+	if err != nil {
+		t.Fatalf("processWatchlistJob: %v", err)
+	}
+	if response := result.(jobs.WatchlistJobResponse); !response.Success || response.Message != "Cartoon added to watchlist" {
+		t.Errorf("response %+v", response)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProcessWatchlistAddExistingItem(t *testing.T) {
+	mock := mockDB(t)
+	expectAddItem(mock, 3, false)
+
+	result, err := processWatchlistJob(context.Background(), jobs.NewWatchlistJob(1, 4, 10, "add", "For the car"))
+	if err != nil {
+		t.Fatalf("processWatchlistJob: %v", err)
+	}
+	if response := result.(jobs.WatchlistJobResponse); !response.Success || response.Message != "Cartoon already in watchlist" {
+		t.Errorf("response %+v", response)
+	}
+}
+
+func TestProcessWatchlistRemove(t *testing.T) {
+	for _, tt := range []struct {
+		rows    int64
+		message string
+	}{
+		{1, "Cartoon removed from watchlist"},
+		{0, "Cartoon was not in watchlist"},
+	} {
+		mock := mockDB(t)
+		mock.ExpectExec(`DELETE FROM "watchlist_items" WHERE watchlist_id = \$1 AND cartoon_id = \$2`).WithArgs(4, 10).
+			WillReturnResult(sqlmock.NewResult(0, tt.rows))
+
+		result, err := processWatchlistJob(context.Background(), jobs.NewWatchlistJob(1, 4, 10, "remove", ""))
+		if err != nil {
+			t.Fatalf("processWatchlistJob: %v", err)
+		}
+		if response := result.(jobs.WatchlistJobResponse); !response.Success || response.Message != tt.message {
+			t.Errorf("%d rows deleted: response %+v, want %q", tt.rows, response, tt.message)
+		}
+	}
+}
+
+func TestProcessWatchlistUnknownAction(t *testing.T) {
+	if _, err := processWatchlistJob(context.Background(), jobs.NewWatchlistJob(1, 4, 10, "rename", "")); err == nil {
+		t.Error("processWatchlistJob accepted an unknown action")
+	}
+}